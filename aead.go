@@ -0,0 +1,242 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// SealChunkSize is the maximum size of the plaintext of a chunk sealed by [*SealWriteCloser].
+const SealChunkSize = 64 << 10
+
+// ErrAuthFailed is returned by [*OpenReadCloser] when a chunk is not authentic,
+// e.g., because it has been tampered with, reordered, or sealed using another key.
+var ErrAuthFailed = errors.New("chunk authentication failed")
+
+// sealFinalFlag is the bit of a chunk header marking the final chunk.
+const sealFinalFlag = 1 << 31
+
+// sealNonce returns the nonce of the chunk with the given index, which is base XOR
+// the big-endian index in the last 8 bytes, writing it into nonce.
+func sealNonce(nonce, base []byte, index uint64) []byte {
+	copy(nonce, base)
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], index)
+	for idx, value := range counter {
+		nonce[len(nonce)-8+idx] ^= value
+	}
+	return nonce
+}
+
+// SealWriteCloser is an [io.WriteCloser] encrypting and authenticating a stream
+// using a [cipher.AEAD], such that [*OpenReadCloser] can decrypt it.
+//
+// The stream starts with a random base nonce, followed by chunks of at most
+// [SealChunkSize] bytes of plaintext. Each chunk consists of a 4-byte big-endian
+// header containing the size of the ciphertext, whose most significant bit marks
+// the final chunk, followed by the ciphertext, which authenticates the header as
+// additional data. Each chunk uses the base nonce XOR its index as the nonce, so
+// chunks cannot be reordered, and the final chunk marker allows detecting
+// truncation. Close writes the final chunk, therefore it MUST be called.
+//
+// Not safe for concurrent use.
+//
+// Construct using [NewSealWriteCloser].
+type SealWriteCloser struct {
+	aead   cipher.AEAD
+	base   []byte
+	buf    []byte
+	cs     closeState
+	err    error
+	header bool
+	index  uint64
+	nonce  []byte
+	w      io.WriteCloser
+}
+
+// NewSealWriteCloser returns a new [*SealWriteCloser] sealing into w using aead,
+// whose nonce MUST be at least 8 bytes long, like the one of AES-GCM.
+//
+// The returned error is nil or the error occurred generating the base nonce.
+func NewSealWriteCloser(w io.WriteCloser, aead cipher.AEAD) (*SealWriteCloser, error) {
+	if aead.NonceSize() < 8 {
+		return nil, errors.New("iox: AEAD nonce shorter than 8 bytes")
+	}
+	base := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(base); err != nil {
+		return nil, err
+	}
+	return &SealWriteCloser{
+		aead:  aead,
+		base:  base,
+		buf:   make([]byte, 0, SealChunkSize),
+		nonce: make([]byte, aead.NonceSize()),
+		w:     w,
+	}, nil
+}
+
+// Write implements [io.Writer].
+//
+// It buffers data and seals each full chunk. The returned error is nil, [ErrClosed]
+// when closed, or the error occurred writing a previous chunk.
+func (w *SealWriteCloser) Write(data []byte) (int, error) {
+	if err := w.cs.check(); err != nil {
+		return 0, err
+	}
+	total := len(data)
+	for len(data) > 0 {
+		if w.err != nil {
+			return total - len(data), w.err
+		}
+		count := min(len(data), SealChunkSize-len(w.buf))
+		w.buf = append(w.buf, data[:count]...)
+		data = data[count:]
+		if len(w.buf) >= SealChunkSize {
+			w.err = w.seal(false)
+		}
+	}
+	return total, w.err
+}
+
+// seal seals and writes the buffered plaintext as a chunk.
+func (w *SealWriteCloser) seal(final bool) error {
+	// 1. prepare the header and the stream prefix, if needed
+	frame := make([]byte, 0, len(w.base)+4+len(w.buf)+w.aead.Overhead())
+	if !w.header {
+		frame = append(frame, w.base...)
+	}
+	size := uint32(len(w.buf) + w.aead.Overhead())
+	if final {
+		size |= sealFinalFlag
+	}
+	frame = binary.BigEndian.AppendUint32(frame, size)
+	header := frame[len(frame)-4:]
+
+	// 2. seal authenticating the header and write
+	frame = w.aead.Seal(frame, sealNonce(w.nonce, w.base, w.index), w.buf, header)
+	if _, err := w.w.Write(frame); err != nil {
+		return err
+	}
+	w.header = true
+	w.index++
+	w.buf = w.buf[:0]
+	return nil
+}
+
+// Close seals the final chunk and closes the underlying writer.
+//
+// The returned error is nil, [ErrClosed] when already closed, or the error
+// occurred writing the chunks or closing the underlying writer.
+func (w *SealWriteCloser) Close() error {
+	return w.cs.close(func() error {
+		err := w.err
+		if err == nil {
+			err = w.seal(true)
+		}
+		return errors.Join(err, w.w.Close())
+	})
+}
+
+// OpenReadCloser is an [io.ReadCloser] decrypting and authenticating a stream
+// sealed by [*SealWriteCloser].
+//
+// Reads only return authenticated plaintext. They fail with [ErrAuthFailed] when
+// a chunk is not authentic, with [io.ErrUnexpectedEOF] when the stream ends
+// before the final chunk, and with [ErrFrameTooLarge] when a chunk header
+// announces more than [SealChunkSize] bytes of plaintext.
+//
+// Not safe for concurrent use, except that Close may be called concurrently
+// with Read to interrupt it, like for the underlying reader.
+//
+// Construct using [NewOpenReadCloser].
+type OpenReadCloser struct {
+	aead  cipher.AEAD
+	base  []byte
+	buf   []byte
+	err   error
+	final bool
+	index uint64
+	nonce []byte
+	plain []byte
+	rc    io.ReadCloser
+}
+
+// NewOpenReadCloser returns a new [*OpenReadCloser] opening the stream read
+// from rc using aead, which MUST be configured like the sealing one.
+func NewOpenReadCloser(rc io.ReadCloser, aead cipher.AEAD) *OpenReadCloser {
+	return &OpenReadCloser{aead: aead, nonce: make([]byte, aead.NonceSize()), rc: rc}
+}
+
+// Read implements [io.Reader].
+func (r *OpenReadCloser) Read(buf []byte) (int, error) {
+	for len(r.plain) <= 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.err = r.open()
+	}
+	count := copy(buf, r.plain)
+	r.plain = r.plain[count:]
+	return count, nil
+}
+
+// open reads and opens the next chunk, or checks for EOF after the final chunk.
+func (r *OpenReadCloser) open() error {
+	// 1. after the final chunk, we expect EOF
+	if r.final {
+		var probe [1]byte
+		if count, _ := io.ReadFull(r.rc, probe[:]); count > 0 {
+			return ErrAuthFailed
+		}
+		return io.EOF
+	}
+
+	// 2. read the base nonce when reading for the first time
+	if r.base == nil {
+		base := make([]byte, r.aead.NonceSize())
+		if _, err := io.ReadFull(r.rc, base); err != nil {
+			return noEOF(err)
+		}
+		r.base = base
+	}
+
+	// 3. read and validate the header
+	var header [4]byte
+	if _, err := io.ReadFull(r.rc, header[:]); err != nil {
+		return noEOF(err)
+	}
+	value := binary.BigEndian.Uint32(header[:])
+	size := int(value &^ sealFinalFlag)
+	if size > SealChunkSize+r.aead.Overhead() {
+		return ErrFrameTooLarge
+	}
+	if size < r.aead.Overhead() {
+		return ErrAuthFailed
+	}
+
+	// 4. read and open the ciphertext
+	if cap(r.buf) < size {
+		r.buf = make([]byte, size)
+	}
+	ciphertext := r.buf[:size]
+	if _, err := io.ReadFull(r.rc, ciphertext); err != nil {
+		return noEOF(err)
+	}
+	plain, err := r.aead.Open(ciphertext[:0], sealNonce(r.nonce, r.base, r.index), ciphertext, header[:])
+	if err != nil {
+		return ErrAuthFailed
+	}
+	r.index++
+	r.final = value&sealFinalFlag != 0
+	r.plain = plain
+	return nil
+}
+
+// Close implements [io.Closer].
+func (r *OpenReadCloser) Close() error {
+	return r.rc.Close()
+}
@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestAEAD returns a new AES-GCM [cipher.AEAD] with a random key.
+func newTestAEAD(t *testing.T) cipher.AEAD {
+	key := make([]byte, 32)
+	rand.Read(key)
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	return aead
+}
+
+// sealBytes seals payload using aead, writing in the given number of writes.
+func sealBytes(t *testing.T, aead cipher.AEAD, payload []byte, writes int) []byte {
+	var sealed bytes.Buffer
+	w, err := NewSealWriteCloser(NopWriteCloser(&sealed), aead)
+	require.NoError(t, err)
+	step := max(len(payload)/writes, 1)
+	for data := payload; len(data) > 0; {
+		count, err := w.Write(data[:min(step, len(data))])
+		require.NoError(t, err)
+		data = data[count:]
+	}
+	require.NoError(t, w.Close())
+	return sealed.Bytes()
+}
+
+// openBytes opens sealed using aead.
+func openBytes(aead cipher.AEAD, sealed []byte) ([]byte, error) {
+	return io.ReadAll(NewOpenReadCloser(NopReadCloser(bytes.NewReader(sealed)), aead))
+}
+
+func TestSealOpen(t *testing.T) {
+	aead := newTestAEAD(t)
+
+	t.Run("round trip", func(t *testing.T) {
+		for _, size := range []int{0, 1, SealChunkSize - 1, SealChunkSize, SealChunkSize + 1, 3*SealChunkSize + 17} {
+			payload := make([]byte, size)
+			rand.Read(payload)
+			sealed := sealBytes(t, aead, payload, 7)
+			nchunks := size/SealChunkSize + 1
+			assert.Equal(t, aead.NonceSize()+size+nchunks*(4+aead.Overhead()), len(sealed))
+			opened, err := openBytes(aead, sealed)
+			require.NoError(t, err)
+			assert.True(t, bytes.Equal(payload, opened), "size %d", size)
+		}
+	})
+
+	payload := make([]byte, 2*SealChunkSize+100)
+	rand.Read(payload)
+	sealed := sealBytes(t, aead, payload, 1)
+	chunkLen := 4 + SealChunkSize + aead.Overhead()
+	prefix := aead.NonceSize()
+
+	t.Run("truncation", func(t *testing.T) {
+		_, err := openBytes(aead, sealed[:prefix+2*chunkLen])
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		_, err = openBytes(aead, sealed[:len(sealed)-1])
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		_, err = openBytes(aead, nil)
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("tampering", func(t *testing.T) {
+		tampered := bytes.Clone(sealed)
+		tampered[prefix+chunkLen+10] ^= 1
+		opened, err := openBytes(aead, tampered)
+		require.ErrorIs(t, err, ErrAuthFailed)
+		assert.Equal(t, payload[:SealChunkSize], opened)
+	})
+
+	t.Run("forging the final marker", func(t *testing.T) {
+		tampered := bytes.Clone(sealed[:prefix+chunkLen])
+		value := binary.BigEndian.Uint32(tampered[prefix:])
+		binary.BigEndian.PutUint32(tampered[prefix:], value|sealFinalFlag)
+		_, err := openBytes(aead, tampered)
+		require.ErrorIs(t, err, ErrAuthFailed)
+	})
+
+	t.Run("reordering", func(t *testing.T) {
+		reordered := append(bytes.Clone(sealed[:prefix]), sealed[prefix+chunkLen:prefix+2*chunkLen]...)
+		reordered = append(reordered, sealed[prefix:prefix+chunkLen]...)
+		reordered = append(reordered, sealed[prefix+2*chunkLen:]...)
+		_, err := openBytes(aead, reordered)
+		require.ErrorIs(t, err, ErrAuthFailed)
+	})
+
+	t.Run("trailing data", func(t *testing.T) {
+		_, err := openBytes(aead, append(bytes.Clone(sealed), 0))
+		require.ErrorIs(t, err, ErrAuthFailed)
+	})
+
+	t.Run("oversized chunks", func(t *testing.T) {
+		tampered := bytes.Clone(sealed)
+		binary.BigEndian.PutUint32(tampered[prefix:], SealChunkSize+uint32(aead.Overhead())+1)
+		_, err := openBytes(aead, tampered)
+		require.ErrorIs(t, err, ErrFrameTooLarge)
+	})
+
+	t.Run("another key", func(t *testing.T) {
+		_, err := openBytes(newTestAEAD(t), sealed)
+		require.ErrorIs(t, err, ErrAuthFailed)
+	})
+
+	t.Run("streams use distinct nonces", func(t *testing.T) {
+		first := sealBytes(t, aead, []byte("iox"), 1)
+		second := sealBytes(t, aead, []byte("iox"), 1)
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("closed writer", func(t *testing.T) {
+		w, err := NewSealWriteCloser(NopWriteCloser(io.Discard), aead)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		_, err = w.Write([]byte("iox"))
+		require.ErrorIs(t, err, ErrClosed)
+		require.ErrorIs(t, w.Close(), ErrClosed)
+	})
+}
@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// sleepContext sleeps for the given duration or until the context is done.
+//
+// Returns nil after sleeping or the context error.
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	return closeSignal(nil).sleep(ctx, delay)
+}
+
+// closeSignal is closed by the Close method of values whose Read or Write may
+// wait, such as [*ResumableReadCloser], to interrupt the wait.
+//
+// The zero value is never closed. Construct using [newCloseSignal].
+type closeSignal chan struct{}
+
+// newCloseSignal returns a new, open [closeSignal].
+func newCloseSignal() closeSignal {
+	return make(closeSignal)
+}
+
+// isClosed returns whether close has been called.
+func (c closeSignal) isClosed() bool {
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
+
+// close closes the signal, interrupting any in-flight sleep.
+//
+// Returns nil or [ErrClosed] if already closed. The caller MUST serialize
+// calls to close, e.g., using a mutex.
+func (c closeSignal) close() error {
+	if c.isClosed() {
+		return ErrClosed
+	}
+	close(c)
+	return nil
+}
+
+// sleep sleeps for the given duration honoring the context and close.
+//
+// Returns nil after sleeping, [ErrClosed], or the context error.
+func (c closeSignal) sleep(ctx context.Context, delay time.Duration) error {
+	if c.isClosed() {
+		return ErrClosed
+	}
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return contextError(ctx)
+	case <-c:
+		return ErrClosed
+	case <-timer.C:
+		return nil
+	}
+}
+
+// backoffDelay returns the delay before the given zero-based retry, which is
+// initial doubled at each subsequent retry and capped to maximum, if positive.
+func backoffDelay(initial, maximum time.Duration, retry int) time.Duration {
+	if maximum <= 0 {
+		maximum = math.MaxInt64
+	}
+	delay := initial
+	for ; retry > 0 && delay < maximum && delay <= math.MaxInt64/2; retry-- {
+		delay *= 2
+	}
+	return min(delay, maximum)
+}
@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloseSignal(t *testing.T) {
+	t.Run("close interrupts sleep", func(t *testing.T) {
+		closed := newCloseSignal()
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			closed.close()
+		}()
+		start := time.Now()
+		require.ErrorIs(t, closed.sleep(context.Background(), time.Hour), ErrClosed)
+		assert.Less(t, time.Since(start), time.Second)
+		assert.True(t, closed.isClosed())
+		require.ErrorIs(t, closed.close(), ErrClosed)
+	})
+
+	t.Run("the context interrupts sleep", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, newCloseSignal().sleep(ctx, time.Hour), context.DeadlineExceeded)
+	})
+
+	t.Run("the zero value is never closed", func(t *testing.T) {
+		var closed closeSignal
+		assert.False(t, closed.isClosed())
+		require.NoError(t, closed.sleep(context.Background(), time.Millisecond))
+	})
+}
+
+func TestBackoffDelay(t *testing.T) {
+	assert.Equal(t, time.Second, backoffDelay(time.Second, 5*time.Second, 0))
+	assert.Equal(t, 4*time.Second, backoffDelay(time.Second, 5*time.Second, 2))
+	assert.Equal(t, 5*time.Second, backoffDelay(time.Second, 5*time.Second, 100))
+	assert.Equal(t, 8*time.Second, backoffDelay(time.Second, 0, 3))
+	assert.Greater(t, backoffDelay(time.Second, 0, 100), time.Duration(0)) // no overflow
+}
@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	// BandwidthInterval is the granularity at which [WithBandwidthSummary] samples throughput.
+	BandwidthInterval = 100 * time.Millisecond
+
+	// BandwidthWindow is the sliding window used by [WithBandwidthSummary] to compute the peak rate.
+	BandwidthWindow = time.Second
+)
+
+// BandwidthSummary summarizes the throughput of a copy over time.
+//
+// Rates are in bytes per second. The per-interval figures only consider complete
+// intervals of [BandwidthInterval], unless the copy is shorter than an interval,
+// in which case they consider the whole copy.
+type BandwidthSummary struct {
+	// Bytes is the number of bytes copied.
+	Bytes int
+
+	// Elapsed is the duration of the copy.
+	Elapsed time.Duration
+
+	// AverageRate is the average rate over the whole copy.
+	AverageRate float64
+
+	// PeakRate is the highest rate over a sliding window of [BandwidthWindow],
+	// or over all the intervals, if the copy is shorter than the window.
+	PeakRate float64
+
+	// P50, P90, and P99 are percentiles of the per-interval rates.
+	P50, P90, P99 float64
+}
+
+// WithBandwidthSummary causes the copy to call fn with a [BandwidthSummary] once done.
+//
+// It samples how many bytes are written every [BandwidthInterval], so the summary
+// preserves the time dimension lost when only counting bytes. Like [WithCopyTrace],
+// it disables the zero-copy fast path of [CopyContext]. Since fn is invoked
+// by the Done hook, it runs in the goroutine calling [CopyContext], once the copy
+// has ended and right before [CopyContext] returns.
+func WithBandwidthSummary(fn func(summary BandwidthSummary)) CopyOption {
+	return func(cfg *copyConfig) {
+		cfg.bandwidth = append(cfg.bandwidth, fn)
+	}
+}
+
+// bandwidthMeter collects the samples for [WithBandwidthSummary].
+//
+// Its methods are invoked through a [*copyTracer], which serializes them.
+type bandwidthMeter struct {
+	buckets []int64
+	fns     []func(BandwidthSummary)
+	start   time.Time
+}
+
+// newBandwidthMeter returns a [*bandwidthMeter] starting to measure now.
+func newBandwidthMeter(fns []func(BandwidthSummary)) *bandwidthMeter {
+	return &bandwidthMeter{fns: fns, start: time.Now()}
+}
+
+// trace returns the [*CopyTrace] feeding the meter.
+func (m *bandwidthMeter) trace() *CopyTrace {
+	return &CopyTrace{WroteChunk: m.wrote, Done: m.done}
+}
+
+// wrote accounts for count bytes written now.
+func (m *bandwidthMeter) wrote(count int) {
+	idx := int(time.Since(m.start) / BandwidthInterval)
+	if idx >= len(m.buckets) {
+		m.buckets = append(m.buckets, make([]int64, idx+1-len(m.buckets))...)
+	}
+	m.buckets[idx] += int64(count)
+}
+
+// done computes the summary and passes it to the callbacks.
+func (m *bandwidthMeter) done(count int, _ error) {
+	summary := summarizeBandwidth(m.buckets, count, time.Since(m.start))
+	for _, fn := range m.fns {
+		fn(summary)
+	}
+}
+
+// summarizeBandwidth computes the [BandwidthSummary] of a copy of count bytes
+// lasting elapsed, given the bytes written within each [BandwidthInterval].
+func summarizeBandwidth(buckets []int64, count int, elapsed time.Duration) BandwidthSummary {
+	// 1. compute the average rate
+	summary := BandwidthSummary{Bytes: count, Elapsed: elapsed}
+	if elapsed <= 0 {
+		return summary
+	}
+	summary.AverageRate = float64(count) / elapsed.Seconds()
+
+	// 2. only consider complete intervals, if any
+	complete := int(elapsed / BandwidthInterval)
+	if complete <= 0 {
+		summary.PeakRate = summary.AverageRate
+		summary.P50, summary.P90, summary.P99 = summary.AverageRate, summary.AverageRate, summary.AverageRate
+		return summary
+	}
+	samples := make([]int64, complete)
+	copy(samples, buckets)
+
+	// 3. compute the peak rate over the sliding window
+	size := min(complete, int(BandwidthWindow/BandwidthInterval))
+	var sum, peak int64
+	for idx, sample := range samples {
+		sum += sample
+		if idx >= size {
+			sum -= samples[idx-size]
+		}
+		peak = max(peak, sum)
+	}
+	summary.PeakRate = float64(peak) / (time.Duration(size) * BandwidthInterval).Seconds()
+
+	// 4. compute the percentiles using the nearest-rank method
+	slices.Sort(samples)
+	percentile := func(p int) float64 {
+		rank := max((p*len(samples)+99)/100, 1)
+		return float64(samples[rank-1]) / BandwidthInterval.Seconds()
+	}
+	summary.P50, summary.P90, summary.P99 = percentile(50), percentile(90), percentile(99)
+	return summary
+}
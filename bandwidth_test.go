@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithBandwidthSummary(t *testing.T) {
+	var summaries []BandwidthSummary
+	lwc := NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{}))
+	rc := NopReadCloser(strings.NewReader(strings.Repeat("x", 4096)))
+	count, err := CopyContext(context.Background(), lwc, rc,
+		WithBandwidthSummary(func(s BandwidthSummary) { summaries = append(summaries, s) }),
+		WithBandwidthSummary(func(s BandwidthSummary) { summaries = append(summaries, s) }),
+	)
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+	assert.Equal(t, summaries[0], summaries[1])
+	summary := summaries[0]
+	assert.Equal(t, count, summary.Bytes)
+	assert.Positive(t, summary.Elapsed)
+	assert.InDelta(t, float64(count)/summary.Elapsed.Seconds(), summary.AverageRate, 1e-6)
+}
+
+func TestSummarizeBandwidth(t *testing.T) {
+	t.Run("copies shorter than an interval", func(t *testing.T) {
+		summary := summarizeBandwidth([]int64{1000}, 1000, BandwidthInterval/2)
+		assert.Equal(t, BandwidthSummary{
+			Bytes:       1000,
+			Elapsed:     BandwidthInterval / 2,
+			AverageRate: 20000,
+			PeakRate:    20000,
+			P50:         20000,
+			P90:         20000,
+			P99:         20000,
+		}, summary)
+	})
+
+	t.Run("copies shorter than the window", func(t *testing.T) {
+		// 3 complete intervals plus a partial one that we ignore
+		buckets := []int64{100, 300, 200, 1000}
+		summary := summarizeBandwidth(buckets, 1600, 3*BandwidthInterval+BandwidthInterval/2)
+		assert.InDelta(t, 1600/0.35, summary.AverageRate, 1e-6)
+		assert.InDelta(t, 600/0.3, summary.PeakRate, 1e-6)
+		assert.InDelta(t, 2000.0, summary.P50, 1e-6)
+		assert.InDelta(t, 3000.0, summary.P90, 1e-6)
+		assert.InDelta(t, 3000.0, summary.P99, 1e-6)
+	})
+
+	t.Run("copies longer than the window", func(t *testing.T) {
+		// 20 intervals: ten of 100 bytes, then ten of 1000 bytes, with a burst
+		// of 5000 bytes in the middle that the sliding window must include
+		buckets := make([]int64, 20)
+		for idx := range buckets {
+			buckets[idx] = 100
+			if idx >= 10 {
+				buckets[idx] = 1000
+			}
+		}
+		buckets[5] = 5000
+		var total int64
+		for _, value := range buckets {
+			total += value
+		}
+		summary := summarizeBandwidth(buckets, int(total), 20*BandwidthInterval)
+		assert.InDelta(t, float64(total)/2, summary.AverageRate, 1e-6)
+		assert.InDelta(t, 10400.0, summary.PeakRate, 1e-6) // intervals 5..14
+		assert.InDelta(t, 10000.0, summary.P50, 1e-6)
+		assert.InDelta(t, 10000.0, summary.P90, 1e-6)
+		assert.InDelta(t, 50000.0, summary.P99, 1e-6)
+	})
+
+	t.Run("intervals without writes", func(t *testing.T) {
+		summary := summarizeBandwidth(nil, 0, 2*BandwidthInterval)
+		assert.Zero(t, summary.PeakRate)
+		assert.Zero(t, summary.P50)
+	})
+
+	t.Run("zero elapsed time", func(t *testing.T) {
+		assert.Equal(t, BandwidthSummary{}, summarizeBandwidth(nil, 0, 0))
+	})
+}
@@ -0,0 +1,260 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrSlowConsumer is returned by a [*Broadcaster] consumer disconnected because
+// it was too slow when using the [BroadcastDropSlowest] policy.
+var ErrSlowConsumer = errors.New("broadcast consumer is too slow")
+
+// BroadcastPolicy describes how a [*Broadcaster] handles consumers falling behind.
+type BroadcastPolicy int
+
+const (
+	// BroadcastBlock blocks reading from the source until all the consumers have
+	// room in their buffers, so the slowest consumer paces the whole broadcast.
+	// This is the default.
+	BroadcastBlock = BroadcastPolicy(iota)
+
+	// BroadcastDropSlowest disconnects consumers whose buffer is full. After
+	// reading the buffered bytes, their Read fails with [ErrSlowConsumer].
+	BroadcastDropSlowest
+
+	// BroadcastBoundedLag discards the oldest buffered bytes of consumers whose
+	// buffer is full, so consumers skip part of the stream but never lag behind
+	// the source by more than the buffer size.
+	BroadcastBoundedLag
+)
+
+// Broadcaster reads once from a source and delivers the bytes to many consumers.
+//
+// Attach consumers using Attach and call Run to read from the source. Each consumer
+// receives the bytes read after it has been attached, followed by [io.EOF] or by the
+// error that stopped Run. Consumers have a bounded buffer and the Policy decides
+// what happens when it is full.
+//
+// All methods are safe for concurrent use.
+//
+// Construct using [NewBroadcaster].
+type Broadcaster struct {
+	// BufferSize is the maximum number of bytes buffered for each consumer.
+	//
+	// [NewBroadcaster] sets it to [DefaultBufferSize]. You MUST NOT
+	// modify it after calling Attach or Run.
+	BufferSize int
+
+	// Policy is the [BroadcastPolicy] for consumers falling behind.
+	//
+	// [NewBroadcaster] sets it to [BroadcastBlock]. You MUST NOT
+	// modify it after calling Attach or Run.
+	Policy BroadcastPolicy
+
+	consumers map[*broadcastConsumer]struct{}
+	err       error
+	mu        sync.Mutex
+	src       io.ReadCloser
+}
+
+// NewBroadcaster returns a new [*Broadcaster] reading from src.
+func NewBroadcaster(src io.ReadCloser) *Broadcaster {
+	return &Broadcaster{
+		BufferSize: DefaultBufferSize,
+		Policy:     BroadcastBlock,
+		consumers:  map[*broadcastConsumer]struct{}{},
+		src:        src,
+	}
+}
+
+// Attach returns a new consumer receiving the bytes read from now on.
+//
+// Closing the consumer detaches it. A consumer attached after Run returns
+// immediately receives the error that stopped Run, or [io.EOF].
+func (b *Broadcaster) Attach() io.ReadCloser {
+	c := &broadcastConsumer{b: b}
+	c.cond = sync.NewCond(&c.mu)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		c.err = b.err
+		return c
+	}
+	b.consumers[c] = struct{}{}
+	return c
+}
+
+// Run reads from the source and delivers to the consumers until EOF, a read error,
+// or the context is done. It MUST be called at most once.
+//
+// Like [CopyContext], Run only closes the source when the context is done, to
+// unblock any in-flight Read, and the caller is otherwise responsible for closing it.
+//
+// The returned error is nil on EOF, or the error caused by I/O or by the context.
+func (b *Broadcaster) Run(ctx context.Context) error {
+	// 1. read and deliver until we fail
+	bp := getBuffer()
+	defer putBuffer(bp)
+	r := contextReader{ctx, b.src}
+	var err error
+	for err == nil {
+		var count int
+		count, err = r.Read(*bp)
+		if count > 0 {
+			if deliverErr := b.deliver(ctx, (*bp)[:count]); deliverErr != nil {
+				err = deliverErr
+			}
+		}
+	}
+
+	// 2. detach the consumers, which will see the error after draining
+	b.mu.Lock()
+	b.err = err
+	consumers := b.consumers
+	b.consumers = map[*broadcastConsumer]struct{}{}
+	b.mu.Unlock()
+	for c := range consumers {
+		c.finish(err)
+	}
+
+	// 3. EOF is the successful termination
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+// deliver delivers data to all the attached consumers.
+func (b *Broadcaster) deliver(ctx context.Context, data []byte) error {
+	b.mu.Lock()
+	consumers := make([]*broadcastConsumer, 0, len(b.consumers))
+	for c := range b.consumers {
+		consumers = append(consumers, c)
+	}
+	b.mu.Unlock()
+	for _, c := range consumers {
+		if err := c.deliver(ctx, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// detach removes c from the consumers.
+func (b *Broadcaster) detach(c *broadcastConsumer) {
+	b.mu.Lock()
+	delete(b.consumers, c)
+	b.mu.Unlock()
+}
+
+// broadcastConsumer is the [io.ReadCloser] returned by [*Broadcaster.Attach].
+type broadcastConsumer struct {
+	b      *Broadcaster
+	buf    []byte
+	closed bool
+	cond   *sync.Cond
+	err    error
+	mu     sync.Mutex
+}
+
+// Read implements [io.Reader].
+func (c *broadcastConsumer) Read(buf []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.buf) <= 0 && c.err == nil && !c.closed {
+		c.cond.Wait()
+	}
+	if c.closed {
+		return 0, ErrClosed
+	}
+	if len(c.buf) <= 0 {
+		return 0, c.err
+	}
+	count := copy(buf, c.buf)
+	c.buf = c.buf[count:]
+	c.cond.Broadcast()
+	return count, nil
+}
+
+// Close implements [io.Closer].
+func (c *broadcastConsumer) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+	c.closed = true
+	c.buf = nil
+	c.cond.Broadcast()
+	c.mu.Unlock()
+	c.b.detach(c)
+	return nil
+}
+
+// deliver appends data to the buffer honoring the [BroadcastPolicy].
+//
+// The returned error is nil or caused by the context while blocking.
+func (c *broadcastConsumer) deliver(ctx context.Context, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || c.err != nil {
+		return nil
+	}
+	limit := max(c.b.BufferSize, 1)
+
+	switch c.b.Policy {
+	case BroadcastDropSlowest:
+		if len(c.buf)+len(data) > limit {
+			c.err = ErrSlowConsumer
+			c.cond.Broadcast()
+			c.b.detach(c)
+			return nil
+		}
+		c.buf = append(c.buf, data...)
+
+	case BroadcastBoundedLag:
+		c.buf = append(c.buf, data...)
+		if excess := len(c.buf) - limit; excess > 0 {
+			c.buf = append([]byte(nil), c.buf[excess:]...)
+		}
+
+	default:
+		stop := context.AfterFunc(ctx, func() {
+			c.mu.Lock()
+			c.cond.Broadcast()
+			c.mu.Unlock()
+		})
+		defer stop()
+		for len(data) > 0 && !c.closed {
+			if err := contextError(ctx); err != nil {
+				return err
+			}
+			space := limit - len(c.buf)
+			if space <= 0 {
+				c.cond.Wait()
+				continue
+			}
+			chunk := data[:min(space, len(data))]
+			c.buf = append(c.buf, chunk...)
+			data = data[len(chunk):]
+			c.cond.Broadcast()
+		}
+	}
+
+	c.cond.Broadcast()
+	return nil
+}
+
+// finish sets the error returned after draining the buffer.
+func (c *broadcastConsumer) finish(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+	}
+	c.cond.Broadcast()
+}
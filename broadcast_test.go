@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	stdiotest "testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroadcaster(t *testing.T) {
+	payload := strings.Repeat("0123456789", 1000)
+
+	t.Run("BroadcastBlock delivers everything to everyone", func(t *testing.T) {
+		b := NewBroadcaster(NopReadCloser(strings.NewReader(payload)))
+		b.BufferSize = 16
+		consumers := []io.ReadCloser{b.Attach(), b.Attach(), b.Attach()}
+
+		var wg sync.WaitGroup
+		results := make([]string, len(consumers))
+		for idx, c := range consumers {
+			wg.Go(func() {
+				data, err := io.ReadAll(c)
+				assert.NoError(t, err)
+				results[idx] = string(data)
+			})
+		}
+		require.NoError(t, b.Run(context.Background()))
+		wg.Wait()
+		for _, result := range results {
+			assert.Equal(t, payload, result)
+		}
+	})
+
+	t.Run("BroadcastDropSlowest disconnects slow consumers", func(t *testing.T) {
+		b := NewBroadcaster(NopReadCloser(strings.NewReader(payload)))
+		b.BufferSize = 16
+		b.Policy = BroadcastDropSlowest
+		slow := b.Attach()
+		require.NoError(t, b.Run(context.Background()))
+
+		data, err := io.ReadAll(slow)
+		assert.ErrorIs(t, err, ErrSlowConsumer)
+		assert.LessOrEqual(t, len(data), 16)
+		assert.Equal(t, payload[:len(data)], string(data))
+	})
+
+	t.Run("BroadcastBoundedLag keeps the newest bytes", func(t *testing.T) {
+		b := NewBroadcaster(NopReadCloser(strings.NewReader(payload)))
+		b.BufferSize = 16
+		b.Policy = BroadcastBoundedLag
+		lagging := b.Attach()
+		require.NoError(t, b.Run(context.Background()))
+
+		data, err := io.ReadAll(lagging)
+		require.NoError(t, err)
+		assert.Equal(t, payload[len(payload)-16:], string(data))
+	})
+
+	t.Run("BroadcastBlock honors the context", func(t *testing.T) {
+		b := NewBroadcaster(NopReadCloser(ZeroReader()))
+		b.BufferSize = 16
+		stuck := b.Attach()
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- b.Run(ctx)
+		}()
+		cancel()
+		require.ErrorIs(t, <-done, context.Canceled)
+
+		_, err := io.ReadAll(stuck)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("source errors reach the consumers", func(t *testing.T) {
+		expected := errors.New("mocked error")
+		b := NewBroadcaster(NopReadCloser(stdiotest.ErrReader(expected)))
+		c := b.Attach()
+		require.ErrorIs(t, b.Run(context.Background()), expected)
+
+		_, err := io.ReadAll(c)
+		assert.ErrorIs(t, err, expected)
+
+		_, err = io.ReadAll(b.Attach())
+		assert.ErrorIs(t, err, expected)
+	})
+
+	t.Run("closed consumers are detached", func(t *testing.T) {
+		b := NewBroadcaster(NopReadCloser(strings.NewReader(payload)))
+		b.BufferSize = 16
+		c := b.Attach()
+		require.NoError(t, c.Close())
+		assert.ErrorIs(t, c.Close(), ErrClosed)
+		_, err := c.Read(make([]byte, 1))
+		assert.ErrorIs(t, err, ErrClosed)
+		require.NoError(t, b.Run(context.Background()))
+	})
+}
@@ -0,0 +1,222 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// BufferedPipe creates a synchronous in-memory pipe buffering up to capacity bytes.
+//
+// Unlike [io.Pipe], where each write blocks until readers consume all its data,
+// producers only block when the buffer is full and consumers only block when the
+// buffer is empty, so they do not need to proceed in lockstep. The WriteContext
+// and ReadContext methods allow to stop blocking when the context is done.
+//
+// Closing the writer causes reads to return [io.EOF], or the error passed to
+// CloseWithError, once the buffer is drained. Closing the reader causes writes
+// to fail with [io.ErrClosedPipe], or the error passed to CloseWithError.
+//
+// Nonpositive values of capacity cause BufferedPipe to use [DefaultBufferSize].
+// All methods of both ends are safe for concurrent use.
+func BufferedPipe(capacity int) (*BufferedPipeReader, *BufferedPipeWriter) {
+	if capacity <= 0 {
+		capacity = DefaultBufferSize
+	}
+	p := &bufferedPipe{buf: make([]byte, capacity), changed: make(chan struct{})}
+	return &BufferedPipeReader{p}, &BufferedPipeWriter{p}
+}
+
+// bufferedPipe is the ring buffer shared by both ends of a [BufferedPipe].
+type bufferedPipe struct {
+	buf     []byte
+	changed chan struct{}
+	mu      sync.Mutex
+	rerr    error
+	size    int
+	start   int
+	werr    error
+}
+
+// wait waits for the next state change or for the context to be done. The
+// caller MUST hold the lock, which wait releases while waiting.
+func (p *bufferedPipe) wait(ctx context.Context) error {
+	changed := p.changed
+	p.mu.Unlock()
+	defer p.mu.Lock()
+	select {
+	case <-ctx.Done():
+		return contextError(ctx)
+	case <-changed:
+		return nil
+	}
+}
+
+// notify wakes up the goroutines waiting for a state change. The caller MUST hold the lock.
+func (p *bufferedPipe) notify() {
+	close(p.changed)
+	p.changed = make(chan struct{})
+}
+
+// read implements [*BufferedPipeReader.ReadContext].
+func (p *bufferedPipe) read(ctx context.Context, buf []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for {
+		// 1. fail if the reader has been closed
+		if p.rerr != nil {
+			return 0, io.ErrClosedPipe
+		}
+
+		// 2. consume buffered data, possibly wrapping around
+		if p.size > 0 {
+			count := copy(buf, p.buf[p.start:min(p.start+p.size, len(p.buf))])
+			if count < len(buf) && count < p.size {
+				count += copy(buf[count:], p.buf[:p.size-count])
+			}
+			p.start = (p.start + count) % len(p.buf)
+			p.size -= count
+			p.notify()
+			return count, nil
+		}
+
+		// 3. return the writer's error once drained
+		if p.werr != nil {
+			return 0, p.werr
+		}
+
+		// 4. wait for the writer
+		if len(buf) <= 0 {
+			return 0, nil
+		}
+		if err := p.wait(ctx); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// write implements [*BufferedPipeWriter.WriteContext].
+func (p *bufferedPipe) write(ctx context.Context, data []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var total int
+	for {
+		// 1. fail if either end has been closed
+		if p.werr != nil {
+			return total, io.ErrClosedPipe
+		}
+		if p.rerr != nil {
+			return total, p.rerr
+		}
+		if len(data) <= 0 {
+			return total, nil
+		}
+
+		// 2. append as much data as possible, possibly wrapping around
+		if p.size < len(p.buf) {
+			end := (p.start + p.size) % len(p.buf)
+			count := copy(p.buf[end:min(end+len(p.buf)-p.size, len(p.buf))], data)
+			if free := len(p.buf) - p.size - count; free > 0 && count < len(data) {
+				count += copy(p.buf[:free], data[count:])
+			}
+			p.size += count
+			total += count
+			data = data[count:]
+			p.notify()
+			continue
+		}
+
+		// 3. wait for the reader
+		if err := p.wait(ctx); err != nil {
+			return total, err
+		}
+	}
+}
+
+// closeRead implements [*BufferedPipeReader.CloseWithError].
+func (p *bufferedPipe) closeRead(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err == nil {
+		err = io.ErrClosedPipe
+	}
+	if p.rerr == nil {
+		p.rerr = err
+		p.notify()
+	}
+}
+
+// closeWrite implements [*BufferedPipeWriter.CloseWithError].
+func (p *bufferedPipe) closeWrite(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err == nil {
+		err = io.EOF
+	}
+	if p.werr == nil {
+		p.werr = err
+		p.notify()
+	}
+}
+
+// BufferedPipeReader is the read end of a [BufferedPipe].
+type BufferedPipeReader struct {
+	p *bufferedPipe
+}
+
+// Read implements [io.Reader].
+//
+// It blocks until there is buffered data or the writer is closed.
+func (r *BufferedPipeReader) Read(buf []byte) (int, error) {
+	return r.p.read(context.Background(), buf)
+}
+
+// ReadContext is like Read but stops blocking when the context is done, in
+// which case it returns the context error.
+func (r *BufferedPipeReader) ReadContext(ctx context.Context, buf []byte) (int, error) {
+	return r.p.read(ctx, buf)
+}
+
+// Close closes the reader, causing writes to fail with [io.ErrClosedPipe].
+func (r *BufferedPipeReader) Close() error {
+	return r.CloseWithError(nil)
+}
+
+// CloseWithError closes the reader, causing writes to fail with err, or with
+// [io.ErrClosedPipe] if err is nil. Only the first close sets the error.
+func (r *BufferedPipeReader) CloseWithError(err error) error {
+	r.p.closeRead(err)
+	return nil
+}
+
+// BufferedPipeWriter is the write end of a [BufferedPipe].
+type BufferedPipeWriter struct {
+	p *bufferedPipe
+}
+
+// Write implements [io.Writer].
+//
+// It blocks until all data is buffered or the reader is closed.
+func (w *BufferedPipeWriter) Write(data []byte) (int, error) {
+	return w.p.write(context.Background(), data)
+}
+
+// WriteContext is like Write but stops blocking when the context is done, in
+// which case it returns the number of bytes buffered and the context error.
+func (w *BufferedPipeWriter) WriteContext(ctx context.Context, data []byte) (int, error) {
+	return w.p.write(ctx, data)
+}
+
+// Close closes the writer, causing reads to return [io.EOF] once drained.
+func (w *BufferedPipeWriter) Close() error {
+	return w.CloseWithError(nil)
+}
+
+// CloseWithError closes the writer, causing reads to return err, or [io.EOF]
+// if err is nil, once drained. Only the first close sets the error.
+func (w *BufferedPipeWriter) CloseWithError(err error) error {
+	w.p.closeWrite(err)
+	return nil
+}
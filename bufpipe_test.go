@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBufferedPipe(t *testing.T) {
+	t.Run("writes do not block until the buffer is full", func(t *testing.T) {
+		pr, pw := BufferedPipe(8)
+		count, err := pw.Write([]byte("hello"))
+		require.NoError(t, err)
+		assert.Equal(t, 5, count)
+
+		buf := make([]byte, 16)
+		count, err = pr.Read(buf)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(buf[:count]))
+	})
+
+	t.Run("data survives wrapping around the ring buffer", func(t *testing.T) {
+		payload := strings.Repeat("0123456789", 1000)
+		pr, pw := BufferedPipe(7)
+		go func() {
+			for data := []byte(payload); len(data) > 0; data = data[min(3, len(data)):] {
+				pw.Write(data[:min(3, len(data))])
+			}
+			pw.Close()
+		}()
+
+		var out bytes.Buffer
+		buf := make([]byte, 5)
+		for {
+			count, err := pr.Read(buf)
+			out.Write(buf[:count])
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+		}
+		assert.Equal(t, payload, out.String())
+	})
+
+	t.Run("large writes block until the reader drains the buffer", func(t *testing.T) {
+		payload := strings.Repeat("x", 100)
+		pr, pw := BufferedPipe(10)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			count, err := pw.Write([]byte(payload))
+			assert.NoError(t, err)
+			assert.Equal(t, len(payload), count)
+			pw.Close()
+		}()
+
+		data, err := io.ReadAll(pr)
+		require.NoError(t, err)
+		assert.Equal(t, payload, string(data))
+		<-done
+	})
+
+	t.Run("WriteContext stops when the context is done", func(t *testing.T) {
+		_, pw := BufferedPipe(4)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		count, err := pw.WriteContext(ctx, []byte("0123456789"))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, 4, count)
+	})
+
+	t.Run("ReadContext stops when the context is done", func(t *testing.T) {
+		pr, _ := BufferedPipe(4)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		count, err := pr.ReadContext(ctx, make([]byte, 4))
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("reads drain the buffer before returning the writer error", func(t *testing.T) {
+		expected := errors.New("mocked producer error")
+		pr, pw := BufferedPipe(0)
+		_, err := pw.Write([]byte("abc"))
+		require.NoError(t, err)
+		require.NoError(t, pw.CloseWithError(expected))
+
+		data, err := io.ReadAll(pr)
+		require.ErrorIs(t, err, expected)
+		assert.Equal(t, "abc", string(data))
+
+		_, err = pw.Write([]byte("abc"))
+		assert.ErrorIs(t, err, io.ErrClosedPipe)
+	})
+
+	t.Run("closing the reader unblocks and fails writes", func(t *testing.T) {
+		expected := errors.New("mocked consumer error")
+		pr, pw := BufferedPipe(2)
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			pr.CloseWithError(expected)
+		}()
+		count, err := pw.Write([]byte("0123456789"))
+		require.ErrorIs(t, err, expected)
+		assert.Equal(t, 2, count)
+
+		_, err = pr.Read(make([]byte, 1))
+		assert.ErrorIs(t, err, io.ErrClosedPipe)
+	})
+
+	t.Run("Close uses the io.Pipe errors", func(t *testing.T) {
+		pr, pw := BufferedPipe(4)
+		require.NoError(t, pw.Close())
+		_, err := pr.Read(make([]byte, 1))
+		assert.ErrorIs(t, err, io.EOF)
+
+		pr, pw = BufferedPipe(4)
+		require.NoError(t, pr.Close())
+		_, err = pw.Write([]byte("a"))
+		assert.ErrorIs(t, err, io.ErrClosedPipe)
+	})
+}
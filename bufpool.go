@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultBufferSize is the default size of the buffers used for copying.
+const DefaultBufferSize = 32 << 10
+
+// bufferSize is the size of the buffers returned by getBuffer.
+var bufferSize = func() *atomic.Int64 {
+	v := &atomic.Int64{}
+	v.Store(DefaultBufferSize)
+	return v
+}()
+
+// bufferPool contains *[]byte buffers reused across copies.
+var bufferPool sync.Pool
+
+// SetBufferSize sets the size of the buffers used by [CopyContext] and the
+// other functions copying data, which reuse buffers through an internal pool.
+//
+// A size <= 0 restores [DefaultBufferSize]. It is safe to call SetBufferSize
+// concurrently with copies: buffers with a stale size are not reused.
+func SetBufferSize(size int) {
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+	bufferSize.Store(int64(size))
+}
+
+// BufferSize returns the size of the buffers used for copying.
+func BufferSize() int {
+	return int(bufferSize.Load())
+}
+
+// getBuffer returns a buffer from the pool or allocates a new one.
+//
+// Callers MUST call putBuffer once done using the buffer.
+func getBuffer() *[]byte {
+	size := BufferSize()
+	if bp, ok := bufferPool.Get().(*[]byte); ok && len(*bp) == size {
+		return bp
+	}
+	buf := make([]byte, size)
+	return &buf
+}
+
+// putBuffer returns a buffer obtained using getBuffer to the pool.
+func putBuffer(bp *[]byte) {
+	if len(*bp) == BufferSize() {
+		bufferPool.Put(bp)
+	}
+}
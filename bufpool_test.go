@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetBufferSize(t *testing.T) {
+	defer SetBufferSize(0)
+
+	// By default we use the default buffer size.
+	assert.Equal(t, DefaultBufferSize, BufferSize())
+	bp := getBuffer()
+	assert.Len(t, *bp, DefaultBufferSize)
+	putBuffer(bp)
+
+	// Once changed, buffers with a stale size are not reused.
+	SetBufferSize(7)
+	assert.Equal(t, 7, BufferSize())
+	bp = getBuffer()
+	assert.Len(t, *bp, 7)
+	putBuffer(bp)
+
+	// Copying still works when using small buffers.
+	const payload = "hello from the buffer pool"
+	buff := &bytes.Buffer{}
+	lwc := NewLockedWriteCloser(NopWriteCloser(buff))
+	rc := &iotest.FuncReadCloser{
+		ReadFunc:  strings.NewReader(payload).Read,
+		CloseFunc: func() error { return nil },
+	}
+	count, err := CopyContext(context.Background(), lwc, rc)
+	require.NoError(t, err)
+	assert.Equal(t, len(payload), count)
+	assert.Equal(t, payload, buff.String())
+
+	// A nonpositive size restores the default.
+	SetBufferSize(-1)
+	assert.Equal(t, DefaultBufferSize, BufferSize())
+}
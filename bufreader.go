@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bufio"
+	"context"
+	"io"
+)
+
+// BufferedReadCloser is a buffered [io.ReadCloser] similar to a [*bufio.Reader]
+// whose reads from the wrapped [io.ReadCloser] are context-interruptible.
+//
+// It allows, e.g., to peek at the first bytes of a connection to sniff the protocol
+// and then route it, while being able to give up when the context is done. The
+// methods with a Context suffix fill the buffer using [ReadContext], so, when the
+// context is done, they unblock using read deadlines or, when the wrapped reader
+// does not support them, by closing it. The other methods are like the ones of
+// [*bufio.Reader] and block until the wrapped reader returns.
+//
+// Because it reads ahead into an internal buffer, you MUST read through the
+// [*BufferedReadCloser] after wrapping, rather than reading from the wrapped
+// [io.ReadCloser] directly.
+//
+// Not safe for concurrent use, except that Close may be called concurrently with
+// reads to interrupt them, as [CopyContext] does on cancellation.
+//
+// Construct using [NewBufferedReadCloser].
+type BufferedReadCloser struct {
+	br  *bufio.Reader
+	ctx context.Context
+	rc  io.ReadCloser
+}
+
+// NewBufferedReadCloser wraps rc and returns a [*BufferedReadCloser] whose buffer
+// has the given size. Nonpositive values of size cause NewBufferedReadCloser to use
+// the default size of [*bufio.Reader], that is, 4096 bytes.
+func NewBufferedReadCloser(rc io.ReadCloser, size int) *BufferedReadCloser {
+	r := &BufferedReadCloser{ctx: context.Background(), rc: rc}
+	fill := ReaderFunc(func(buf []byte) (int, error) {
+		return ReadContext(r.ctx, r.rc, buf)
+	})
+	if size <= 0 {
+		r.br = bufio.NewReader(fill)
+	} else {
+		r.br = bufio.NewReaderSize(fill, size)
+	}
+	return r
+}
+
+// withContext sets the context used to fill the buffer, invokes fn, and then
+// restores the background context.
+func withContext[T any](r *BufferedReadCloser, ctx context.Context, fn func() (T, error)) (T, error) {
+	r.ctx = ctx
+	defer func() { r.ctx = context.Background() }()
+	return fn()
+}
+
+// Read implements [io.Reader] like [*bufio.Reader.Read].
+func (r *BufferedReadCloser) Read(buf []byte) (int, error) {
+	return r.br.Read(buf)
+}
+
+// ReadContext is like Read but stops filling the buffer when the context is done.
+func (r *BufferedReadCloser) ReadContext(ctx context.Context, buf []byte) (int, error) {
+	return withContext(r, ctx, func() (int, error) { return r.br.Read(buf) })
+}
+
+// Peek returns the next n bytes without advancing the reader, like [*bufio.Reader.Peek].
+//
+// The returned bytes are only valid until the next read. When Peek returns fewer
+// than n bytes, it also returns an error explaining why, which is [bufio.ErrBufferFull]
+// when n is larger than the buffer size.
+func (r *BufferedReadCloser) Peek(n int) ([]byte, error) {
+	return r.br.Peek(n)
+}
+
+// PeekContext is like Peek but stops filling the buffer when the context is done.
+func (r *BufferedReadCloser) PeekContext(ctx context.Context, n int) ([]byte, error) {
+	return withContext(r, ctx, func() ([]byte, error) { return r.br.Peek(n) })
+}
+
+// Discard skips the next n bytes, returning the number of bytes discarded, like
+// [*bufio.Reader.Discard].
+func (r *BufferedReadCloser) Discard(n int) (int, error) {
+	return r.br.Discard(n)
+}
+
+// DiscardContext is like Discard but stops filling the buffer when the context is done.
+func (r *BufferedReadCloser) DiscardContext(ctx context.Context, n int) (int, error) {
+	return withContext(r, ctx, func() (int, error) { return r.br.Discard(n) })
+}
+
+// ReadSlice reads until the first occurrence of delim, like [*bufio.Reader.ReadSlice].
+//
+// The returned bytes point into the internal buffer and are only valid until the
+// next read. ReadSlice fails with [bufio.ErrBufferFull] if the buffer fills without
+// finding delim.
+func (r *BufferedReadCloser) ReadSlice(delim byte) ([]byte, error) {
+	return r.br.ReadSlice(delim)
+}
+
+// ReadSliceContext is like ReadSlice but stops filling the buffer when the context is done.
+func (r *BufferedReadCloser) ReadSliceContext(ctx context.Context, delim byte) ([]byte, error) {
+	return withContext(r, ctx, func() ([]byte, error) { return r.br.ReadSlice(delim) })
+}
+
+// Buffered returns the number of bytes that can be read from the internal buffer.
+func (r *BufferedReadCloser) Buffered() int {
+	return r.br.Buffered()
+}
+
+// Close implements [io.Closer].
+func (r *BufferedReadCloser) Close() error {
+	return r.rc.Close()
+}
@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBufferedReadCloser(t *testing.T) {
+	t.Run("peek then read", func(t *testing.T) {
+		r := NewBufferedReadCloser(NopReadCloser(strings.NewReader("GET / HTTP/1.1\r\n")), 0)
+		prefix, err := r.Peek(3)
+		require.NoError(t, err)
+		assert.Equal(t, "GET", string(prefix))
+		assert.Equal(t, 16, r.Buffered())
+
+		line, err := r.ReadSlice('\n')
+		require.NoError(t, err)
+		assert.Equal(t, "GET / HTTP/1.1\r\n", string(line))
+		assert.Equal(t, 0, r.Buffered())
+	})
+
+	t.Run("discard and read", func(t *testing.T) {
+		r := NewBufferedReadCloser(NopReadCloser(strings.NewReader("0123456789")), 16)
+		count, err := r.DiscardContext(context.Background(), 4)
+		require.NoError(t, err)
+		assert.Equal(t, 4, count)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, "456789", string(data))
+	})
+
+	t.Run("peeking more than the buffer size", func(t *testing.T) {
+		r := NewBufferedReadCloser(NopReadCloser(strings.NewReader(strings.Repeat("x", 64))), 16)
+		data, err := r.PeekContext(context.Background(), 32)
+		require.ErrorIs(t, err, bufio.ErrBufferFull)
+		assert.Len(t, data, 16)
+	})
+
+	t.Run("canceling with read deadlines keeps the connection usable", func(t *testing.T) {
+		client, server := net.Pipe()
+		defer client.Close()
+		defer server.Close()
+		r := NewBufferedReadCloser(server, 0)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := r.PeekContext(ctx, 4)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		go client.Write([]byte("SSH-2.0\r\n"))
+		line, err := r.ReadSliceContext(context.Background(), '\n')
+		require.NoError(t, err)
+		assert.Equal(t, "SSH-2.0\r\n", string(line))
+	})
+
+	t.Run("canceling without read deadlines closes the reader", func(t *testing.T) {
+		inside := make(chan struct{})
+		unblock := make(chan struct{})
+		var closed atomic.Bool
+		r := NewBufferedReadCloser(&iotest.FuncReadCloser{
+			ReadFunc: func(b []byte) (int, error) {
+				close(inside)
+				<-unblock
+				return 0, io.ErrClosedPipe
+			},
+			CloseFunc: func() error {
+				closed.Store(true)
+				close(unblock)
+				return nil
+			},
+		}, 0)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			<-inside
+			cancel()
+		}()
+		count, err := r.ReadContext(ctx, make([]byte, 4))
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 0, count)
+		assert.True(t, closed.Load())
+	})
+
+	t.Run("Close forwards to the wrapped reader", func(t *testing.T) {
+		var closed atomic.Bool
+		r := NewBufferedReadCloser(&iotest.FuncReadCloser{
+			CloseFunc: func() error {
+				closed.Store(true)
+				return nil
+			},
+		}, 0)
+		require.NoError(t, r.Close())
+		assert.True(t, closed.Load())
+	})
+}
@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bufio"
+	"io"
+)
+
+// ByteReadCloser is a buffered [io.ReadCloser] implementing [io.ByteReader].
+//
+// It allows to use an [io.ReadCloser] with APIs requiring an [io.ByteReader], such
+// as [encoding/binary.ReadUvarint], while still forwarding Close. Because it reads
+// ahead into an internal buffer, you MUST read through the [*ByteReadCloser] after
+// wrapping, rather than reading from the wrapped [io.ReadCloser] directly.
+//
+// Not safe for concurrent use, except that Close may be called concurrently with
+// reads to interrupt them, as [CopyContext] does on cancellation.
+//
+// Construct using [NewByteReadCloser].
+type ByteReadCloser struct {
+	br *bufio.Reader
+	rc io.ReadCloser
+}
+
+var _ io.ByteScanner = &ByteReadCloser{}
+
+// NewByteReadCloser wraps rc and returns a [*ByteReadCloser].
+func NewByteReadCloser(rc io.ReadCloser) *ByteReadCloser {
+	return &ByteReadCloser{br: bufio.NewReader(rc), rc: rc}
+}
+
+// Read implements [io.Reader].
+func (r *ByteReadCloser) Read(buf []byte) (int, error) {
+	return r.br.Read(buf)
+}
+
+// ReadByte implements [io.ByteReader].
+func (r *ByteReadCloser) ReadByte() (byte, error) {
+	return r.br.ReadByte()
+}
+
+// UnreadByte implements [io.ByteScanner].
+func (r *ByteReadCloser) UnreadByte() error {
+	return r.br.UnreadByte()
+}
+
+// Buffered returns the number of bytes that can be read from the internal buffer.
+func (r *ByteReadCloser) Buffered() int {
+	return r.br.Buffered()
+}
+
+// Close implements [io.Closer].
+func (r *ByteReadCloser) Close() error {
+	return r.rc.Close()
+}
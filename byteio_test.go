@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestByteReadCloserAndByteWriter(t *testing.T) {
+	// Write varints one byte at a time through the locked writer.
+	buff := &bytes.Buffer{}
+	lwc := NewLockedWriteCloser(NopWriteCloser(buff))
+	bw := lwc.ByteWriter()
+	for _, value := range []uint64{1, 300, 1 << 40} {
+		for _, c := range binary.AppendUvarint(nil, value) {
+			require.NoError(t, bw.WriteByte(c))
+		}
+	}
+	assert.Equal(t, buff.Len(), lwc.Count())
+
+	// Read them back using the byte reader.
+	closed := false
+	brc := NewByteReadCloser(JoinReadCloser(buff, CloserFunc(func() error {
+		closed = true
+		return nil
+	})))
+	for _, expected := range []uint64{1, 300, 1 << 40} {
+		value, err := binary.ReadUvarint(brc)
+		require.NoError(t, err)
+		assert.Equal(t, expected, value)
+	}
+	_, err := brc.ReadByte()
+	require.ErrorIs(t, err, io.EOF)
+
+	require.NoError(t, brc.Close())
+	assert.True(t, closed)
+}
+
+func TestLockedWriteByte(t *testing.T) {
+	t.Run("with a writer not implementing io.ByteWriter", func(t *testing.T) {
+		buff := &bytes.Buffer{}
+		lwc := NewLockedWriteCloser(NopWriteCloser(writerOnly{buff}))
+		require.NoError(t, lwc.LockedWriteByte('x'))
+		assert.Equal(t, "x", buff.String())
+		assert.Equal(t, 1, lwc.Count())
+	})
+
+	t.Run("once closed", func(t *testing.T) {
+		lwc := NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{}))
+		require.NoError(t, lwc.Close())
+		require.ErrorIs(t, lwc.LockedWriteByte('x'), ErrClosed)
+	})
+}
+
+func TestByteReadCloserUnreadByte(t *testing.T) {
+	brc := NewByteReadCloser(NopReadCloser(bytes.NewReader([]byte("iox"))))
+	c, err := brc.ReadByte()
+	require.NoError(t, err)
+	assert.Equal(t, byte('i'), c)
+	assert.Equal(t, 2, brc.Buffered())
+
+	require.NoError(t, brc.UnreadByte())
+	data, err := io.ReadAll(brc)
+	require.NoError(t, err)
+	assert.Equal(t, "iox", string(data))
+}
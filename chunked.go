@@ -0,0 +1,213 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"sync"
+)
+
+// ErrMalformedChunk is returned when reading malformed chunked data.
+var ErrMalformedChunk = errors.New("malformed chunked encoding")
+
+// ChunkedWriter implements the HTTP/1.1 chunked transfer coding.
+//
+// Each Write emits a chunk and Close emits the last chunk followed by the
+// trailers, if any. Close does NOT close the underlying [io.Writer], which
+// is typically a connection we want to keep using.
+//
+// Write and Close are serialized, so they are safe for concurrent use.
+//
+// Construct using [NewChunkedWriter].
+type ChunkedWriter struct {
+	// Trailer contains the trailers to emit on Close.
+	//
+	// You MUST NOT modify it concurrently with Close.
+	Trailer textproto.MIMEHeader
+
+	cs closeState
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewChunkedWriter returns a new [*ChunkedWriter] writing into w.
+func NewChunkedWriter(w io.Writer) *ChunkedWriter {
+	return &ChunkedWriter{Trailer: textproto.MIMEHeader{}, w: w}
+}
+
+// Write implements [io.Writer].
+//
+// Empty writes are ignored, since an empty chunk marks the end of the body.
+//
+// The returned error is nil, [ErrClosed] when closed, or the error occurred
+// when writing into the underlying [io.Writer].
+func (cw *ChunkedWriter) Write(data []byte) (int, error) {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	if err := cw.cs.check(); err != nil {
+		return 0, err
+	}
+	if len(data) <= 0 {
+		return 0, nil
+	}
+	if _, err := fmt.Fprintf(cw.w, "%x\r\n", len(data)); err != nil {
+		return 0, err
+	}
+	count, err := cw.w.Write(data)
+	if err != nil {
+		return count, err
+	}
+	if _, err := io.WriteString(cw.w, "\r\n"); err != nil {
+		return count, err
+	}
+	return count, nil
+}
+
+// Close writes the last chunk and the trailers.
+//
+// Returns nil, [ErrClosed], or the error occurred when writing.
+func (cw *ChunkedWriter) Close() error {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	return cw.cs.close(func() error {
+		buff := &bytes.Buffer{}
+		buff.WriteString("0\r\n")
+		for key, values := range cw.Trailer {
+			for _, value := range values {
+				fmt.Fprintf(buff, "%s: %s\r\n", key, value)
+			}
+		}
+		buff.WriteString("\r\n")
+		_, err := cw.w.Write(buff.Bytes())
+		return err
+	})
+}
+
+// ChunkedReadCloser decodes the HTTP/1.1 chunked transfer coding.
+//
+// It reads until the last chunk, then parses the trailers, which are available
+// using [*ChunkedReadCloser.Trailer] once Read has returned [io.EOF].
+//
+// Reads are context-interruptible: on context cancellation, the underlying
+// [io.ReadCloser] is closed to unblock any in-flight Read. Because it reads
+// ahead into an internal buffer, it may consume bytes following the body.
+//
+// Not safe for concurrent use, except that Close may be called concurrently
+// with Read to interrupt it, as [CopyContext] does on cancellation.
+//
+// Construct using [NewChunkedReadCloser].
+type ChunkedReadCloser struct {
+	br        *bufio.Reader
+	err       error
+	needCRLF  bool
+	rc        io.ReadCloser
+	remaining uint64
+	trailer   textproto.MIMEHeader
+}
+
+// NewChunkedReadCloser returns a new [*ChunkedReadCloser] reading from rc.
+func NewChunkedReadCloser(ctx context.Context, rc io.ReadCloser) *ChunkedReadCloser {
+	return &ChunkedReadCloser{br: bufio.NewReader(contextReader{ctx, rc}), rc: rc}
+}
+
+// Read implements [io.Reader].
+//
+// The returned error is nil, [io.EOF] after the trailers, [io.ErrUnexpectedEOF]
+// when the stream is truncated, [ErrMalformedChunk], or the error caused by
+// I/O or by the context.
+func (cr *ChunkedReadCloser) Read(buf []byte) (int, error) {
+	if cr.err != nil {
+		return 0, cr.err
+	}
+	if cr.remaining <= 0 {
+		if cr.err = cr.beginChunk(); cr.err != nil {
+			return 0, cr.err
+		}
+	}
+	count, err := cr.br.Read(buf[:min(uint64(len(buf)), cr.remaining)])
+	cr.remaining -= uint64(count)
+	cr.needCRLF = cr.remaining <= 0
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	cr.err = err
+	return count, err
+}
+
+// beginChunk reads the next chunk header, and the trailers after the last chunk.
+func (cr *ChunkedReadCloser) beginChunk() error {
+	// 1. consume the CRLF terminating the previous chunk
+	if cr.needCRLF {
+		line, err := cr.readLine()
+		if err != nil {
+			return err
+		}
+		if len(line) > 0 {
+			return ErrMalformedChunk
+		}
+		cr.needCRLF = false
+	}
+
+	// 2. parse the chunk size ignoring chunk extensions
+	line, err := cr.readLine()
+	if err != nil {
+		return err
+	}
+	if idx := bytes.IndexByte(line, ';'); idx >= 0 {
+		line = line[:idx]
+	}
+	size, err := strconv.ParseUint(string(bytes.TrimSpace(line)), 16, 63)
+	if err != nil {
+		return ErrMalformedChunk
+	}
+	cr.remaining = size
+
+	// 3. after the last chunk, read the trailers
+	if size <= 0 {
+		trailer, err := textproto.NewReader(cr.br).ReadMIMEHeader()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		cr.trailer = trailer
+		return io.EOF
+	}
+	return nil
+}
+
+// readLine reads a CRLF-terminated line and returns it without the CRLF.
+func (cr *ChunkedReadCloser) readLine() ([]byte, error) {
+	line, err := cr.br.ReadSlice('\n')
+	switch {
+	case errors.Is(err, io.EOF):
+		return nil, io.ErrUnexpectedEOF
+	case errors.Is(err, bufio.ErrBufferFull):
+		return nil, ErrMalformedChunk
+	case err != nil:
+		return nil, err
+	}
+	line, ok := bytes.CutSuffix(line, []byte("\r\n"))
+	if !ok {
+		return nil, ErrMalformedChunk
+	}
+	return line, nil
+}
+
+// Trailer returns the trailers, which are only available after Read returned [io.EOF].
+func (cr *ChunkedReadCloser) Trailer() textproto.MIMEHeader {
+	return cr.trailer
+}
+
+// Close implements [io.Closer].
+func (cr *ChunkedReadCloser) Close() error {
+	return cr.rc.Close()
+}
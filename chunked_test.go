@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkedWriterAndReadCloser(t *testing.T) {
+	// Write chunks followed by trailers.
+	buff := &bytes.Buffer{}
+	cw := NewChunkedWriter(buff)
+	for _, chunk := range []string{"hello", "", ", chunked world"} {
+		_, err := cw.Write([]byte(chunk))
+		require.NoError(t, err)
+	}
+	cw.Trailer.Set("X-Checksum", "abc")
+	require.NoError(t, cw.Close())
+	assert.Equal(t, "5\r\nhello\r\nf\r\n, chunked world\r\n0\r\nX-Checksum: abc\r\n\r\n", buff.String())
+
+	// Once closed we cannot write anymore.
+	_, err := cw.Write([]byte("x"))
+	require.ErrorIs(t, err, ErrClosed)
+	require.ErrorIs(t, cw.Close(), ErrClosed)
+
+	// Read back the body and the trailers.
+	cr := NewChunkedReadCloser(context.Background(), NopReadCloser(buff))
+	data, err := io.ReadAll(cr)
+	require.NoError(t, err)
+	assert.Equal(t, "hello, chunked world", string(data))
+	assert.Equal(t, "abc", cr.Trailer().Get("X-Checksum"))
+	require.NoError(t, cr.Close())
+}
+
+func TestChunkedReadCloserWithExtensionsAndNoTrailers(t *testing.T) {
+	input := "3;name=value\r\niox\r\n0\r\n\r\n"
+	cr := NewChunkedReadCloser(context.Background(), NopReadCloser(strings.NewReader(input)))
+	data, err := io.ReadAll(cr)
+	require.NoError(t, err)
+	assert.Equal(t, "iox", string(data))
+	assert.Empty(t, cr.Trailer())
+}
+
+func TestChunkedReadCloserErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		expected error
+	}{{
+		name:     "with an invalid chunk size",
+		input:    "zz\r\niox\r\n0\r\n\r\n",
+		expected: ErrMalformedChunk,
+	}, {
+		name:     "with a missing CRLF after the chunk",
+		input:    "3\r\nioxx\r\n0\r\n\r\n",
+		expected: ErrMalformedChunk,
+	}, {
+		name:     "with a bare LF",
+		input:    "3\niox\r\n0\r\n\r\n",
+		expected: ErrMalformedChunk,
+	}, {
+		name:     "with a truncated chunk",
+		input:    "5\r\niox",
+		expected: io.ErrUnexpectedEOF,
+	}, {
+		name:     "with missing trailers",
+		input:    "3\r\niox\r\n0\r\n",
+		expected: io.ErrUnexpectedEOF,
+	}, {
+		name:     "with a chunk header too long",
+		input:    strings.Repeat("0", 8192),
+		expected: ErrMalformedChunk,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cr := NewChunkedReadCloser(context.Background(), NopReadCloser(strings.NewReader(tc.input)))
+			_, err := io.ReadAll(cr)
+			require.ErrorIs(t, err, tc.expected)
+		})
+	}
+}
+
+func TestChunkedReadCloserWithCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cr := NewChunkedReadCloser(ctx, NopReadCloser(strings.NewReader("3\r\niox\r\n0\r\n\r\n")))
+	_, err := io.ReadAll(cr)
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"errors"
+	"io"
+	"runtime/debug"
+	"slices"
+	"sync"
+)
+
+// closeState implements idempotent close semantics.
+//
+// The zero value is ready to use. It is not safe for concurrent use, so the
+// caller MUST provide synchronization (e.g., by holding a mutex).
+//
+// When debugging (see [SetDebug]), it records the stack of the first close and
+// reports uses after close and double closes, unless marked as idempotent.
+type closeState struct {
+	err        error
+	idempotent bool
+	quiet      bool
+	stack      []byte
+}
+
+// check returns nil when not closed and [ErrClosed] otherwise.
+func (s *closeState) check() error {
+	if s.err != nil && !s.quiet && debugEnabled() {
+		debugReport("use after close", s.stack)
+	}
+	return s.err
+}
+
+// close marks as closed and returns the result of calling closefn the first
+// time it is invoked. Subsequent invocations return [ErrClosed].
+func (s *closeState) close(closefn func() error) error {
+	if err := s.err; err != nil {
+		if !s.idempotent && !s.quiet && debugEnabled() {
+			debugReport("double close", s.stack)
+		}
+		return err
+	}
+	s.err = ErrClosed
+	if debugEnabled() {
+		s.stack = debug.Stack()
+	}
+	return closefn()
+}
+
+// CloseOnce wraps an [io.Closer] such that its Close runs exactly once.
+//
+// The first call to Close returns the result of closing c, while subsequent calls
+// return [ErrClosed]. Close is safe for concurrent use and concurrent callers
+// wait for the first Close to return.
+func CloseOnce(c io.Closer) io.Closer {
+	return &onceCloser{c: c, cs: closeState{idempotent: true}}
+}
+
+// onceCloser is the [io.Closer] returned by [CloseOnce].
+type onceCloser struct {
+	c  io.Closer
+	cs closeState
+	mu sync.Mutex
+}
+
+// Close implements [io.Closer].
+func (oc *onceCloser) Close() error {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	return oc.cs.close(oc.c.Close)
+}
+
+// MultiCloser returns an [io.Closer] whose Close closes all the given closers.
+//
+// Close invokes every closer in the given order, even when some of them fail, and
+// returns their errors joined using [errors.Join]. Like [CloseOnce], only the
+// first call to Close closes and subsequent calls return [ErrClosed].
+func MultiCloser(closers ...io.Closer) io.Closer {
+	return &onceCloser{c: multiCloser(slices.Clone(closers)), cs: closeState{idempotent: true}}
+}
+
+// ReverseMultiCloser is like [MultiCloser] but closes in reverse order.
+//
+// This is convenient for tearing down layered wrappers, which we typically
+// create from the innermost to the outermost but need to close the other
+// way around (e.g., a compressor before the connection it writes to).
+func ReverseMultiCloser(closers ...io.Closer) io.Closer {
+	closers = slices.Clone(closers)
+	slices.Reverse(closers)
+	return &onceCloser{c: multiCloser(closers), cs: closeState{idempotent: true}}
+}
+
+// multiCloser is the [io.Closer] used by [MultiCloser] and [ReverseMultiCloser].
+type multiCloser []io.Closer
+
+// Close implements [io.Closer].
+func (mc multiCloser) Close() error {
+	var errs []error
+	for _, c := range mc {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloseOnce(t *testing.T) {
+	// Use a closer that fails to make sure we return the original result.
+	expected := errors.New("mocked close error")
+	calls := &atomic.Int64{}
+	closer := CloseOnce(CloserFunc(func() error {
+		calls.Add(1)
+		return expected
+	}))
+
+	// Close concurrently and collect the results.
+	const ncallers = 8
+	errch := make(chan error, ncallers)
+	wg := &sync.WaitGroup{}
+	for range ncallers {
+		wg.Go(func() {
+			errch <- closer.Close()
+		})
+	}
+	wg.Wait()
+	close(errch)
+
+	// Exactly one caller must see the original error.
+	var nexpected, nclosed int
+	for err := range errch {
+		switch {
+		case errors.Is(err, expected):
+			nexpected++
+		case errors.Is(err, ErrClosed):
+			nclosed++
+		}
+	}
+	assert.Equal(t, 1, nexpected)
+	assert.Equal(t, ncallers-1, nclosed)
+	assert.Equal(t, int64(1), calls.Load())
+
+	// Subsequent calls keep failing with ErrClosed.
+	require.ErrorIs(t, closer.Close(), ErrClosed)
+}
+
+func TestMultiCloser(t *testing.T) {
+	// newClosers returns closers recording the closing order.
+	expected := errors.New("mocked close error")
+	newClosers := func(order *[]int) []io.Closer {
+		return []io.Closer{
+			CloserFunc(func() error {
+				*order = append(*order, 0)
+				return nil
+			}),
+			CloserFunc(func() error {
+				*order = append(*order, 1)
+				return expected
+			}),
+			CloserFunc(func() error {
+				*order = append(*order, 2)
+				return nil
+			}),
+		}
+	}
+
+	t.Run("MultiCloser", func(t *testing.T) {
+		var order []int
+		closer := MultiCloser(newClosers(&order)...)
+		require.ErrorIs(t, closer.Close(), expected)
+		assert.Equal(t, []int{0, 1, 2}, order)
+
+		require.ErrorIs(t, closer.Close(), ErrClosed)
+		assert.Equal(t, []int{0, 1, 2}, order)
+	})
+
+	t.Run("ReverseMultiCloser", func(t *testing.T) {
+		var order []int
+		closer := ReverseMultiCloser(newClosers(&order)...)
+		require.ErrorIs(t, closer.Close(), expected)
+		assert.Equal(t, []int{2, 1, 0}, order)
+
+		require.ErrorIs(t, closer.Close(), ErrClosed)
+		assert.Equal(t, []int{2, 1, 0}, order)
+	})
+
+	t.Run("without closers", func(t *testing.T) {
+		require.NoError(t, MultiCloser().Close())
+	})
+}
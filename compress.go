@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+)
+
+// CompressorFunc returns an [io.WriteCloser] compressing the data written into it
+// and writing the compressed data into w. Closing it MUST finalize the compressed
+// stream without closing w.
+type CompressorFunc func(w io.Writer) (io.WriteCloser, error)
+
+var (
+	// compressorsMu protects compressors.
+	compressorsMu sync.RWMutex
+
+	// compressors maps normalized encoding names to compressors.
+	compressors = map[string]CompressorFunc{
+		"":         newIdentityCompressor,
+		"deflate":  newZlibCompressor,
+		"gzip":     newGzipCompressor,
+		"identity": newIdentityCompressor,
+		"x-gzip":   newGzipCompressor,
+	}
+)
+
+// newGzipCompressor is the [CompressorFunc] for gzip.
+func newGzipCompressor(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+// newZlibCompressor is the [CompressorFunc] for deflate.
+func newZlibCompressor(w io.Writer) (io.WriteCloser, error) {
+	return zlib.NewWriter(w), nil
+}
+
+// newIdentityCompressor is the [CompressorFunc] for uncompressed data.
+func newIdentityCompressor(w io.Writer) (io.WriteCloser, error) {
+	return NopWriteCloser(w), nil
+}
+
+// normalizeEncoding returns the lowercase form of encoding without leading and
+// trailing whitespace, which is the key used by the registries of encodings.
+func normalizeEncoding(encoding string) string {
+	return strings.ToLower(strings.TrimSpace(encoding))
+}
+
+// RegisterCompressor registers the [CompressorFunc] for the given encoding.
+//
+// Use this function to add support for encodings outside of the standard
+// library (e.g., "zstd" or "br") or to override the builtin ones. The
+// encoding name is case insensitive and leading and trailing whitespace
+// is ignored, like for [CompressWriteCloser].
+func RegisterCompressor(encoding string, fn CompressorFunc) {
+	compressorsMu.Lock()
+	compressors[normalizeEncoding(encoding)] = fn
+	compressorsMu.Unlock()
+}
+
+// CompressWriteCloser returns an [io.WriteCloser] compressing the data written into
+// it according to the given encoding, such as the value of an HTTP Content-Encoding
+// header, and writing the compressed data into w.
+//
+// We support "gzip", "deflate" (i.e., zlib as specified by RFC 9110), and "identity"
+// out of the box. Use [RegisterCompressor] to support more encodings.
+//
+// The returned value implements [Flusher]: Flush flushes the compressor, when it
+// has a Flush method, and then w (see [*LockedWriteCloser.LockedFlush]), which makes
+// the compressed data available to the reader, e.g., when streaming with
+// [WithFlushEachWrite]. Close finalizes the compressed stream and then closes w
+// exactly once, which is the ordering [CopyContext] needs when it closes the
+// destination. On failure, w is NOT closed.
+//
+// The returned error is nil, [ErrUnsupportedEncoding], or the error returned
+// when creating the compressor.
+func CompressWriteCloser(w io.WriteCloser, encoding string) (io.WriteCloser, error) {
+	compressorsMu.RLock()
+	fn, found := compressors[normalizeEncoding(encoding)]
+	compressorsMu.RUnlock()
+	if !found {
+		return nil, ErrUnsupportedEncoding
+	}
+	enc, err := fn(w)
+	if err != nil {
+		return nil, err
+	}
+	return &compressWriteCloser{enc: enc, w: w}, nil
+}
+
+// compressWriteCloser is the [io.WriteCloser] returned by [CompressWriteCloser].
+type compressWriteCloser struct {
+	cs  closeState
+	enc io.WriteCloser
+	w   io.WriteCloser
+}
+
+var _ Flusher = &compressWriteCloser{}
+
+// Write implements [io.Writer].
+func (w *compressWriteCloser) Write(data []byte) (int, error) {
+	if err := w.cs.check(); err != nil {
+		return 0, err
+	}
+	return w.enc.Write(data)
+}
+
+// Flush implements [Flusher].
+func (w *compressWriteCloser) Flush() error {
+	if err := w.cs.check(); err != nil {
+		return err
+	}
+	if err := flushWriter(w.enc); err != nil {
+		return err
+	}
+	return flushWriter(w.w)
+}
+
+// Close implements [io.Closer].
+func (w *compressWriteCloser) Close() error {
+	return w.cs.close(func() error {
+		return errors.Join(w.enc.Close(), w.w.Close())
+	})
+}
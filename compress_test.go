@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressWriteCloser(t *testing.T) {
+	payload := strings.Repeat("iox-compress ", 1000)
+
+	for _, encoding := range []string{"gzip", "X-Gzip", "deflate", "identity", ""} {
+		t.Run("round trip with "+encoding, func(t *testing.T) {
+			var buf bytes.Buffer
+			wc, err := CompressWriteCloser(NopWriteCloser(&buf), encoding)
+			require.NoError(t, err)
+			count, err := CopyContext(context.Background(), NewLockedWriteCloser(wc),
+				NopReadCloser(strings.NewReader(payload)))
+			require.NoError(t, err)
+			assert.Equal(t, len(payload), count)
+
+			rc, err := DecompressReadCloser(NopReadCloser(&buf), encoding, 1<<20)
+			require.NoError(t, err)
+			data, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			assert.Equal(t, payload, string(data))
+		})
+	}
+
+	t.Run("close finalizes and then closes exactly once", func(t *testing.T) {
+		var events []string
+		var buf bytes.Buffer
+		wc, err := CompressWriteCloser(&iotest.FuncWriteCloser{
+			WriteFunc: func(b []byte) (int, error) {
+				events = append(events, "write")
+				return buf.Write(b)
+			},
+			CloseFunc: func() error {
+				events = append(events, "close")
+				return nil
+			},
+		}, "gzip")
+		require.NoError(t, err)
+		_, err = wc.Write([]byte("iox"))
+		require.NoError(t, err)
+		require.NoError(t, wc.Close())
+		require.ErrorIs(t, wc.Close(), ErrClosed)
+		require.NotEmpty(t, events)
+		assert.Equal(t, "close", events[len(events)-1])
+		assert.Equal(t, 1, strings.Count(strings.Join(events, " "), "close"))
+		_, err = wc.Write([]byte("iox"))
+		require.ErrorIs(t, err, ErrClosed)
+	})
+
+	t.Run("flush makes the compressed data available", func(t *testing.T) {
+		var buf bytes.Buffer
+		wc, err := CompressWriteCloser(NopWriteCloser(&buf), "gzip")
+		require.NoError(t, err)
+		lwc := NewLockedWriteCloser(wc)
+		_, err = lwc.LockedWrite([]byte("hello"))
+		require.NoError(t, err)
+		require.NoError(t, lwc.LockedFlush())
+
+		zr, err := gzip.NewReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		data := make([]byte, 5)
+		_, err = io.ReadFull(zr, data)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(data))
+		require.NoError(t, lwc.Close())
+	})
+
+	t.Run("custom compressors", func(t *testing.T) {
+		expected := errors.New("mocked error")
+		RegisterCompressor("X-Failing", func(w io.Writer) (io.WriteCloser, error) {
+			return nil, expected
+		})
+		defer func() {
+			compressorsMu.Lock()
+			delete(compressors, "x-failing")
+			compressorsMu.Unlock()
+		}()
+		_, err := CompressWriteCloser(NopWriteCloser(io.Discard), "x-failing")
+		require.ErrorIs(t, err, expected)
+	})
+
+	t.Run("registered names are normalized", func(t *testing.T) {
+		RegisterCompressor(" X-Spaced ", newIdentityCompressor)
+		defer func() {
+			compressorsMu.Lock()
+			delete(compressors, "x-spaced")
+			compressorsMu.Unlock()
+		}()
+		_, err := CompressWriteCloser(NopWriteCloser(io.Discard), "x-spaced")
+		require.NoError(t, err)
+	})
+
+	t.Run("unsupported encoding", func(t *testing.T) {
+		_, err := CompressWriteCloser(NopWriteCloser(io.Discard), "rot13")
+		require.ErrorIs(t, err, ErrUnsupportedEncoding)
+	})
+}
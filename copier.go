@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"io"
+)
+
+// Copier is a handle for a [CopyContext] running in the background.
+//
+// All methods are safe for concurrent use.
+//
+// Construct using [StartCopy].
+type Copier struct {
+	cancel context.CancelCauseFunc
+	count  int
+	done   chan struct{}
+	err    error
+	lwc    *LockedWriteCloser
+}
+
+// StartCopy starts copying from rc into lwc in the background using [CopyContext].
+//
+// The ownership rules for rc and lwc and the options are the same of [CopyContext].
+// Use the returned [*Copier] to observe the progress, wait for the copy to finish,
+// or abort it.
+func StartCopy(ctx context.Context, lwc *LockedWriteCloser, rc io.ReadCloser, opts ...CopyOption) *Copier {
+	return startCopy(ctx, lwc, rc, nil, opts...)
+}
+
+// startCopy implements [StartCopy] and invokes onDone, if not nil, with the
+// results of the copy right before the copy is marked as finished.
+func startCopy(ctx context.Context, lwc *LockedWriteCloser, rc io.ReadCloser,
+	onDone func(count int, err error), opts ...CopyOption) *Copier {
+	ctx, cancel := context.WithCancelCause(ctx)
+	c := &Copier{
+		cancel: cancel,
+		done:   make(chan struct{}),
+		lwc:    lwc,
+	}
+	go func() {
+		defer close(c.done)
+		c.count, c.err = CopyContext(ctx, lwc, rc, opts...)
+		cancel(nil)
+		if onDone != nil {
+			onDone(c.count, c.err)
+		}
+	}()
+	return c
+}
+
+// Abort interrupts the copy, if still running, canceling its context with the given cause.
+//
+// A nil cause means [context.Canceled]. Abort does not wait for the copy to
+// finish, use Wait for that.
+func (c *Copier) Abort(cause error) {
+	c.cancel(cause)
+}
+
+// Bytes returns the number of bytes copied so far.
+//
+// It never waits for the copy, since it does not take the lock of the
+// [*LockedWriteCloser] (see [*LockedWriteCloser.Count]). With the zero-copy fast
+// path of [CopyContext], the count is only updated when the copy completes.
+func (c *Copier) Bytes() int {
+	return c.lwc.Count()
+}
+
+// Done returns a channel closed when the copy has finished.
+func (c *Copier) Done() <-chan struct{} {
+	return c.done
+}
+
+// Wait waits for the copy to finish and returns the results of [CopyContext].
+func (c *Copier) Wait() (int, error) {
+	<-c.done
+	return c.count, c.err
+}
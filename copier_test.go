@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartCopy(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		const payload = "hello, world"
+		buff := &bytes.Buffer{}
+		c := StartCopy(context.Background(),
+			NewLockedWriteCloser(NopWriteCloser(buff)), NopReadCloser(strings.NewReader(payload)))
+		<-c.Done()
+		count, err := c.Wait()
+		require.NoError(t, err)
+		assert.Equal(t, len(payload), count)
+		assert.Equal(t, len(payload), c.Bytes())
+		assert.Equal(t, payload, buff.String())
+
+		// aborting a finished copy has no effect
+		c.Abort(errors.New("too late"))
+		_, err = c.Wait()
+		require.NoError(t, err)
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		firstRead := make(chan struct{})
+		unblock := make(chan struct{})
+		reads := 0
+		rc := &iotest.FuncReadCloser{
+			ReadFunc: func(buf []byte) (int, error) {
+				reads++
+				if reads == 1 {
+					defer close(firstRead)
+					return copy(buf, "abc"), nil
+				}
+				<-unblock
+				return 0, io.EOF
+			},
+			CloseFunc: func() error {
+				close(unblock)
+				return nil
+			},
+		}
+		c := StartCopy(context.Background(), NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{})), rc)
+		<-firstRead
+
+		cause := errors.New("user requested abort")
+		c.Abort(cause)
+		count, err := c.Wait()
+		require.ErrorIs(t, err, context.Canceled)
+		require.ErrorIs(t, err, cause)
+		assert.LessOrEqual(t, count, 3)
+	})
+}
+
+func TestCopierBytesDoesNotBlock(t *testing.T) {
+	// With a destination implementing io.ReaderFrom, observing the progress
+	// must not wait for the copy, which is blocked reading.
+	pr, pw := io.Pipe()
+	c := StartCopy(context.Background(), NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{})), pr)
+	_, err := pw.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return c.Bytes() == 5 }, time.Second, time.Millisecond)
+
+	pw.Close()
+	count, err := c.Wait()
+	require.NoError(t, err)
+	assert.Equal(t, 5, count)
+}
@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// CopyGroup coordinates several [CopyContext] operations sharing a context.
+//
+// By default, the first copy to fail cancels the shared context, using its
+// error as the cause, which interrupts the other copies. Set ContinueOnError
+// to let the other copies continue instead.
+//
+// All methods are safe for concurrent use, but Go MUST NOT be called after Wait.
+//
+// Construct using [NewCopyGroup].
+type CopyGroup struct {
+	// ContinueOnError prevents a failing copy from canceling the other copies.
+	//
+	// [NewCopyGroup] sets it to false. You MUST NOT modify it after calling Go.
+	ContinueOnError bool
+
+	cancel   context.CancelCauseFunc
+	ctx      context.Context
+	firstErr error
+	mu       sync.Mutex
+	results  []CopyResult
+	wg       sync.WaitGroup
+}
+
+// NewCopyGroup returns a new [*CopyGroup] whose copies use a context derived from ctx.
+func NewCopyGroup(ctx context.Context) *CopyGroup {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &CopyGroup{cancel: cancel, ctx: ctx}
+}
+
+// Go starts copying from rc into lwc in the background using [CopyContext] with
+// the shared context and returns the index of the copy's result in the slice
+// returned by Wait.
+//
+// The ownership rules for rc and lwc and the options are the same of [CopyContext].
+func (g *CopyGroup) Go(lwc *LockedWriteCloser, rc io.ReadCloser, opts ...CopyOption) int {
+	g.mu.Lock()
+	index := len(g.results)
+	g.results = append(g.results, CopyResult{})
+	g.mu.Unlock()
+
+	g.wg.Go(func() {
+		count, err := CopyContext(g.ctx, lwc, rc, opts...)
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		g.results[index] = CopyResult{Count: count, Err: err}
+		if err != nil && g.firstErr == nil {
+			g.firstErr = err
+			if !g.ContinueOnError {
+				g.cancel(err)
+			}
+		}
+	})
+	return index
+}
+
+// Wait waits for all the copies to finish and returns their results, in the
+// order in which they were started, along with the first error that occurred.
+func (g *CopyGroup) Wait() ([]CopyResult, error) {
+	g.wg.Wait()
+	g.cancel(nil)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.results, g.firstErr
+}
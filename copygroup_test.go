@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	stdiotest "testing/iotest"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBlockingReadCloser returns a reader blocking until closed.
+func newBlockingReadCloser() io.ReadCloser {
+	unblock := make(chan struct{})
+	return &iotest.FuncReadCloser{
+		ReadFunc: func(buf []byte) (int, error) {
+			<-unblock
+			return 0, io.EOF
+		},
+		CloseFunc: func() error {
+			close(unblock)
+			return nil
+		},
+	}
+}
+
+func TestCopyGroup(t *testing.T) {
+	expected := errors.New("mocked error")
+
+	t.Run("success", func(t *testing.T) {
+		g := NewCopyGroup(context.Background())
+		var buffs [3]bytes.Buffer
+		for idx, payload := range []string{"a", "bb", "ccc"} {
+			index := g.Go(NewLockedWriteCloser(NopWriteCloser(&buffs[idx])),
+				NopReadCloser(strings.NewReader(payload)))
+			assert.Equal(t, idx, index)
+		}
+		results, err := g.Wait()
+		require.NoError(t, err)
+		assert.Equal(t, []CopyResult{{Count: 1}, {Count: 2}, {Count: 3}}, results)
+		assert.Equal(t, "ccc", buffs[2].String())
+	})
+
+	t.Run("the first failure cancels the other copies", func(t *testing.T) {
+		g := NewCopyGroup(context.Background())
+		g.Go(NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{})), newBlockingReadCloser())
+		g.Go(NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{})), NopReadCloser(stdiotest.ErrReader(expected)))
+		results, err := g.Wait()
+		require.ErrorIs(t, err, expected)
+		require.Len(t, results, 2)
+		assert.ErrorIs(t, results[0].Err, context.Canceled)
+		assert.ErrorIs(t, results[0].Err, expected)
+		assert.ErrorIs(t, results[1].Err, expected)
+	})
+
+	t.Run("ContinueOnError", func(t *testing.T) {
+		g := NewCopyGroup(context.Background())
+		g.ContinueOnError = true
+		g.Go(NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{})), NopReadCloser(stdiotest.ErrReader(expected)))
+		g.Go(NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{})), NopReadCloser(strings.NewReader("abc")))
+		results, err := g.Wait()
+		require.ErrorIs(t, err, expected)
+		assert.ErrorIs(t, results[0].Err, expected)
+		assert.Equal(t, CopyResult{Count: 3}, results[1])
+	})
+}
@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// CopyPoolStats contains aggregate statistics about the copies of a [*CopyPool].
+type CopyPoolStats struct {
+	// Active is the number of copies currently running.
+	Active int64
+
+	// Completed is the number of copies finished successfully.
+	Completed int64
+
+	// Failed is the number of copies finished with an error.
+	Failed int64
+
+	// Bytes is the number of bytes copied by the finished copies.
+	Bytes int64
+}
+
+// CopyPool runs copies with bounded concurrency.
+//
+// Copies submitted when all the workers are busy wait for a worker to become
+// available, which provides admission control for servers proxying many streams.
+// Since copies use the shared buffer pool (see [SetBufferSize]), bounding the
+// number of workers also bounds the memory used for buffers.
+//
+// All methods are safe for concurrent use.
+//
+// Construct using [NewCopyPool].
+type CopyPool struct {
+	active    atomic.Int64
+	bytes     atomic.Int64
+	closed    chan struct{}
+	completed atomic.Int64
+	cs        closeState
+	failed    atomic.Int64
+	mu        sync.Mutex
+	sem       chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewCopyPool returns a new [*CopyPool] running at most workers copies at a time.
+//
+// Nonpositive values of workers mean a single worker.
+func NewCopyPool(workers int) *CopyPool {
+	return &CopyPool{
+		closed: make(chan struct{}),
+		sem:    make(chan struct{}, max(workers, 1)),
+	}
+}
+
+// Submit waits for a worker to become available and starts copying from src
+// into dst in the background using [StartCopy].
+//
+// The ownership rules for src and dst and the options are the same of [CopyContext].
+// In particular, Submit closes dst when it fails to start the copy.
+//
+// The returned error is nil, [ErrClosed] when the pool is closed, or caused by
+// the context when it is done before a worker becomes available.
+func (p *CopyPool) Submit(ctx context.Context, dst *LockedWriteCloser,
+	src io.ReadCloser, opts ...CopyOption) (*Copier, error) {
+	// 1. wait for a worker to become available
+	select {
+	case p.sem <- struct{}{}:
+	case <-p.closed:
+		dst.Close()
+		return nil, ErrClosed
+	case <-ctx.Done():
+		dst.Close()
+		return nil, contextError(ctx)
+	}
+
+	// 2. register the copy unless we have been closed in the meanwhile
+	p.mu.Lock()
+	if err := p.cs.check(); err != nil {
+		p.mu.Unlock()
+		<-p.sem
+		dst.Close()
+		return nil, err
+	}
+	p.wg.Add(1)
+	p.mu.Unlock()
+
+	// 3. start the copy and release the worker when done
+	p.active.Add(1)
+	return startCopy(ctx, dst, src, func(count int, err error) {
+		p.bytes.Add(int64(count))
+		if err != nil {
+			p.failed.Add(1)
+		} else {
+			p.completed.Add(1)
+		}
+		p.active.Add(-1)
+		<-p.sem
+		p.wg.Done()
+	}, opts...), nil
+}
+
+// Stats returns the aggregate statistics of the pool.
+func (p *CopyPool) Stats() CopyPoolStats {
+	return CopyPoolStats{
+		Active:    p.active.Load(),
+		Completed: p.completed.Load(),
+		Failed:    p.failed.Load(),
+		Bytes:     p.bytes.Load(),
+	}
+}
+
+// Close stops accepting new copies and waits for the running ones to finish.
+//
+// Use the context passed to Submit to interrupt the running copies.
+//
+// Returns nil or [ErrClosed] when already closed.
+func (p *CopyPool) Close() error {
+	p.mu.Lock()
+	err := p.cs.close(func() error {
+		close(p.closed)
+		return nil
+	})
+	p.mu.Unlock()
+	p.wg.Wait()
+	return err
+}
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	stdiotest "testing/iotest"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newGatedReadCloser returns a reader returning payload once gate is closed.
+func newGatedReadCloser(payload string, gate <-chan struct{}) io.ReadCloser {
+	r := strings.NewReader(payload)
+	return &iotest.FuncReadCloser{
+		ReadFunc: func(buf []byte) (int, error) {
+			<-gate
+			return r.Read(buf)
+		},
+		CloseFunc: func() error {
+			return nil
+		},
+	}
+}
+
+func TestCopyPool(t *testing.T) {
+	t.Run("bounds concurrency and collects statistics", func(t *testing.T) {
+		pool := NewCopyPool(2)
+		gate := make(chan struct{})
+		var copiers []*Copier
+		for range 2 {
+			c, err := pool.Submit(context.Background(),
+				NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{})), newGatedReadCloser("hello", gate))
+			require.NoError(t, err)
+			copiers = append(copiers, c)
+		}
+		assert.Equal(t, int64(2), pool.Stats().Active)
+
+		// the third copy must wait for a worker
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		closed := &atomic.Bool{}
+		_, err := pool.Submit(ctx, NewLockedWriteCloser(&iotest.FuncWriteCloser{
+			WriteFunc: func(data []byte) (int, error) {
+				return len(data), nil
+			},
+			CloseFunc: func() error {
+				closed.Store(true)
+				return nil
+			},
+		}), newGatedReadCloser("hello", gate))
+		require.ErrorIs(t, err, context.Canceled)
+		assert.True(t, closed.Load())
+
+		close(gate)
+		for _, c := range copiers {
+			count, err := c.Wait()
+			require.NoError(t, err)
+			assert.Equal(t, 5, count)
+		}
+
+		c, err := pool.Submit(context.Background(), NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{})),
+			NopReadCloser(stdiotest.ErrReader(errors.New("mocked error"))))
+		require.NoError(t, err)
+		_, err = c.Wait()
+		require.Error(t, err)
+
+		require.NoError(t, pool.Close())
+		assert.Equal(t, CopyPoolStats{Active: 0, Completed: 2, Failed: 1, Bytes: 10}, pool.Stats())
+	})
+
+	t.Run("Close waits for running copies and rejects new ones", func(t *testing.T) {
+		pool := NewCopyPool(0)
+		gate := make(chan struct{})
+		buff := &bytes.Buffer{}
+		_, err := pool.Submit(context.Background(),
+			NewLockedWriteCloser(NopWriteCloser(buff)), newGatedReadCloser("hello", gate))
+		require.NoError(t, err)
+
+		done := make(chan error)
+		go func() {
+			done <- pool.Close()
+		}()
+		close(gate)
+		require.NoError(t, <-done)
+		assert.Equal(t, "hello", buff.String())
+
+		_, err = pool.Submit(context.Background(),
+			NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{})), NopReadCloser(strings.NewReader("x")))
+		require.ErrorIs(t, err, ErrClosed)
+		require.ErrorIs(t, pool.Close(), ErrClosed)
+	})
+}
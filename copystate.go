@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// copyState is the state shared by [CopyContext] and its background goroutine.
+//
+// High-QPS proxies call [CopyContext] for each request, so we reuse the state,
+// including the configuration, the channels, and the goroutine's entry point,
+// using copyStatePool. Since [CopyContext] may return before the goroutine does,
+// both release the state once done, and the last one returns it to the pool.
+type copyState struct {
+	abandoned chan bool
+	cfg       copyConfig
+	errch     chan error
+	lwc       *LockedWriteCloser
+	op        opReader
+	ownership Ownership
+	raw       io.ReadCloser
+	rc        io.ReadCloser
+	refs      atomic.Int32
+	run       func()
+	src       io.Reader
+	writer    io.Writer
+}
+
+// copyStatePool contains the *copyState reused across copies.
+var copyStatePool sync.Pool
+
+// newCopyState returns a [*copyState], possibly from the pool, configured using opts.
+//
+// Both [CopyContext] and the goroutine running the copy MUST call release.
+func newCopyState(opts ...CopyOption) *copyState {
+	st, ok := copyStatePool.Get().(*copyState)
+	if !ok {
+		st = &copyState{abandoned: make(chan bool, 1), errch: make(chan error, 1)}
+		st.run = st.copy
+	}
+	st.cfg.init(opts...)
+	st.refs.Store(2)
+	return st
+}
+
+// copy copies from the reader into the writer and sends the result to errch.
+//
+// When abandoning the reader on cancellation (see [AbandonOnCancel]), it also
+// waits to know whether [CopyContext] was canceled, and then drains the reader.
+func (st *copyState) copy() {
+	defer st.release()
+	if st.src != nil {
+		if _, ok, err := st.lwc.lockedZeroCopy(st.src); ok {
+			st.errch <- classifyCopyError(err, st.lwc.Count())
+			return
+		}
+	}
+	bp := getBuffer()
+	_, err := io.CopyBuffer(st.writer, st.op.reset(st.rc), *bp)
+	putBuffer(bp)
+	if err == nil {
+		err = st.cfg.finish()
+	}
+	st.errch <- classifyCopyError(err, st.lwc.Count())
+	if st.ownership == AbandonOnCancel && <-st.abandoned {
+		drainAbandoned(st.raw)
+	}
+}
+
+// release releases st and, when both [CopyContext] and the goroutine running
+// the copy have released it, resets st and returns it to the pool.
+func (st *copyState) release() {
+	if st.refs.Add(-1) > 0 {
+		return
+	}
+	select {
+	case <-st.errch: // not received by a canceled [CopyContext]
+	default:
+	}
+	st.cfg = copyConfig{}
+	st.lwc, st.op, st.raw, st.rc, st.src, st.writer = nil, opReader{}, nil, nil, nil, nil
+	copyStatePool.Put(st)
+}
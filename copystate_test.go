@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyContextReusesState(t *testing.T) {
+	// Interleave successful and canceled copies, which release the pooled
+	// state in different orders, and make sure they never mix up their results.
+	var wg sync.WaitGroup
+	for idx := range 64 {
+		wg.Go(func() {
+			payload := fmt.Sprintf("copy #%d", idx)
+			if idx%2 == 0 {
+				var buf bytes.Buffer
+				count, err := CopyContext(context.Background(),
+					NewLockedWriteCloser(NopWriteCloser(&buf)), NopReadCloser(strings.NewReader(payload)))
+				assert.NoError(t, err)
+				assert.Equal(t, len(payload), count)
+				assert.Equal(t, payload, buf.String())
+				return
+			}
+			unblock := make(chan struct{})
+			rc := &iotest.FuncReadCloser{
+				ReadFunc: func(b []byte) (int, error) {
+					<-unblock
+					return 0, io.ErrClosedPipe
+				},
+				CloseFunc: func() error {
+					close(unblock)
+					return nil
+				},
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			count, err := CopyContext(ctx, NewLockedWriteCloser(NopWriteCloser(io.Discard)), rc)
+			assert.ErrorIs(t, err, context.Canceled)
+			assert.Equal(t, 0, count)
+		})
+	}
+	wg.Wait()
+}
+
+func TestCopyContextReportsReadErrors(t *testing.T) {
+	expected := io.ErrUnexpectedEOF
+	rc := &iotest.FuncReadCloser{
+		ReadFunc: func(b []byte) (int, error) {
+			return copy(b, "abc"), expected
+		},
+		CloseFunc: func() error { return nil },
+	}
+	count, err := CopyContext(context.Background(), NewLockedWriteCloser(NopWriteCloser(io.Discard)), rc)
+	require.ErrorIs(t, err, expected)
+	var ioErr *Error
+	require.ErrorAs(t, err, &ioErr)
+	assert.Equal(t, "read", ioErr.Op)
+	assert.Equal(t, int64(3), ioErr.Offset)
+	assert.Equal(t, 3, count)
+}
+
+func BenchmarkCopyContextSmall(b *testing.B) {
+	// This is the common case of proxies copying small bodies: the allocations
+	// are the ones of the caller, since CopyContext reuses its state.
+	reader := strings.NewReader("")
+	rc := NopReadCloser(reader)
+	b.ReportAllocs()
+	for b.Loop() {
+		reader.Reset("hello, world")
+		CopyContext(context.Background(), NewLockedWriteCloser(NopWriteCloser(io.Discard)), rc)
+	}
+}
+
+func BenchmarkCopyContextParallel(b *testing.B) {
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		reader := strings.NewReader("")
+		rc := NopReadCloser(reader)
+		for pb.Next() {
+			reader.Reset("hello, world")
+			CopyContext(context.Background(), NewLockedWriteCloser(NopWriteCloser(io.Discard)), rc)
+		}
+	})
+}
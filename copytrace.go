@@ -0,0 +1,211 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// CopyTrace is a set of hooks observing a copy, similar to [net/http/httptrace.ClientTrace].
+//
+// Any hook may be nil. The hooks are never invoked concurrently and no hook is
+// invoked after Done. All hooks but Done are invoked from the goroutine performing
+// the copy, while Done is invoked from the goroutine calling [CopyContext] right
+// before it returns.
+//
+// Attach a CopyTrace to a copy using [ContextWithCopyTrace] or [WithCopyTrace].
+type CopyTrace struct {
+	// GotFirstByte is called when the copy reads the first bytes.
+	GotFirstByte func()
+
+	// WroteChunk is called after each write with the number of bytes written.
+	WroteChunk func(count int)
+
+	// ReadError is called when reading fails with an error other than [io.EOF].
+	ReadError func(err error)
+
+	// Done is called once with the number of bytes copied and the final error.
+	Done func(count int, err error)
+}
+
+// copyTraceKey is the context key for the [*CopyTrace].
+type copyTraceKey struct{}
+
+// ContextWithCopyTrace returns a new context based on ctx carrying the given [*CopyTrace].
+//
+// Copies using the returned context, such as [CopyContext], invoke the hooks.
+// If ctx already carries a [*CopyTrace], the returned context carries both,
+// and the hooks of trace are invoked before the ones already present.
+func ContextWithCopyTrace(ctx context.Context, trace *CopyTrace) context.Context {
+	return context.WithValue(ctx, copyTraceKey{}, trace.compose(ContextCopyTrace(ctx)))
+}
+
+// ContextCopyTrace returns the [*CopyTrace] carried by ctx, or nil.
+func ContextCopyTrace(ctx context.Context) *CopyTrace {
+	trace, _ := ctx.Value(copyTraceKey{}).(*CopyTrace)
+	return trace
+}
+
+// WithCopyTrace causes the copy to invoke the hooks of the given [*CopyTrace].
+//
+// It composes with [ContextWithCopyTrace] and with other invocations of this
+// option. Tracing disables the zero-copy fast path of [CopyContext].
+func WithCopyTrace(trace *CopyTrace) CopyOption {
+	return func(cfg *copyConfig) {
+		cfg.trace = trace.compose(cfg.trace)
+	}
+}
+
+// compose returns a [*CopyTrace] invoking the hooks of t and then the hooks of old.
+//
+// Either of them may be nil, in which case it returns the other one.
+func (t *CopyTrace) compose(old *CopyTrace) *CopyTrace {
+	switch {
+	case t == nil:
+		return old
+	case old == nil:
+		return t
+	}
+	return &CopyTrace{
+		GotFirstByte: func() {
+			callHook0(t.GotFirstByte)
+			callHook0(old.GotFirstByte)
+		},
+		WroteChunk: func(count int) {
+			callHook1(t.WroteChunk, count)
+			callHook1(old.WroteChunk, count)
+		},
+		ReadError: func(err error) {
+			callHook1(t.ReadError, err)
+			callHook1(old.ReadError, err)
+		},
+		Done: func(count int, err error) {
+			callHook2(t.Done, count, err)
+			callHook2(old.Done, count, err)
+		},
+	}
+}
+
+// callHook0 invokes fn when not nil.
+func callHook0(fn func()) {
+	if fn != nil {
+		fn()
+	}
+}
+
+// callHook1 invokes fn with the given argument when not nil.
+func callHook1[T any](fn func(T), arg T) {
+	if fn != nil {
+		fn(arg)
+	}
+}
+
+// callHook2 invokes fn with the given arguments when not nil.
+func callHook2[T, U any](fn func(T, U), arg1 T, arg2 U) {
+	if fn != nil {
+		fn(arg1, arg2)
+	}
+}
+
+// startTrace returns the [*copyTracer] invoking the hooks attached to ctx, the
+// ones configured by [WithCopyTrace], the [Span] started by [WithTracer], and
+// the meter configured by [WithBandwidthSummary].
+//
+// It returns nil when there are no hooks to invoke.
+func (cfg *copyConfig) startTrace(ctx context.Context) *copyTracer {
+	trace := cfg.trace.compose(ContextCopyTrace(ctx))
+	if len(cfg.bandwidth) > 0 {
+		trace = trace.compose(newBandwidthMeter(cfg.bandwidth).trace())
+	}
+	if cfg.tracer != nil {
+		span := cfg.tracer.StartSpan(ctx, cfg.spanName)
+		trace = trace.compose(&CopyTrace{GotFirstByte: span.RecordFirstByte, Done: span.End})
+	}
+	if trace == nil {
+		return nil
+	}
+	return &copyTracer{trace: trace}
+}
+
+// copyTracer serializes the invocation of the hooks of a [*CopyTrace], which may
+// otherwise race because the copy continues in the background after cancellation.
+//
+// All methods tolerate a nil receiver, meaning there is nothing to trace.
+type copyTracer struct {
+	done      bool
+	firstByte bool
+	mu        sync.Mutex
+	trace     *CopyTrace
+}
+
+// wrap wraps rc and w such that reading and writing invoke the hooks.
+func (ct *copyTracer) wrap(rc io.ReadCloser, w io.Writer) (io.ReadCloser, io.Writer) {
+	if ct == nil {
+		return rc, w
+	}
+	return readCloser{tracingReader{rc, ct}, rc}, tracingWriter{w, ct}
+}
+
+// read invokes the hooks interested in the result of a read.
+func (ct *copyTracer) read(count int, err error) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	if ct.done {
+		return
+	}
+	if count > 0 && !ct.firstByte {
+		ct.firstByte = true
+		callHook0(ct.trace.GotFirstByte)
+	}
+	if err != nil && err != io.EOF {
+		callHook1(ct.trace.ReadError, err)
+	}
+}
+
+// wrote invokes the hooks interested in the result of a write.
+func (ct *copyTracer) wrote(count int) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	if !ct.done && count > 0 {
+		callHook1(ct.trace.WroteChunk, count)
+	}
+}
+
+// end invokes the Done hook.
+func (ct *copyTracer) end(count int, err error) {
+	if ct == nil {
+		return
+	}
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.done = true
+	callHook2(ct.trace.Done, count, err)
+}
+
+// tracingReader is the [io.Reader] returned by [*copyTracer.wrap].
+type tracingReader struct {
+	r  io.Reader
+	ct *copyTracer
+}
+
+// Read implements [io.Reader].
+func (r tracingReader) Read(buf []byte) (int, error) {
+	count, err := r.r.Read(buf)
+	r.ct.read(count, err)
+	return count, err
+}
+
+// tracingWriter is the [io.Writer] returned by [*copyTracer.wrap].
+type tracingWriter struct {
+	w  io.Writer
+	ct *copyTracer
+}
+
+// Write implements [io.Writer].
+func (w tracingWriter) Write(data []byte) (int, error) {
+	count, err := w.w.Write(data)
+	w.ct.wrote(count)
+	return count, err
+}
@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newEventsTrace returns a [*CopyTrace] appending the events it observes to events.
+func newEventsTrace(prefix string, events *[]string) *CopyTrace {
+	return &CopyTrace{
+		GotFirstByte: func() {
+			*events = append(*events, prefix+"first")
+		},
+		WroteChunk: func(count int) {
+			*events = append(*events, fmt.Sprintf("%swrote %d", prefix, count))
+		},
+		ReadError: func(err error) {
+			*events = append(*events, fmt.Sprintf("%sread error %s", prefix, err))
+		},
+		Done: func(count int, err error) {
+			*events = append(*events, fmt.Sprintf("%sdone %d %v", prefix, count, err))
+		},
+	}
+}
+
+func TestCopyContextWithCopyTrace(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		var events []string
+		buff := &bytes.Buffer{}
+		lwc := NewLockedWriteCloser(NopWriteCloser(buff))
+		_, err := CopyContext(context.Background(), lwc,
+			newSmallReadsReadCloser("abcdefgh", 4), WithCopyTrace(newEventsTrace("", &events)))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"first", "wrote 4", "wrote 4", "done 8 <nil>"}, events)
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		var events []string
+		expected := errors.New("mocked error")
+		rc := &iotest.FuncReadCloser{
+			ReadFunc: func(buf []byte) (int, error) {
+				return 0, expected
+			},
+			CloseFunc: func() error {
+				return nil
+			},
+		}
+		lwc := NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{}))
+		_, err := CopyContext(context.Background(), lwc, rc, WithCopyTrace(newEventsTrace("", &events)))
+		assert.ErrorIs(t, err, expected)
+		assert.Equal(t, []string{"read error mocked error", "done 0 read at offset 0: mocked error"}, events)
+	})
+
+	t.Run("no hooks after Done", func(t *testing.T) {
+		var events []string
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		unblock := make(chan struct{})
+		finished := make(chan struct{})
+		rc := &iotest.FuncReadCloser{
+			ReadFunc: func(buf []byte) (int, error) {
+				<-unblock
+				defer close(finished)
+				return copy(buf, "late"), nil
+			},
+			CloseFunc: func() error {
+				close(unblock)
+				return nil
+			},
+		}
+		lwc := NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{}))
+		_, err := CopyContext(ctx, lwc, rc, WithCopyTrace(newEventsTrace("", &events)))
+		assert.ErrorIs(t, err, context.Canceled)
+		<-finished
+		assert.Equal(t, []string{"done 0 context canceled"}, events)
+	})
+}
+
+func TestContextWithCopyTrace(t *testing.T) {
+	assert.Nil(t, ContextCopyTrace(context.Background()))
+
+	var events []string
+	ctx := ContextWithCopyTrace(context.Background(), newEventsTrace("outer ", &events))
+	ctx = ContextWithCopyTrace(ctx, newEventsTrace("inner ", &events))
+	ctx = ContextWithCopyTrace(ctx, &CopyTrace{})
+	require.NotNil(t, ContextCopyTrace(ctx))
+
+	lwc := NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{}))
+	_, err := CopyContext(ctx, lwc, newSmallReadsReadCloser("ab", 2),
+		WithCopyTrace(newEventsTrace("option ", &events)))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"option first", "inner first", "outer first",
+		"option wrote 2", "inner wrote 2", "outer wrote 2",
+		"option done 2 <nil>", "inner done 2 <nil>", "outer done 2 <nil>",
+	}, events)
+}
@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// CountingReadCloser is an [io.ReadCloser] wrapper counting bytes and reads.
+//
+// It is the read-side counterpart of the counting performed by [*LockedWriteCloser]
+// and is useful to measure, e.g., request bodies handed to an [*net/http.Client].
+//
+// Read and Close are forwarded to the underlying reader without locking. Count,
+// Reads, and Stats are safe to call concurrently with Read.
+//
+// Construct using [NewCountingReadCloser].
+type CountingReadCloser struct {
+	// RecordSizes enables recording the histogram of the per-read
+	// sizes returned by [*CountingReadCloser.Stats].
+	//
+	// [NewCountingReadCloser] sets it to false.
+	//
+	// You MUST NOT modify it after the first Read.
+	RecordSizes bool
+
+	nbytes atomic.Int64
+	nreads atomic.Int64
+	rc     io.ReadCloser
+	sizes  atomic.Pointer[sizeCounters]
+}
+
+// NewCountingReadCloser wraps an [io.ReadCloser] and returns a counting wrapper.
+func NewCountingReadCloser(rc io.ReadCloser) *CountingReadCloser {
+	return &CountingReadCloser{rc: rc}
+}
+
+// Read implements [io.Reader].
+func (r *CountingReadCloser) Read(buf []byte) (int, error) {
+	count, err := r.rc.Read(buf)
+	r.nreads.Add(1)
+	r.nbytes.Add(int64(count))
+	if r.RecordSizes {
+		sizes := r.sizes.Load()
+		if sizes == nil {
+			sizes = &sizeCounters{}
+			r.sizes.Store(sizes)
+		}
+		sizes.observe(count)
+	}
+	return count, err
+}
+
+// Close implements [io.Closer].
+func (r *CountingReadCloser) Close() error {
+	return r.rc.Close()
+}
+
+// Count returns the number of bytes read so far.
+func (r *CountingReadCloser) Count() int64 {
+	return r.nbytes.Load()
+}
+
+// Reads returns the number of Read calls so far.
+func (r *CountingReadCloser) Reads() int64 {
+	return r.nreads.Load()
+}
+
+// Stats returns statistics about the reads so far.
+//
+// The histogram of the per-read sizes is only available when RecordSizes is true.
+func (r *CountingReadCloser) Stats() IOStats {
+	return IOStats{Bytes: r.nbytes.Load(), Calls: r.nreads.Load(), Sizes: r.sizes.Load().snapshot()}
+}
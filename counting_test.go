@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountingReadCloser(t *testing.T) {
+	// Wrap a reader returning at most two bytes per read.
+	const payload = "hello"
+	sr := strings.NewReader(payload)
+	closed := &atomic.Bool{}
+	crc := NewCountingReadCloser(&iotest.FuncReadCloser{
+		ReadFunc: func(b []byte) (int, error) {
+			return sr.Read(b[:min(len(b), 2)])
+		},
+		CloseFunc: func() error {
+			closed.Store(true)
+			return nil
+		},
+	})
+
+	data, err := io.ReadAll(crc)
+	require.NoError(t, err)
+	assert.Equal(t, payload, string(data))
+	assert.Equal(t, int64(len(payload)), crc.Count())
+
+	// Three reads returning data plus the one returning EOF.
+	assert.Equal(t, int64(4), crc.Reads())
+
+	require.NoError(t, crc.Close())
+	assert.True(t, closed.Load())
+}
+
+func TestCountingReadCloserStats(t *testing.T) {
+	t.Run("without recording sizes", func(t *testing.T) {
+		crc := NewCountingReadCloser(NopReadCloser(strings.NewReader("hello")))
+		_, err := io.ReadAll(crc)
+		require.NoError(t, err)
+		stats := crc.Stats()
+		assert.Equal(t, int64(5), stats.Bytes)
+		assert.Equal(t, crc.Reads(), stats.Calls)
+		assert.Nil(t, stats.Sizes)
+	})
+
+	t.Run("recording sizes", func(t *testing.T) {
+		crc := NewCountingReadCloser(NopReadCloser(strings.NewReader(strings.Repeat("x", 1000))))
+		crc.RecordSizes = true
+		buf := make([]byte, 512)
+		for {
+			if _, err := crc.Read(buf); err != nil {
+				break
+			}
+		}
+		stats := crc.Stats()
+		assert.Equal(t, int64(1000), stats.Bytes)
+		assert.Equal(t, int64(3), stats.Calls)
+		require.NotNil(t, stats.Sizes)
+		assert.Equal(t, []SizeBucket{
+			{Min: 0, Max: 0, Count: 1},
+			{Min: 256, Max: 511, Count: 1},
+			{Min: 512, Max: 1023, Count: 1},
+		}, stats.Sizes.Buckets())
+	})
+}
@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+// DebugMode controls how wrappers report misuse. See [SetDebug].
+type DebugMode int32
+
+const (
+	// DebugOff disables reporting misuse. This is the default.
+	DebugOff = DebugMode(iota)
+
+	// DebugLog logs misuse using the [log] package.
+	DebugLog
+
+	// DebugPanic panics on misuse.
+	DebugPanic
+)
+
+// debugMode is the current [DebugMode].
+var debugMode atomic.Int32
+
+func init() {
+	switch os.Getenv("IOX_DEBUG") {
+	case "log":
+		SetDebug(DebugLog)
+	case "1", "panic":
+		SetDebug(DebugPanic)
+	}
+}
+
+// SetDebug sets the [DebugMode], which is initially [DebugOff] unless the
+// IOX_DEBUG environment variable is "log" ([DebugLog]) or "1" or "panic"
+// ([DebugPanic]).
+//
+// When debugging, wrappers tracking whether they are closed record the stack of
+// the first Close call and report it, along with the current stack, when they are
+// used after close. They also report closing them again, unless their Close is
+// documented as idempotent, like the one of [*LockedWriteCloser], [*LockedWriterAt],
+// and [CloseOnce]. Hence, closing the writer passed to [CopyContext], which closes
+// it, is not reported, and neither are the writes attempted by the background
+// goroutine of a canceled copy.
+//
+// Since recording the stack is expensive, enable debugging only during development.
+func SetDebug(mode DebugMode) {
+	debugMode.Store(int32(mode))
+}
+
+// debugEnabled returns whether we should record stacks and report misuse.
+func debugEnabled() bool {
+	return DebugMode(debugMode.Load()) != DebugOff
+}
+
+// debugReport reports the given misuse according to the current [DebugMode].
+func debugReport(misuse string, closeStack []byte) {
+	if closeStack == nil {
+		closeStack = []byte("(unavailable: closed before enabling debugging)\n")
+	}
+	msg := fmt.Sprintf("iox: %s\n\nclosed at:\n%s\ndetected at:\n%s", misuse, closeStack, debug.Stack())
+	switch DebugMode(debugMode.Load()) {
+	case DebugLog:
+		log.Print(msg)
+	case DebugPanic:
+		panic(msg)
+	}
+}
@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// enableDebug sets the given [DebugMode] for the duration of the test.
+func enableDebug(t *testing.T, mode DebugMode) {
+	SetDebug(mode)
+	t.Cleanup(func() {
+		SetDebug(DebugOff)
+	})
+}
+
+// closeFromHelper closes c from a function we can recognize in stacks.
+func closeFromHelper(c interface{ Close() error }) error {
+	return c.Close()
+}
+
+// panicMessage returns the string fn panics with, or an empty string.
+func panicMessage(fn func()) (msg string) {
+	defer func() {
+		msg, _ = recover().(string)
+	}()
+	fn()
+	return
+}
+
+func TestDebugPanic(t *testing.T) {
+	enableDebug(t, DebugPanic)
+
+	t.Run("use after close", func(t *testing.T) {
+		lwc := NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{}))
+		require.NoError(t, closeFromHelper(lwc))
+		msg := panicMessage(func() {
+			lwc.LockedWrite([]byte("abc"))
+		})
+		assert.Contains(t, msg, "iox: use after close")
+		assert.Contains(t, msg, "closeFromHelper")
+	})
+
+	t.Run("double close", func(t *testing.T) {
+		wc, err := CompressWriteCloser(NopWriteCloser(&bytes.Buffer{}), "identity")
+		require.NoError(t, err)
+		require.NoError(t, closeFromHelper(wc))
+		msg := panicMessage(func() {
+			wc.Close()
+		})
+		assert.Contains(t, msg, "iox: double close")
+		assert.Contains(t, msg, "closeFromHelper")
+	})
+
+	t.Run("idempotent closers are not reported", func(t *testing.T) {
+		file, err := os.CreateTemp(t.TempDir(), "iox")
+		require.NoError(t, err)
+		closers := map[string]io.Closer{
+			"CloseOnce":         CloseOnce(NopReadCloser(nil)),
+			"LockedWriteCloser": NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{})),
+			"LockedWriterAt":    NewLockedWriterAt(file),
+		}
+		for name, c := range closers {
+			require.NoError(t, c.Close(), name)
+			assert.NotPanics(t, func() {
+				assert.ErrorIs(t, c.Close(), ErrClosed, name)
+			}, name)
+		}
+	})
+
+	t.Run("closing the writer after CopyContext is not reported", func(t *testing.T) {
+		lwc := NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{}))
+		assert.NotPanics(t, func() {
+			defer lwc.Close()
+			_, err := CopyContext(context.Background(), lwc, NopReadCloser(strings.NewReader("iox")))
+			assert.NoError(t, err)
+		})
+	})
+
+	t.Run("canceled copies are not reported", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		lwc := NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{}))
+		assert.NotPanics(t, func() {
+			_, err := CopyContext(ctx, lwc, NopReadCloser(ZeroReader()))
+			assert.ErrorIs(t, err, context.Canceled)
+		})
+	})
+}
+
+func TestDebugLog(t *testing.T) {
+	enableDebug(t, DebugLog)
+	logs := &bytes.Buffer{}
+	log.SetOutput(logs)
+	defer log.SetOutput(os.Stderr)
+
+	lwc := NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{}))
+	require.NoError(t, lwc.Close())
+	_, err := lwc.LockedWrite([]byte("abc"))
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.Contains(t, logs.String(), "iox: use after close")
+}
+
+func TestDebugOff(t *testing.T) {
+	lwc := NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{}))
+	require.NoError(t, lwc.Close())
+	assert.NotPanics(t, func() {
+		assert.ErrorIs(t, lwc.Close(), ErrClosed)
+	})
+}
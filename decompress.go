@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"math"
+	"sync"
+)
+
+var (
+	// ErrDecompressedTooLarge is returned when decompressed data exceeds the maximum size.
+	ErrDecompressedTooLarge = errors.New("decompressed data too large")
+
+	// ErrUnsupportedEncoding is returned when there is no decompressor, compressor,
+	// or text codec (see [EncodeReadCloser]) for an encoding.
+	ErrUnsupportedEncoding = errors.New("unsupported content encoding")
+)
+
+// DecompressorFunc returns an [io.ReadCloser] decompressing the data read from r.
+type DecompressorFunc func(r io.Reader) (io.ReadCloser, error)
+
+var (
+	// decompressorsMu protects decompressors.
+	decompressorsMu sync.RWMutex
+
+	// decompressors maps normalized encoding names to decompressors.
+	decompressors = map[string]DecompressorFunc{
+		"":         newIdentityDecompressor,
+		"deflate":  zlib.NewReader,
+		"gzip":     newGzipDecompressor,
+		"identity": newIdentityDecompressor,
+		"x-gzip":   newGzipDecompressor,
+	}
+)
+
+// newGzipDecompressor is the [DecompressorFunc] for gzip.
+func newGzipDecompressor(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// newIdentityDecompressor is the [DecompressorFunc] for uncompressed data.
+func newIdentityDecompressor(r io.Reader) (io.ReadCloser, error) {
+	return NopReadCloser(r), nil
+}
+
+// RegisterDecompressor registers the [DecompressorFunc] for the given encoding.
+//
+// Use this function to add support for encodings outside of the standard
+// library (e.g., "zstd" or "br") or to override the builtin ones. The
+// encoding name is case insensitive and leading and trailing whitespace
+// is ignored, like for [DecompressReadCloser].
+func RegisterDecompressor(encoding string, fn DecompressorFunc) {
+	decompressorsMu.Lock()
+	decompressors[normalizeEncoding(encoding)] = fn
+	decompressorsMu.Unlock()
+}
+
+// DecompressReadCloser returns an [io.ReadCloser] decompressing the data read
+// from rc according to the given encoding, such as the value of an HTTP
+// Content-Encoding header.
+//
+// We support "gzip", "deflate" (i.e., zlib as specified by RFC 9110), and "identity"
+// out of the box. Use [RegisterDecompressor] to support more encodings.
+//
+// To protect against decompression bombs, reads fail with [ErrDecompressedTooLarge]
+// once the decompressed data exceeds maxSize bytes. This makes it safe to pass the
+// returned reader to [ReadAllContext] when handling untrusted input. Like for
+// [ReadAllLimitContext], nonpositive values of maxSize disable the check, which is
+// only safe with trusted input.
+//
+// Close closes both the decompressor and rc. On failure, rc is NOT closed.
+//
+// The returned error is nil, [ErrUnsupportedEncoding], or the error returned
+// when creating the decompressor (e.g., because of an invalid gzip header).
+func DecompressReadCloser(rc io.ReadCloser, encoding string, maxSize int64) (io.ReadCloser, error) {
+	decompressorsMu.RLock()
+	fn, found := decompressors[normalizeEncoding(encoding)]
+	decompressorsMu.RUnlock()
+	if !found {
+		return nil, ErrUnsupportedEncoding
+	}
+	dec, err := fn(rc)
+	if err != nil {
+		return nil, err
+	}
+	if maxSize <= 0 {
+		maxSize = math.MaxInt64
+	}
+	return &decompressReadCloser{dec: dec, rc: rc, remaining: maxSize}, nil
+}
+
+// decompressReadCloser is the [io.ReadCloser] returned by [DecompressReadCloser].
+type decompressReadCloser struct {
+	dec       io.ReadCloser
+	rc        io.ReadCloser
+	remaining int64
+}
+
+// Read implements [io.Reader].
+func (r *decompressReadCloser) Read(buf []byte) (int, error) {
+	if len(buf) <= 0 {
+		return 0, nil
+	}
+	if r.remaining <= 0 {
+		more, err := probeMore(r.dec)
+		if more {
+			return 0, ErrDecompressedTooLarge
+		}
+		return 0, err
+	}
+	count, err := r.dec.Read(buf[:min(int64(len(buf)), r.remaining)])
+	r.remaining -= int64(count)
+	return count, err
+}
+
+// Close implements [io.Closer].
+func (r *decompressReadCloser) Close() error {
+	return errors.Join(r.dec.Close(), r.rc.Close())
+}
@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// compressString compresses s using the given writer constructor.
+func compressString(t *testing.T, s string, newWriter func(io.Writer) io.WriteCloser) []byte {
+	buff := &bytes.Buffer{}
+	w := newWriter(buff)
+	_, err := io.WriteString(w, s)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buff.Bytes()
+}
+
+func TestDecompressReadCloser(t *testing.T) {
+	const payload = "hello, compressed world"
+	gzipData := compressString(t, payload, func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) })
+	zlibData := compressString(t, payload, func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) })
+
+	cases := []struct {
+		encoding string
+		data     []byte
+	}{
+		{"gzip", gzipData},
+		{"X-GZIP", gzipData},
+		{"deflate", zlibData},
+		{"identity", []byte(payload)},
+		{"", []byte(payload)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.encoding, func(t *testing.T) {
+			closed := &atomic.Bool{}
+			rc := JoinReadCloser(bytes.NewReader(tc.data), CloserFunc(func() error {
+				closed.Store(true)
+				return nil
+			}))
+			drc, err := DecompressReadCloser(rc, tc.encoding, 1024)
+			require.NoError(t, err)
+
+			data, err := ReadAllContext(context.Background(), drc)
+			require.NoError(t, err)
+			assert.Equal(t, payload, string(data))
+
+			// Close must close the underlying reader.
+			require.NoError(t, drc.Close())
+			assert.True(t, closed.Load())
+		})
+	}
+}
+
+func TestDecompressReadCloserWithBomb(t *testing.T) {
+	// Compress a large amount of zeros into a small payload.
+	payload := strings.Repeat("\x00", 1<<20)
+	data := compressString(t, payload, func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) })
+	assert.Less(t, len(data), 4096)
+
+	drc, err := DecompressReadCloser(NopReadCloser(bytes.NewReader(data)), "gzip", 1<<16)
+	require.NoError(t, err)
+	defer drc.Close()
+
+	out, err := ReadAllContext(context.Background(), drc)
+	require.ErrorIs(t, err, ErrDecompressedTooLarge)
+	assert.Len(t, out, 1<<16)
+}
+
+func TestDecompressReadCloserWithExactMaxSize(t *testing.T) {
+	drc, err := DecompressReadCloser(NopReadCloser(strings.NewReader("iox")), "identity", 3)
+	require.NoError(t, err)
+	data, err := io.ReadAll(drc)
+	require.NoError(t, err)
+	assert.Equal(t, "iox", string(data))
+}
+
+func TestDecompressReadCloserWithNonpositiveMaxSize(t *testing.T) {
+	// Like for ReadAllLimitContext, nonpositive values disable the check.
+	for _, maxSize := range []int64{0, -1} {
+		drc, err := DecompressReadCloser(NopReadCloser(strings.NewReader("iox")), "identity", maxSize)
+		require.NoError(t, err)
+		data, err := io.ReadAll(drc)
+		require.NoError(t, err)
+		assert.Equal(t, "iox", string(data))
+	}
+}
+
+func TestDecompressReadCloserErrors(t *testing.T) {
+	t.Run("with an unsupported encoding", func(t *testing.T) {
+		_, err := DecompressReadCloser(NopReadCloser(strings.NewReader("")), "compress", 1024)
+		require.ErrorIs(t, err, ErrUnsupportedEncoding)
+	})
+
+	t.Run("with an invalid gzip header", func(t *testing.T) {
+		_, err := DecompressReadCloser(NopReadCloser(strings.NewReader("not a gzip stream")), "gzip", 1024)
+		require.ErrorIs(t, err, gzip.ErrHeader)
+	})
+}
+
+func TestRegisterDecompressor(t *testing.T) {
+	// Register a fake decompressor converting to uppercase.
+	RegisterDecompressor("X-Upper", func(r io.Reader) (io.ReadCloser, error) {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return NopReadCloser(strings.NewReader(strings.ToUpper(string(data)))), nil
+	})
+
+	drc, err := DecompressReadCloser(NopReadCloser(strings.NewReader("iox")), "x-upper", 1024)
+	require.NoError(t, err)
+	data, err := io.ReadAll(drc)
+	require.NoError(t, err)
+	assert.Equal(t, "IOX", string(data))
+}
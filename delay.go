@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// DelayedReadCloser is an [io.ReadCloser] injecting latency before each read.
+//
+// Each read waits for the configured delay plus a random jitter uniformly
+// distributed in [0, jitter) before reading, which is useful to simulate slow
+// networks in tests. Waiting honors the context passed to the constructor and
+// Close, which may be called concurrently with Read to interrupt it.
+//
+// Construct using [NewDelayedReadCloser].
+type DelayedReadCloser struct {
+	closed closeSignal
+	ctx    context.Context
+	delay  time.Duration
+	jitter time.Duration
+	mu     sync.Mutex
+	rc     io.ReadCloser
+}
+
+// NewDelayedReadCloser wraps rc and returns a [*DelayedReadCloser].
+func NewDelayedReadCloser(ctx context.Context, rc io.ReadCloser, delay, jitter time.Duration) *DelayedReadCloser {
+	return &DelayedReadCloser{closed: newCloseSignal(), ctx: ctx, delay: delay, jitter: jitter, rc: rc}
+}
+
+// Read implements [io.Reader].
+//
+// The returned error is [ErrClosed] when closed, the context error, or the one
+// returned by the underlying reader.
+func (r *DelayedReadCloser) Read(buf []byte) (int, error) {
+	if err := r.closed.sleep(r.ctx, jitterDelay(r.delay, r.jitter)); err != nil {
+		return 0, err
+	}
+	return r.rc.Read(buf)
+}
+
+// Close interrupts any in-flight Read and closes the underlying reader.
+func (r *DelayedReadCloser) Close() error {
+	r.mu.Lock()
+	r.closed.close()
+	r.mu.Unlock()
+	return r.rc.Close()
+}
+
+// DelayedWriteCloser is an [io.WriteCloser] injecting latency before each write.
+//
+// Each write waits for the configured delay plus a random jitter uniformly
+// distributed in [0, jitter) before writing, which is useful to simulate slow
+// networks in tests. Waiting honors the context passed to the constructor and
+// Close, which may be called concurrently with Write to interrupt it.
+//
+// Construct using [NewDelayedWriteCloser].
+type DelayedWriteCloser struct {
+	closed closeSignal
+	ctx    context.Context
+	delay  time.Duration
+	jitter time.Duration
+	mu     sync.Mutex
+	w      io.WriteCloser
+}
+
+// NewDelayedWriteCloser wraps w and returns a [*DelayedWriteCloser].
+func NewDelayedWriteCloser(ctx context.Context, w io.WriteCloser, delay, jitter time.Duration) *DelayedWriteCloser {
+	return &DelayedWriteCloser{closed: newCloseSignal(), ctx: ctx, delay: delay, jitter: jitter, w: w}
+}
+
+// Write implements [io.Writer].
+//
+// The returned error is [ErrClosed] when closed, the context error, or the one
+// returned by the underlying writer.
+func (w *DelayedWriteCloser) Write(data []byte) (int, error) {
+	if err := w.closed.sleep(w.ctx, jitterDelay(w.delay, w.jitter)); err != nil {
+		return 0, err
+	}
+	return w.w.Write(data)
+}
+
+// Close interrupts any in-flight Write and closes the underlying writer.
+func (w *DelayedWriteCloser) Close() error {
+	w.mu.Lock()
+	w.closed.close()
+	w.mu.Unlock()
+	return w.w.Close()
+}
+
+// jitterDelay returns delay plus a random jitter uniformly distributed in [0, jitter).
+func jitterDelay(delay, jitter time.Duration) time.Duration {
+	if jitter > 0 {
+		delay += rand.N(jitter)
+	}
+	return delay
+}
@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDelayedReadCloser(t *testing.T) {
+	t.Run("on success", func(t *testing.T) {
+		rc := NewDelayedReadCloser(context.Background(),
+			NopReadCloser(strings.NewReader("iox")), 5*time.Millisecond, time.Millisecond)
+
+		t0 := time.Now()
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		assert.Equal(t, "iox", string(data))
+
+		// We need at least two reads, one for the data and one for EOF.
+		assert.GreaterOrEqual(t, time.Since(t0), 10*time.Millisecond)
+		require.NoError(t, rc.Close())
+	})
+
+	t.Run("with a cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		rc := NewDelayedReadCloser(ctx, NopReadCloser(strings.NewReader("iox")), time.Hour, 0)
+
+		count, err := rc.Read(make([]byte, 4))
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("Close interrupts a pending Read", func(t *testing.T) {
+		rc := NewDelayedReadCloser(context.Background(), NopReadCloser(strings.NewReader("iox")), time.Hour, 0)
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			rc.Close()
+		}()
+		t0 := time.Now()
+		count, err := rc.Read(make([]byte, 4))
+		require.ErrorIs(t, err, ErrClosed)
+		assert.Equal(t, 0, count)
+		assert.Less(t, time.Since(t0), time.Second)
+	})
+}
+
+func TestDelayedWriteCloser(t *testing.T) {
+	t.Run("on success", func(t *testing.T) {
+		buff := &bytes.Buffer{}
+		wc := NewDelayedWriteCloser(context.Background(), NopWriteCloser(buff), 5*time.Millisecond, 0)
+
+		t0 := time.Now()
+		count, err := wc.Write([]byte("iox"))
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.GreaterOrEqual(t, time.Since(t0), 5*time.Millisecond)
+		assert.Equal(t, "iox", buff.String())
+		require.NoError(t, wc.Close())
+	})
+
+	t.Run("with an expiring context", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		buff := &bytes.Buffer{}
+		wc := NewDelayedWriteCloser(ctx, NopWriteCloser(buff), time.Hour, time.Hour)
+
+		count, err := wc.Write([]byte("iox"))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, 0, count)
+		assert.Equal(t, 0, buff.Len())
+	})
+
+	t.Run("Close interrupts a pending Write", func(t *testing.T) {
+		buff := &bytes.Buffer{}
+		wc := NewDelayedWriteCloser(context.Background(), NopWriteCloser(buff), time.Hour, 0)
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			wc.Close()
+		}()
+		t0 := time.Now()
+		count, err := wc.Write([]byte("iox"))
+		require.ErrorIs(t, err, ErrClosed)
+		assert.Equal(t, 0, count)
+		assert.Less(t, time.Since(t0), time.Second)
+		assert.Equal(t, 0, buff.Len())
+	})
+}
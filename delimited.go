@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+)
+
+// WriteDelimited writes msg prefixed by its length encoded as a uvarint.
+//
+// This is the delimited stream convention used to stream protobuf messages
+// (e.g., by Java's writeDelimitedTo). It writes the message using a single
+// [*LockedWriteCloser.LockedWriteBuffers] call, therefore messages written by
+// concurrent goroutines are never interleaved.
+//
+// The returned error is the one returned by the [*LockedWriteCloser].
+func WriteDelimited(lwc *LockedWriteCloser, msg []byte) error {
+	var header [binary.MaxVarintLen64]byte
+	size := binary.PutUvarint(header[:], uint64(len(msg)))
+	_, err := lwc.LockedWriteBuffers(net.Buffers{header[:size], msg})
+	return err
+}
+
+// ReadDelimitedContext reads a message written by [WriteDelimited].
+//
+// To avoid reading past the end of the message, it reads the uvarint length one
+// byte at a time, so consider wrapping rc using [NewByteReadCloser] when reading
+// many messages from an unbuffered stream.
+//
+// Reads are context-interruptible: on context cancellation, rc is closed to
+// unblock any in-flight Read. Otherwise, rc is NOT closed and the caller MUST
+// close it (e.g., via defer).
+//
+// The returned error is nil, [io.EOF] if the stream ends at a message boundary,
+// [io.ErrUnexpectedEOF] if it ends within a message, [ErrFrameTooLarge] if the
+// message exceeds maxSize bytes, or the error caused by I/O or by the context.
+// Nonpositive values of maxSize mean [bufio.MaxScanTokenSize], so an untrusted
+// length prefix cannot cause large allocations.
+func ReadDelimitedContext(ctx context.Context, rc io.ReadCloser, maxSize int) ([]byte, error) {
+	size, err := binary.ReadUvarint(contextByteReader{ctx, rc})
+	if err != nil {
+		return nil, err
+	}
+	if size > uint64(frameMaxSize(maxSize)) {
+		return nil, ErrFrameTooLarge
+	}
+	msg := make([]byte, size)
+	if _, err := ReadFullContext(ctx, rc, msg); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return msg, nil
+}
+
+// contextByteReader adapts an [io.ReadCloser] to be an [io.ByteReader] whose
+// reads are context-interruptible (see readContext).
+type contextByteReader struct {
+	ctx context.Context
+	rc  io.ReadCloser
+}
+
+// ReadByte implements [io.ByteReader].
+func (r contextByteReader) ReadByte() (byte, error) {
+	var buf [1]byte
+	if _, err := ReadFullContext(r.ctx, r.rc, buf[:]); err != nil {
+		return 0, err
+	}
+	return buf[0], nil
+}
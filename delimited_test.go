@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteAndReadDelimited(t *testing.T) {
+	buff := &bytes.Buffer{}
+	lwc := NewLockedWriteCloser(NopWriteCloser(buff))
+	messages := []string{"", "short", strings.Repeat("long message ", 20)}
+	for _, msg := range messages {
+		require.NoError(t, WriteDelimited(lwc, []byte(msg)))
+	}
+
+	// The length of the long message needs two bytes.
+	assert.Equal(t, byte(260&0x7f|0x80), buff.Bytes()[7])
+
+	rc := NewByteReadCloser(NopReadCloser(buff))
+	for _, expected := range messages {
+		msg, err := ReadDelimitedContext(context.Background(), rc, 1024)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(msg))
+	}
+	_, err := ReadDelimitedContext(context.Background(), rc, 1024)
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestReadDelimitedContextErrors(t *testing.T) {
+	t.Run("with a message too large", func(t *testing.T) {
+		rc := NopReadCloser(bytes.NewReader([]byte{0x80, 0x01}))
+		_, err := ReadDelimitedContext(context.Background(), rc, 127)
+		require.ErrorIs(t, err, ErrFrameTooLarge)
+	})
+
+	t.Run("with a hostile length and a nonpositive maximum size", func(t *testing.T) {
+		rc := NopReadCloser(bytes.NewReader(binary.AppendUvarint(nil, 1<<62)))
+		_, err := ReadDelimitedContext(context.Background(), rc, -1)
+		require.ErrorIs(t, err, ErrFrameTooLarge)
+	})
+
+	t.Run("with a nonpositive maximum size", func(t *testing.T) {
+		msg := bytes.Repeat([]byte("x"), bufio.MaxScanTokenSize)
+		buff := &bytes.Buffer{}
+		require.NoError(t, WriteDelimited(NewLockedWriteCloser(NopWriteCloser(buff)), msg))
+		data, err := ReadDelimitedContext(context.Background(), NopReadCloser(buff), -1)
+		require.NoError(t, err)
+		assert.Equal(t, msg, data)
+	})
+
+	t.Run("with a truncated length", func(t *testing.T) {
+		rc := NopReadCloser(bytes.NewReader([]byte{0x80}))
+		_, err := ReadDelimitedContext(context.Background(), rc, 1024)
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("with a truncated message", func(t *testing.T) {
+		rc := NopReadCloser(bytes.NewReader([]byte{0x03, 'i'}))
+		_, err := ReadDelimitedContext(context.Background(), rc, 1024)
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("with a cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		rc := NopReadCloser(bytes.NewReader([]byte{0x00}))
+		_, err := ReadDelimitedContext(ctx, rc, 1024)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestWriteDelimitedWithClosedWriter(t *testing.T) {
+	lwc := NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{}))
+	require.NoError(t, lwc.Close())
+	require.ErrorIs(t, WriteDelimited(lwc, []byte("iox")), ErrClosed)
+}
@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+)
+
+// Error is the error returned when an I/O operation fails.
+//
+// [CopyContext] and the functions built on top of it return an [*Error] when
+// reading or writing fails, which allows telling which side of a copy failed.
+// Errors caused by the context are not wrapped.
+type Error struct {
+	// Op is the failed operation: "read", "write", or "close".
+	Op string
+
+	// Offset is the number of bytes successfully transferred by the
+	// failed side of the operation before the failure.
+	Offset int64
+
+	// Err is the underlying error.
+	Err error
+}
+
+var _ error = &Error{}
+
+// Error implements error.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at offset %d: %s", e.Op, e.Offset, e.Err.Error())
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// IsTimeout returns whether err is caused by a timeout, including
+// [context.DeadlineExceeded] and network timeouts.
+func IsTimeout(err error) bool {
+	var timeout interface{ Timeout() bool }
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &timeout) && timeout.Timeout())
+}
+
+// IsCanceled returns whether err is caused by [context.Canceled].
+func IsCanceled(err error) bool {
+	return errors.Is(err, context.Canceled)
+}
+
+// IsClosed returns whether err is caused by using a closed wrapper, file, or
+// connection, that is [ErrClosed], [os.ErrClosed], or [net.ErrClosed].
+func IsClosed(err error) bool {
+	return errors.Is(err, ErrClosed) || errors.Is(err, os.ErrClosed) || errors.Is(err, net.ErrClosed)
+}
+
+// opReader wraps an [io.Reader] such that read errors other than [io.EOF] become [*Error].
+//
+// Use [*opReader.reset] to initialize it.
+type opReader struct {
+	off int64
+	ow  opWriter
+	r   io.Reader
+}
+
+// reset makes or wrap r and returns the reader to use.
+//
+// When r implements [io.WriterTo], so does the returned reader, which preserves
+// the fast path of [io.Copy], and it classifies errors as caused by reading
+// unless the destination caused them. Since or may be part of a larger struct
+// (see [copyState]), reset does not allocate.
+func (or *opReader) reset(r io.Reader) io.Reader {
+	*or = opReader{r: r}
+	if _, ok := r.(io.WriterTo); ok {
+		return opWriterToReader{or}
+	}
+	return or
+}
+
+// Read implements [io.Reader].
+func (r *opReader) Read(buf []byte) (int, error) {
+	count, err := r.r.Read(buf)
+	r.off += int64(count)
+	if err != nil && err != io.EOF {
+		err = &Error{Op: "read", Offset: r.off, Err: err}
+	}
+	return count, err
+}
+
+// opWriterToReader is the [io.WriterTo] returned by [*opReader.reset].
+type opWriterToReader struct {
+	*opReader
+}
+
+// WriteTo implements [io.WriterTo].
+func (r opWriterToReader) WriteTo(w io.Writer) (int64, error) {
+	r.ow = opWriter{w: w}
+	count, err := r.r.(io.WriterTo).WriteTo(&r.ow)
+	r.off += count
+	if err != nil && !errors.As(err, new(*Error)) {
+		err = &Error{Op: "read", Offset: r.off, Err: err}
+	}
+	return count, err
+}
+
+// opWriter is an [io.Writer] turning write errors into [*Error].
+type opWriter struct {
+	off int64
+	w   io.Writer
+}
+
+// Write implements [io.Writer].
+func (w *opWriter) Write(data []byte) (int, error) {
+	count, err := w.w.Write(data)
+	w.off += int64(count)
+	if err != nil {
+		err = &Error{Op: "write", Offset: w.off, Err: err}
+	}
+	return count, err
+}
+
+// WriteString implements [io.StringWriter], which avoids copying strings into
+// a []byte when the reader is, e.g., a [*strings.Reader].
+func (w *opWriter) WriteString(s string) (int, error) {
+	count, err := io.WriteString(w.w, s)
+	w.off += int64(count)
+	if err != nil {
+		err = &Error{Op: "write", Offset: w.off, Err: err}
+	}
+	return count, err
+}
+
+// classifyCopyError turns an error returned by copying into an [*Error], assuming
+// the destination caused the errors not already classified by [*opReader].
+func classifyCopyError(err error, offset int) error {
+	if err == nil || errors.As(err, new(*Error)) {
+		return err
+	}
+	return &Error{Op: "write", Offset: int64(offset), Err: err}
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	stdiotest "testing/iotest"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestError(t *testing.T) {
+	expected := errors.New("mocked error")
+	err := error(&Error{Op: "write", Offset: 17, Err: expected})
+	assert.Equal(t, "write at offset 17: mocked error", err.Error())
+	assert.ErrorIs(t, err, expected)
+}
+
+func TestCopyContextClassifiesErrors(t *testing.T) {
+	expected := errors.New("mocked error")
+
+	t.Run("read error", func(t *testing.T) {
+		rc := NopReadCloser(io.MultiReader(strings.NewReader("abc"), stdiotest.ErrReader(expected)))
+		lwc := NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{}))
+		_, err := CopyContext(context.Background(), lwc, rc)
+		var ioerr *Error
+		require.ErrorAs(t, err, &ioerr)
+		assert.Equal(t, "read", ioerr.Op)
+		assert.Equal(t, int64(3), ioerr.Offset)
+		assert.ErrorIs(t, err, expected)
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		rc := NopReadCloser(strings.NewReader("abcdef"))
+		lwc := NewLockedWriteCloser(&iotest.FuncWriteCloser{
+			WriteFunc: func(data []byte) (int, error) {
+				return 2, expected
+			},
+			CloseFunc: func() error {
+				return nil
+			},
+		})
+		_, err := CopyContext(context.Background(), lwc, rc)
+		var ioerr *Error
+		require.ErrorAs(t, err, &ioerr)
+		assert.Equal(t, "write", ioerr.Op)
+		assert.Equal(t, int64(2), ioerr.Offset)
+		assert.ErrorIs(t, err, expected)
+	})
+
+	t.Run("write error using WriterTo", func(t *testing.T) {
+		rc := struct {
+			*bytes.Reader
+			io.Closer
+		}{bytes.NewReader([]byte("abcdef")), io.NopCloser(nil)}
+		var _ io.WriterTo = rc
+		lwc := NewLockedWriteCloser(&iotest.FuncWriteCloser{
+			WriteFunc: func(data []byte) (int, error) {
+				return 0, expected
+			},
+			CloseFunc: func() error {
+				return nil
+			},
+		})
+		_, err := CopyContext(context.Background(), lwc, rc)
+		var ioerr *Error
+		require.ErrorAs(t, err, &ioerr)
+		assert.Equal(t, "write", ioerr.Op)
+		assert.ErrorIs(t, err, expected)
+	})
+
+	t.Run("context errors are not wrapped", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		lwc := NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{}))
+		_, err := CopyContext(ctx, lwc, NopReadCloser(ZeroReader()))
+		assert.Same(t, context.Canceled, err)
+	})
+}
+
+// timeoutError is an error implementing Timeout.
+type timeoutError struct{}
+
+func (timeoutError) Error() string { return "timeout" }
+func (timeoutError) Timeout() bool { return true }
+
+func TestErrorClassifiers(t *testing.T) {
+	wrap := func(err error) error {
+		return fmt.Errorf("wrapped: %w", &Error{Op: "read", Err: err})
+	}
+
+	assert.True(t, IsTimeout(wrap(context.DeadlineExceeded)))
+	assert.True(t, IsTimeout(wrap(timeoutError{})))
+	assert.True(t, IsTimeout(wrap(os.ErrDeadlineExceeded)))
+	assert.False(t, IsTimeout(wrap(context.Canceled)))
+
+	assert.True(t, IsCanceled(wrap(context.Canceled)))
+	assert.False(t, IsCanceled(wrap(context.DeadlineExceeded)))
+
+	assert.True(t, IsClosed(wrap(ErrClosed)))
+	assert.True(t, IsClosed(wrap(os.ErrClosed)))
+	assert.True(t, IsClosed(wrap(net.ErrClosed)))
+	assert.False(t, IsClosed(wrap(io.EOF)))
+}
@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"errors"
+	"io"
+)
+
+var (
+	// ErrTruncated is returned when a stream ends before the expected length.
+	ErrTruncated = errors.New("stream shorter than expected")
+
+	// ErrOverlong is returned when a stream continues past the expected length.
+	ErrOverlong = errors.New("stream longer than expected")
+)
+
+// ExpectLengthReadCloser wraps rc such that reads fail unless rc contains
+// exactly n bytes, while Close forwards to the underlying rc.
+//
+// Reads fail with [ErrTruncated] if rc reaches EOF before n bytes and with
+// [ErrOverlong] if rc has more than n bytes. This is useful to check that an
+// HTTP body matches its declared Content-Length while copying it.
+func ExpectLengthReadCloser(rc io.ReadCloser, n int64) io.ReadCloser {
+	return &expectLengthReadCloser{rc: rc, remaining: n}
+}
+
+// expectLengthReadCloser is the [io.ReadCloser] returned by [ExpectLengthReadCloser].
+type expectLengthReadCloser struct {
+	rc        io.ReadCloser
+	remaining int64
+}
+
+// Read implements [io.Reader].
+func (r *expectLengthReadCloser) Read(buf []byte) (int, error) {
+	if len(buf) <= 0 {
+		return 0, nil
+	}
+	if r.remaining <= 0 {
+		more, err := probeMore(r.rc)
+		if more {
+			return 0, ErrOverlong
+		}
+		return 0, err
+	}
+	count, err := r.rc.Read(buf[:min(int64(len(buf)), r.remaining)])
+	r.remaining -= int64(count)
+	if errors.Is(err, io.EOF) && r.remaining > 0 {
+		err = ErrTruncated
+	}
+	return count, err
+}
+
+// Close implements [io.Closer].
+func (r *expectLengthReadCloser) Close() error {
+	return r.rc.Close()
+}
+
+// probeMore reads a single byte from r to check whether there is more data.
+//
+// Returns true if there is more data, otherwise false and the read error,
+// which is [io.EOF] when the stream ended.
+func probeMore(r io.Reader) (bool, error) {
+	var probe [1]byte
+	for {
+		count, err := r.Read(probe[:])
+		if count > 0 {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+}
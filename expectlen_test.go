@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpectLengthReadCloser(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		length   int64
+		expected error
+	}{
+		{"with the expected length", "iox", 3, nil},
+		{"with a truncated stream", "io", 3, ErrTruncated},
+		{"with an overlong stream", "ioxx", 3, ErrOverlong},
+		{"with an empty stream", "", 0, nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			buff := &bytes.Buffer{}
+			rc := ExpectLengthReadCloser(NopReadCloser(strings.NewReader(tc.input)), tc.length)
+			count, err := CopyContext(context.Background(), NewLockedWriteCloser(NopWriteCloser(buff)), rc)
+			if tc.expected != nil {
+				require.ErrorIs(t, err, tc.expected)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, int(min(tc.length, int64(len(tc.input)))), count)
+			require.NoError(t, rc.Close())
+		})
+	}
+}
+
+func TestExpectLengthReadCloserWithEmptyBuffer(t *testing.T) {
+	rc := ExpectLengthReadCloser(NopReadCloser(strings.NewReader("iox")), 0)
+	count, err := rc.Read(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	_, err = io.ReadAll(rc)
+	require.ErrorIs(t, err, ErrOverlong)
+}
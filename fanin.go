@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// FramingFunc appends the framed record to dst and returns the extended slice.
+//
+// See [NewlineFraming], [LengthPrefixFraming], and [UvarintFraming].
+type FramingFunc func(dst, record []byte) ([]byte, error)
+
+// NewlineFraming is a [FramingFunc] terminating records with a newline, unless
+// they already end with one, which is suitable for log lines and NDJSON.
+func NewlineFraming(dst, record []byte) ([]byte, error) {
+	dst = append(dst, record...)
+	if len(record) <= 0 || record[len(record)-1] != '\n' {
+		dst = append(dst, '\n')
+	}
+	return dst, nil
+}
+
+// LengthPrefixFraming is a [FramingFunc] prefixing records with their length as a
+// big-endian uint32, like [*FrameWriter], so that [*FrameReader] can read them.
+//
+// It fails with [ErrFrameTooLarge] when the record length does not fit into an uint32.
+func LengthPrefixFraming(dst, record []byte) ([]byte, error) {
+	if uint64(len(record)) > math.MaxUint32 {
+		return dst, ErrFrameTooLarge
+	}
+	dst = binary.BigEndian.AppendUint32(dst, uint32(len(record)))
+	return append(dst, record...), nil
+}
+
+// UvarintFraming is a [FramingFunc] prefixing records with their length as a
+// uvarint, like [WriteDelimited], so that [ReadDelimitedContext] can read them.
+func UvarintFraming(dst, record []byte) ([]byte, error) {
+	dst = binary.AppendUvarint(dst, uint64(len(record)))
+	return append(dst, record...), nil
+}
+
+// FanInWriteCloser merges the records written by multiple producers into a single writer.
+//
+// Each Write is a record, which is optionally framed using Framing and written using a
+// single [*LockedWriteCloser.LockedWrite] call, therefore records written by concurrent
+// producers are never interleaved, regardless of their size.
+//
+// All methods are safe for concurrent use.
+//
+// Construct using [NewFanInWriteCloser].
+type FanInWriteCloser struct {
+	// Framing is the optional [FramingFunc] applied to each record.
+	//
+	// [NewFanInWriteCloser] sets it to nil, meaning that records are written
+	// unmodified. You MUST NOT modify it after you started writing.
+	Framing FramingFunc
+
+	lwc *LockedWriteCloser
+}
+
+// NewFanInWriteCloser returns a new [*FanInWriteCloser] writing records into lwc.
+func NewFanInWriteCloser(lwc *LockedWriteCloser) *FanInWriteCloser {
+	return &FanInWriteCloser{lwc: lwc}
+}
+
+// Write implements [io.Writer] by atomically writing record.
+//
+// It returns len(record) on success and zero on failure, since a partially
+// written framed record is not meaningful. The returned error is nil, the one
+// returned by Framing, or the one returned by the [*LockedWriteCloser].
+func (w *FanInWriteCloser) Write(record []byte) (int, error) {
+	data := record
+	if w.Framing != nil {
+		var err error
+		if data, err = w.Framing(make([]byte, 0, len(record)+binary.MaxVarintLen64), record); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := w.lwc.LockedWrite(data); err != nil {
+		return 0, err
+	}
+	return len(record), nil
+}
+
+// Close closes the underlying [*LockedWriteCloser].
+func (w *FanInWriteCloser) Close() error {
+	return w.lwc.Close()
+}
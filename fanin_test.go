@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chunkingWriter writes at most n bytes per underlying Write call, which would
+// interleave records if the [*FanInWriteCloser] did not write them atomically.
+type chunkingWriter struct {
+	n int
+	w io.Writer
+}
+
+func (cw *chunkingWriter) Write(data []byte) (int, error) {
+	var total int
+	for len(data) > 0 {
+		count, err := cw.w.Write(data[:min(cw.n, len(data))])
+		total += count
+		if err != nil {
+			return total, err
+		}
+		data = data[count:]
+	}
+	return total, nil
+}
+
+func TestFanInWriteCloser(t *testing.T) {
+	t.Run("concurrent records are not interleaved", func(t *testing.T) {
+		buff := &bytes.Buffer{}
+		w := NewFanInWriteCloser(NewLockedWriteCloser(NopWriteCloser(&chunkingWriter{n: 3, w: buff})))
+		w.Framing = NewlineFraming
+
+		var wg sync.WaitGroup
+		for producer := range 8 {
+			wg.Go(func() {
+				for idx := range 50 {
+					record := fmt.Sprintf("producer %d record %d %s", producer, idx, strings.Repeat("x", idx+1))
+					count, err := w.Write([]byte(record))
+					assert.NoError(t, err)
+					assert.Equal(t, len(record), count)
+				}
+			})
+		}
+		wg.Wait()
+		require.NoError(t, w.Close())
+
+		scanner := bufio.NewScanner(buff)
+		var lines int
+		for scanner.Scan() {
+			var producer, idx int
+			var padding string
+			_, err := fmt.Sscanf(scanner.Text(), "producer %d record %d %s", &producer, &idx, &padding)
+			require.NoError(t, err, scanner.Text())
+			assert.Equal(t, strings.Repeat("x", idx+1), padding)
+			lines++
+		}
+		assert.Equal(t, 400, lines)
+	})
+
+	t.Run("LengthPrefixFraming is compatible with FrameReader", func(t *testing.T) {
+		buff := &bytes.Buffer{}
+		w := NewFanInWriteCloser(NewLockedWriteCloser(NopWriteCloser(buff)))
+		w.Framing = LengthPrefixFraming
+		_, err := w.Write([]byte("hello"))
+		require.NoError(t, err)
+
+		payload, err := NewFrameReader(NopReadCloser(buff), 16).ReadFrameContext(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(payload))
+	})
+
+	t.Run("UvarintFraming is compatible with ReadDelimitedContext", func(t *testing.T) {
+		buff := &bytes.Buffer{}
+		w := NewFanInWriteCloser(NewLockedWriteCloser(NopWriteCloser(buff)))
+		w.Framing = UvarintFraming
+		_, err := w.Write([]byte("hello"))
+		require.NoError(t, err)
+
+		msg, err := ReadDelimitedContext(context.Background(), NopReadCloser(buff), 16)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(msg))
+	})
+
+	t.Run("without framing and after close", func(t *testing.T) {
+		buff := &bytes.Buffer{}
+		w := NewFanInWriteCloser(NewLockedWriteCloser(NopWriteCloser(buff)))
+		_, err := w.Write([]byte("abc"))
+		require.NoError(t, err)
+		assert.Equal(t, "abc", buff.String())
+
+		require.NoError(t, w.Close())
+		count, err := w.Write([]byte("def"))
+		assert.ErrorIs(t, err, ErrClosed)
+		assert.Equal(t, 0, count)
+	})
+}
+
+func TestNewlineFraming(t *testing.T) {
+	data, err := NewlineFraming(nil, []byte("a\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "a\n", string(data))
+
+	data, err = NewlineFraming(nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "\n", string(data))
+}
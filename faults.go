@@ -0,0 +1,181 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"errors"
+	"io"
+)
+
+// Fault describes the behavior of a single I/O operation of a
+// [*FaultyReadCloser] or of a [*FaultyWriteCloser].
+type Fault struct {
+	// N is the maximum number of bytes the operation transfers.
+	//
+	// When Err is nil, a nonpositive N means no limit.
+	N int
+
+	// Err is the error returned by the operation.
+	//
+	// When not nil, the operation transfers at most N bytes, without
+	// touching the underlying stream if N is nonpositive, and then fails
+	// with Err. Use [io.EOF] to inject an early EOF.
+	Err error
+}
+
+// FaultyReadCloser is an [io.ReadCloser] injecting faults for testing.
+//
+// Faults are deterministic: the Schedule applies to the first reads, in order,
+// while MaxRead, ErrAfter and Err apply to all the subsequent reads.
+//
+// Configure the fields before the first read. Not safe for concurrent use.
+type FaultyReadCloser struct {
+	// ReadCloser is the underlying [io.ReadCloser].
+	ReadCloser io.ReadCloser
+
+	// Schedule contains the faults to apply to the first reads, one per read.
+	Schedule []Fault
+
+	// MaxRead, when positive, causes short reads of at most MaxRead bytes.
+	MaxRead int
+
+	// ErrAfter is the number of bytes after which reads fail with Err.
+	ErrAfter int64
+
+	// Err, when not nil, is returned by reads once ErrAfter bytes have been read.
+	Err error
+
+	// CloseErr, when not nil, is returned by Close after closing ReadCloser.
+	CloseErr error
+
+	count int64
+	calls int
+}
+
+var _ io.ReadCloser = &FaultyReadCloser{}
+
+// Read implements [io.Reader].
+func (r *FaultyReadCloser) Read(buf []byte) (int, error) {
+	fault := r.next()
+	if fault.Err != nil && fault.N <= 0 {
+		return 0, fault.Err
+	}
+	if fault.N > 0 {
+		buf = buf[:min(len(buf), fault.N)]
+	}
+	count, err := r.ReadCloser.Read(buf)
+	r.count += int64(count)
+	if fault.Err != nil {
+		err = fault.Err
+	}
+	return count, err
+}
+
+// next returns the [Fault] to apply to the next read.
+func (r *FaultyReadCloser) next() Fault {
+	defer func() { r.calls++ }()
+	if r.calls < len(r.Schedule) {
+		return r.Schedule[r.calls]
+	}
+	return nextFault(r.count, r.MaxRead, r.ErrAfter, r.Err)
+}
+
+// Close implements [io.Closer].
+func (r *FaultyReadCloser) Close() error {
+	return closeWithFault(r.ReadCloser, r.CloseErr)
+}
+
+// FaultyWriteCloser is an [io.WriteCloser] injecting faults for testing.
+//
+// Faults are deterministic: the Schedule applies to the first writes, in order,
+// while MaxWrite, ErrAfter and Err apply to all the subsequent writes.
+//
+// Because [io.Writer] requires short writes to return an error, writes limited
+// by a [Fault] without an error, or by MaxWrite, fail with [io.ErrShortWrite].
+//
+// Configure the fields before the first write. Not safe for concurrent use.
+type FaultyWriteCloser struct {
+	// WriteCloser is the underlying [io.WriteCloser].
+	WriteCloser io.WriteCloser
+
+	// Schedule contains the faults to apply to the first writes, one per write.
+	Schedule []Fault
+
+	// MaxWrite, when positive, causes short writes of at most MaxWrite bytes.
+	MaxWrite int
+
+	// ErrAfter is the number of bytes after which writes fail with Err.
+	ErrAfter int64
+
+	// Err, when not nil, is returned by writes once ErrAfter bytes have been written.
+	Err error
+
+	// CloseErr, when not nil, is returned by Close after closing WriteCloser.
+	CloseErr error
+
+	count int64
+	calls int
+}
+
+var _ io.WriteCloser = &FaultyWriteCloser{}
+
+// Write implements [io.Writer].
+func (w *FaultyWriteCloser) Write(data []byte) (int, error) {
+	fault := w.next()
+	if fault.Err != nil && fault.N <= 0 {
+		return 0, fault.Err
+	}
+	short := fault.N > 0 && fault.N < len(data)
+	if short {
+		data = data[:fault.N]
+	}
+	count, err := w.WriteCloser.Write(data)
+	w.count += int64(count)
+	switch {
+	case fault.Err != nil:
+		err = fault.Err
+	case err == nil && short && w.Err != nil && w.count >= w.ErrAfter:
+		err = w.Err
+	case err == nil && short:
+		err = io.ErrShortWrite
+	}
+	return count, err
+}
+
+// next returns the [Fault] to apply to the next write.
+func (w *FaultyWriteCloser) next() Fault {
+	defer func() { w.calls++ }()
+	if w.calls < len(w.Schedule) {
+		return w.Schedule[w.calls]
+	}
+	return nextFault(w.count, w.MaxWrite, w.ErrAfter, w.Err)
+}
+
+// Close implements [io.Closer].
+func (w *FaultyWriteCloser) Close() error {
+	return closeWithFault(w.WriteCloser, w.CloseErr)
+}
+
+// nextFault computes the [Fault] for an operation not covered by a schedule.
+func nextFault(count int64, maxio int, errAfter int64, err error) Fault {
+	if err == nil {
+		return Fault{N: maxio}
+	}
+	remaining := errAfter - count
+	if remaining <= 0 {
+		return Fault{Err: err}
+	}
+	if maxio > 0 && int64(maxio) < remaining {
+		return Fault{N: maxio}
+	}
+	return Fault{N: int(remaining)}
+}
+
+// closeWithFault closes c and returns faultErr, if not nil, or the close error.
+func closeWithFault(c io.Closer, faultErr error) error {
+	err := c.Close()
+	if faultErr != nil {
+		return errors.Join(faultErr, err)
+	}
+	return err
+}
@@ -0,0 +1,128 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFaultyReadCloser(t *testing.T) {
+	t.Run("with a schedule", func(t *testing.T) {
+		expected := errors.New("mocked read error")
+		frc := &FaultyReadCloser{
+			ReadCloser: NopReadCloser(strings.NewReader("hello, world")),
+			Schedule: []Fault{
+				{N: 2},
+				{Err: expected},
+				{N: 3, Err: io.EOF},
+			},
+		}
+
+		buf := make([]byte, 64)
+		count, err := frc.Read(buf)
+		require.NoError(t, err)
+		assert.Equal(t, "he", string(buf[:count]))
+
+		count, err = frc.Read(buf)
+		require.ErrorIs(t, err, expected)
+		assert.Equal(t, 0, count)
+
+		count, err = frc.Read(buf)
+		require.ErrorIs(t, err, io.EOF)
+		assert.Equal(t, "llo", string(buf[:count]))
+
+		// Once the schedule is over, reads pass through.
+		data, err := io.ReadAll(frc)
+		require.NoError(t, err)
+		assert.Equal(t, ", world", string(data))
+	})
+
+	t.Run("with short reads and an error after N bytes", func(t *testing.T) {
+		expected := errors.New("mocked connection reset")
+		frc := &FaultyReadCloser{
+			ReadCloser: NopReadCloser(strings.NewReader("hello, world")),
+			MaxRead:    2,
+			ErrAfter:   5,
+			Err:        expected,
+		}
+
+		buff := &bytes.Buffer{}
+		count, err := CopyContext(context.Background(), NewLockedWriteCloser(NopWriteCloser(buff)), frc)
+		require.ErrorIs(t, err, expected)
+		assert.Equal(t, 5, count)
+		assert.Equal(t, "hello", buff.String())
+	})
+
+	t.Run("with a close error", func(t *testing.T) {
+		expected := errors.New("mocked close error")
+		frc := &FaultyReadCloser{
+			ReadCloser: NopReadCloser(strings.NewReader("")),
+			CloseErr:   expected,
+		}
+		require.ErrorIs(t, frc.Close(), expected)
+	})
+}
+
+func TestFaultyWriteCloser(t *testing.T) {
+	t.Run("with a schedule", func(t *testing.T) {
+		expected := errors.New("mocked write error")
+		buff := &bytes.Buffer{}
+		fwc := &FaultyWriteCloser{
+			WriteCloser: NopWriteCloser(buff),
+			Schedule: []Fault{
+				{N: 2},
+				{N: 1, Err: expected},
+			},
+		}
+
+		count, err := fwc.Write([]byte("hello"))
+		require.ErrorIs(t, err, io.ErrShortWrite)
+		assert.Equal(t, 2, count)
+
+		count, err = fwc.Write([]byte("llo"))
+		require.ErrorIs(t, err, expected)
+		assert.Equal(t, 1, count)
+
+		// Once the schedule is over, writes pass through.
+		count, err = fwc.Write([]byte("lo"))
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+		assert.Equal(t, "hello", buff.String())
+	})
+
+	t.Run("with an error after N bytes", func(t *testing.T) {
+		expected := errors.New("mocked broken pipe")
+		buff := &bytes.Buffer{}
+		fwc := &FaultyWriteCloser{
+			WriteCloser: NopWriteCloser(buff),
+			ErrAfter:    3,
+			Err:         expected,
+		}
+
+		count, err := fwc.Write([]byte("hello"))
+		require.ErrorIs(t, err, expected)
+		assert.Equal(t, 3, count)
+
+		count, err = fwc.Write([]byte("lo"))
+		require.ErrorIs(t, err, expected)
+		assert.Equal(t, 0, count)
+		assert.Equal(t, "hel", buff.String())
+	})
+
+	t.Run("with a close error", func(t *testing.T) {
+		expected := errors.New("mocked close error")
+		fwc := &FaultyWriteCloser{
+			WriteCloser: NopWriteCloser(&bytes.Buffer{}),
+			CloseErr:    expected,
+		}
+		require.ErrorIs(t, fwc.Close(), expected)
+	})
+}
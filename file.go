@@ -0,0 +1,183 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ErrSameFile is returned by [CopyFileContext] when the source and the
+// destination are the same file.
+var ErrSameFile = errors.New("source and destination are the same file")
+
+// ErrFileTooLarge is returned by [ReadFileContext] when the file exceeds the maximum size.
+var ErrFileTooLarge = errors.New("file too large")
+
+// WithSync causes functions writing files, such as [CopyFileContext], to flush the
+// written file to stable storage using [*os.File.Sync] before closing it.
+func WithSync() CopyOption {
+	return func(cfg *copyConfig) {
+		cfg.sync = true
+	}
+}
+
+// CopyFileContext copies the file at srcPath into dstPath using [CopyContext].
+//
+// It creates or truncates dstPath, setting the permissions of srcPath. On Linux,
+// the copy uses copy_file_range, unless options wrapping the reader or the writer
+// disable this fast path (see [CopyContext]). On failure, including context
+// cancellation, it removes the partially written dstPath. It fails with
+// [ErrSameFile], without modifying it, when dstPath names the file at srcPath,
+// including through links, since truncating dstPath would wipe srcPath.
+//
+// This function honors [WithSync] and the options honored by [CopyContext].
+//
+// The returned count is the number of bytes copied. The returned error is nil or
+// [ErrSameFile], or the error occurred when opening, copying, syncing, or closing
+// the files.
+func CopyFileContext(ctx context.Context, dstPath, srcPath string, opts ...CopyOption) (int, error) {
+	// 1. open the source and get its permissions
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if dstInfo, err := os.Stat(dstPath); err == nil && os.SameFile(info, dstInfo) {
+		return 0, ErrSameFile
+	}
+
+	// 2. create the destination with the same permissions, which we also need to
+	// set explicitly because of the umask and because dstPath may already exist
+	dst, err := os.OpenFile(dstPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return 0, err
+	}
+	if err := dst.Chmod(info.Mode().Perm()); err != nil {
+		dst.Close()
+		os.Remove(dstPath)
+		return 0, err
+	}
+
+	// 3. copy, sync, and close, removing the destination on failure
+	count, err := CopyContext(ctx, NewLockedWriteCloser(NopWriteCloser(dst)), src, opts...)
+	if err := finishFile(dst, err, newCopyConfig(opts...).sync); err != nil {
+		os.Remove(dstPath)
+		return count, err
+	}
+	return count, nil
+}
+
+// finishFile syncs file, if needed and if writing succeeded, and closes it.
+//
+// The err argument is the error occurred when writing into file. The returned
+// error is the first error among err and the sync and close errors.
+func finishFile(file *os.File, err error, sync bool) error {
+	if err == nil && sync {
+		err = file.Sync()
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// WriteFileAtomicContext atomically replaces the file at path with the content of r.
+//
+// It streams r into a temporary file in the same directory as path using
+// [CopyContext], sets the given permissions, syncs the temporary file to stable
+// storage, and renames it to path. Therefore, readers of path either see the
+// previous content or the whole new content. On failure, including context
+// cancellation, it removes the temporary file and leaves path untouched.
+//
+// Because r is not an [io.ReadCloser], context cancellation cannot unblock an
+// in-flight Read, which continues in the background, but its result is discarded
+// (see [NeverClose]), so the function returns promptly regardless.
+//
+// The returned error is nil or the error occurred when writing, syncing, or
+// renaming the temporary file.
+func WriteFileAtomicContext(ctx context.Context, path string, r io.Reader, perm os.FileMode) error {
+	// 1. create the temporary file in the same directory, so renaming is atomic
+	dir, base := filepath.Split(path)
+	file, err := os.CreateTemp(dir, "."+base+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := file.Name()
+
+	// 2. set the permissions, copy, sync, and close
+	err = file.Chmod(perm)
+	if err == nil {
+		lwc := NewLockedWriteCloser(NopWriteCloser(file))
+		_, err = CopyContext(ctx, lwc, NopReadCloser(r), WithOwnership(NeverClose))
+	}
+	if err := finishFile(file, err, true); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	// 3. rename into place
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// ReadFileContext is a context-interruptible variant of [os.ReadFile].
+//
+// It reads the file using [ReadAllContext], so reading can be canceled along with,
+// e.g., the request being served. When maxSize is positive, it fails with
+// [ErrFileTooLarge] if the file is larger than maxSize bytes, without reading
+// more than maxSize+1 bytes. Nonpositive values of maxSize disable the check.
+//
+// The returned error is nil, [ErrFileTooLarge], or the error caused by I/O or by
+// the context. Like for [ReadAllContext], the returned bytes are those read before
+// the I/O or context error, if any.
+func ReadFileContext(ctx context.Context, name string, maxSize int64) ([]byte, error) {
+	// 1. open the file and bail early if it is already too large
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var sizeHint int64
+	if info, err := file.Stat(); err == nil && info.Mode().IsRegular() {
+		if maxSize > 0 && info.Size() > maxSize {
+			return nil, ErrFileTooLarge
+		}
+		sizeHint = info.Size()
+	}
+
+	// 2. read, checking the size again in case the file has grown
+	data, err := ReadAllLimitContext(ctx, file, maxSize, sizeHint)
+	if errors.Is(err, ErrReadLimitExceeded) {
+		err = ErrFileTooLarge
+	}
+	return data, err
+}
+
+// WriteFileContext is a context-interruptible variant of [os.WriteFile].
+//
+// Like [os.WriteFile], it creates or truncates the file, using perm when creating
+// it, and writes data using [CopyContext], so writing can be canceled along with,
+// e.g., the request being served. On failure, the file may be partially written:
+// use [WriteFileAtomicContext] to avoid this.
+//
+// The returned error is nil or the error caused by I/O or by the context.
+func WriteFileContext(ctx context.Context, name string, data []byte, perm os.FileMode) error {
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	_, err = CopyContext(ctx, NewLockedWriteCloser(NopWriteCloser(file)), NopReadCloser(bytes.NewReader(data)))
+	return finishFile(file, err, false)
+}
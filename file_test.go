@@ -0,0 +1,244 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	stdiotest "testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyFileContext(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		dir := t.TempDir()
+		srcPath := filepath.Join(dir, "src")
+		require.NoError(t, os.WriteFile(srcPath, []byte("iox-file"), 0600))
+		require.NoError(t, os.Chmod(srcPath, 0751))
+		dstPath := filepath.Join(dir, "dst")
+		require.NoError(t, os.WriteFile(dstPath, []byte("previous longer content"), 0600))
+
+		count, err := CopyFileContext(context.Background(), dstPath, srcPath, WithSync())
+		require.NoError(t, err)
+		assert.Equal(t, 8, count)
+		data, err := os.ReadFile(dstPath)
+		require.NoError(t, err)
+		assert.Equal(t, "iox-file", string(data))
+		info, err := os.Stat(dstPath)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0751), info.Mode().Perm())
+	})
+
+	t.Run("failures remove the destination", func(t *testing.T) {
+		// Reading from a directory fails with EISDIR.
+		dir := t.TempDir()
+		srcPath := filepath.Join(dir, "src")
+		require.NoError(t, os.Mkdir(srcPath, 0700))
+		dstPath := filepath.Join(dir, "dst")
+
+		_, err := CopyFileContext(context.Background(), dstPath, srcPath)
+		require.Error(t, err)
+		_, err = os.Stat(dstPath)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("cancellation removes the destination", func(t *testing.T) {
+		dir := t.TempDir()
+		srcPath := filepath.Join(dir, "src")
+		require.NoError(t, os.WriteFile(srcPath, []byte("iox-file"), 0600))
+		dstPath := filepath.Join(dir, "dst")
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := CopyFileContext(ctx, dstPath, srcPath)
+		require.ErrorIs(t, err, context.Canceled)
+		_, err = os.Stat(dstPath)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("same file", func(t *testing.T) {
+		dir := t.TempDir()
+		srcPath := filepath.Join(dir, "src")
+		require.NoError(t, os.WriteFile(srcPath, []byte("iox-file"), 0600))
+		linkPath := filepath.Join(dir, "link")
+		require.NoError(t, os.Link(srcPath, linkPath))
+		symlinkPath := filepath.Join(dir, "symlink")
+		require.NoError(t, os.Symlink(srcPath, symlinkPath))
+
+		for _, dstPath := range []string{srcPath, linkPath, symlinkPath} {
+			count, err := CopyFileContext(context.Background(), dstPath, srcPath)
+			require.ErrorIs(t, err, ErrSameFile)
+			assert.Equal(t, 0, count)
+			data, err := os.ReadFile(srcPath)
+			require.NoError(t, err)
+			assert.Equal(t, "iox-file", string(data))
+		}
+	})
+
+	t.Run("missing source", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := CopyFileContext(context.Background(), filepath.Join(dir, "dst"), filepath.Join(dir, "src"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+		_, err = os.Stat(filepath.Join(dir, "dst"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestWriteFileAtomicContext(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config")
+		require.NoError(t, os.WriteFile(path, []byte("old"), 0600))
+
+		err := WriteFileAtomicContext(context.Background(), path, strings.NewReader("new"), 0640)
+		require.NoError(t, err)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "new", string(data))
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+		assertDirEntries(t, dir, "config")
+	})
+
+	t.Run("failures keep the previous content", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config")
+		require.NoError(t, os.WriteFile(path, []byte("old"), 0600))
+		expected := errors.New("mocked error")
+
+		err := WriteFileAtomicContext(context.Background(), path, io.MultiReader(
+			strings.NewReader("partial"), stdiotest.ErrReader(expected)), 0600)
+		require.ErrorIs(t, err, expected)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "old", string(data))
+		assertDirEntries(t, dir, "config")
+	})
+
+	t.Run("cancellation removes the temporary file", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config")
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := WriteFileAtomicContext(ctx, path, strings.NewReader("new"), 0600)
+		require.ErrorIs(t, err, context.Canceled)
+		assertDirEntries(t, dir)
+	})
+
+	t.Run("cancellation does not wait for a stuck Read", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config")
+		pr, pw := io.Pipe()
+		defer pw.Close()
+		go pw.Write([]byte("partial")) // then the reader blocks forever
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		errch := make(chan error, 1)
+		go func() {
+			errch <- WriteFileAtomicContext(ctx, path, pr, 0600)
+		}()
+		select {
+		case err := <-errch:
+			require.ErrorIs(t, err, context.DeadlineExceeded)
+		case <-time.After(5 * time.Second):
+			t.Fatal("WriteFileAtomicContext did not return")
+		}
+		assertDirEntries(t, dir)
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "missing", "config")
+		err := WriteFileAtomicContext(context.Background(), path, strings.NewReader("new"), 0600)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+// assertDirEntries asserts that dir contains exactly the given entries.
+func assertDirEntries(t *testing.T, dir string, names ...string) {
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Name())
+	}
+	assert.Equal(t, names, got)
+}
+
+func TestReadFileContext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data")
+	require.NoError(t, os.WriteFile(path, []byte("iox-file"), 0600))
+
+	t.Run("without size cap", func(t *testing.T) {
+		data, err := ReadFileContext(context.Background(), path, 0)
+		require.NoError(t, err)
+		assert.Equal(t, "iox-file", string(data))
+	})
+
+	t.Run("within the size cap", func(t *testing.T) {
+		data, err := ReadFileContext(context.Background(), path, 8)
+		require.NoError(t, err)
+		assert.Equal(t, "iox-file", string(data))
+	})
+
+	t.Run("exceeding the size cap", func(t *testing.T) {
+		_, err := ReadFileContext(context.Background(), path, 7)
+		require.ErrorIs(t, err, ErrFileTooLarge)
+	})
+
+	t.Run("exceeding the size cap without a known size", func(t *testing.T) {
+		if _, err := os.Stat("/dev/zero"); err != nil {
+			t.Skip("no /dev/zero")
+		}
+		data, err := ReadFileContext(context.Background(), "/dev/zero", 1024)
+		require.ErrorIs(t, err, ErrFileTooLarge)
+		assert.Len(t, data, 1024)
+	})
+
+	t.Run("cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := ReadFileContext(ctx, path, 0)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := ReadFileContext(context.Background(), path+".missing", 0)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestWriteFileContext(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "data")
+		require.NoError(t, os.WriteFile(path, []byte("previous longer content"), 0600))
+		require.NoError(t, WriteFileContext(context.Background(), path, []byte("iox-file"), 0600))
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "iox-file", string(data))
+	})
+
+	t.Run("cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		path := filepath.Join(t.TempDir(), "data")
+		err := WriteFileContext(ctx, path, []byte("iox-file"), 0600)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "missing", "data")
+		err := WriteFileContext(context.Background(), path, []byte("iox-file"), 0600)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Flusher is implemented by writers buffering data until flushed.
+type Flusher interface {
+	Flush() error
+}
+
+// flusherEntry allows registering the same [Flusher] more than once.
+type flusherEntry struct {
+	f Flusher
+}
+
+var (
+	// flushersMu protects flushers.
+	flushersMu sync.Mutex
+
+	// flushers contains the registered flushers.
+	flushers = map[*flusherEntry]struct{}{}
+)
+
+// RegisterFlusher registers f such that [FlushAllContext] flushes it.
+//
+// Returns a function that unregisters f, which is safe to call more than once.
+// Wrappers should be unregistered once closed to avoid leaking them.
+func RegisterFlusher(f Flusher) (unregister func()) {
+	entry := &flusherEntry{f}
+	flushersMu.Lock()
+	flushers[entry] = struct{}{}
+	flushersMu.Unlock()
+	return func() {
+		flushersMu.Lock()
+		delete(flushers, entry)
+		flushersMu.Unlock()
+	}
+}
+
+// FlushAllContext flushes all the flushers registered using [RegisterFlusher].
+//
+// It flushes concurrently and waits until either all flushes complete or the
+// context is done, which allows to bound the draining time on graceful shutdown.
+//
+// The returned error joins the errors returned by each flush and, if the context
+// is done before all flushes complete, the context error.
+func FlushAllContext(ctx context.Context) error {
+	// 1. take a snapshot of the registered flushers
+	flushersMu.Lock()
+	entries := make([]*flusherEntry, 0, len(flushers))
+	for entry := range flushers {
+		entries = append(entries, entry)
+	}
+	flushersMu.Unlock()
+
+	// 2. flush in the background so we can be interrupted
+	errch := make(chan error, len(entries))
+	for _, entry := range entries {
+		go func() {
+			errch <- entry.f.Flush()
+		}()
+	}
+
+	// 3. collect the results
+	var errs []error
+	for range entries {
+		select {
+		case <-ctx.Done():
+			return errors.Join(append(errs, contextError(ctx))...)
+		case err := <-errch:
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// funcFlusher is a [Flusher] implemented by a func.
+type funcFlusher func() error
+
+// Flush implements [Flusher].
+func (f funcFlusher) Flush() error {
+	return f()
+}
+
+func TestFlushAllContextSuccess(t *testing.T) {
+	// Register two flushers, one of which fails.
+	calls := &atomic.Int64{}
+	expected := errors.New("mocked flush error")
+	unregister1 := RegisterFlusher(funcFlusher(func() error {
+		calls.Add(1)
+		return nil
+	}))
+	unregister2 := RegisterFlusher(funcFlusher(func() error {
+		calls.Add(1)
+		return expected
+	}))
+
+	err := FlushAllContext(context.Background())
+	require.ErrorIs(t, err, expected)
+	assert.Equal(t, int64(2), calls.Load())
+
+	// Once unregistered, flushers are not flushed anymore.
+	unregister1()
+	unregister2()
+	unregister2()
+	require.NoError(t, FlushAllContext(context.Background()))
+	assert.Equal(t, int64(2), calls.Load())
+}
+
+func TestFlushAllContextWithExpiredContext(t *testing.T) {
+	// Register a flusher that blocks until we unblock it.
+	unblock := make(chan struct{})
+	defer close(unblock)
+	unregister := RegisterFlusher(funcFlusher(func() error {
+		<-unblock
+		return nil
+	}))
+	defer unregister()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := FlushAllContext(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
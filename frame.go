@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"net"
+)
+
+// ErrFrameTooLarge is returned when a frame exceeds the maximum frame size.
+var ErrFrameTooLarge = errors.New("frame too large")
+
+// FrameWriter writes messages prefixed by their length as a big-endian uint32.
+//
+// It writes each frame using a single [*LockedWriteCloser.LockedWriteBuffers] call,
+// therefore frames emitted by concurrent goroutines are never interleaved.
+//
+// All methods are safe for concurrent use.
+//
+// Construct using [NewFrameWriter].
+type FrameWriter struct {
+	lwc     *LockedWriteCloser
+	maxSize int
+}
+
+// NewFrameWriter returns a new [*FrameWriter] writing frames into lwc whose
+// payload is at most maxSize bytes. Nonpositive values of maxSize mean
+// [bufio.MaxScanTokenSize].
+func NewFrameWriter(lwc *LockedWriteCloser, maxSize int) *FrameWriter {
+	return &FrameWriter{lwc: lwc, maxSize: frameMaxSize(maxSize)}
+}
+
+// WriteFrame writes payload as a single frame.
+//
+// The returned error is nil, [ErrFrameTooLarge] if payload exceeds the
+// maximum frame size, or the error returned by the [*LockedWriteCloser].
+func (fw *FrameWriter) WriteFrame(payload []byte) error {
+	if len(payload) > fw.maxSize || uint64(len(payload)) > math.MaxUint32 {
+		return ErrFrameTooLarge
+	}
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(payload)))
+	_, err := fw.lwc.LockedWriteBuffers(net.Buffers{header[:], payload})
+	return err
+}
+
+// Close closes the underlying [*LockedWriteCloser].
+func (fw *FrameWriter) Close() error {
+	return fw.lwc.Close()
+}
+
+// FrameReader reads messages prefixed by their length as a big-endian uint32.
+//
+// Reads are context-interruptible (see [ReadFullContext]).
+//
+// Not safe for concurrent use, except that Close may be called concurrently
+// with ReadFrameContext to interrupt it.
+//
+// Construct using [NewFrameReader].
+type FrameReader struct {
+	maxSize int
+	rc      io.ReadCloser
+}
+
+// NewFrameReader returns a new [*FrameReader] reading frames from rc whose
+// payload is at most maxSize bytes. Nonpositive values of maxSize mean
+// [bufio.MaxScanTokenSize], so an untrusted length prefix cannot cause
+// large allocations.
+func NewFrameReader(rc io.ReadCloser, maxSize int) *FrameReader {
+	return &FrameReader{maxSize: frameMaxSize(maxSize), rc: rc}
+}
+
+// frameMaxSize returns maxSize or, if nonpositive, [bufio.MaxScanTokenSize].
+func frameMaxSize(maxSize int) int {
+	if maxSize <= 0 {
+		return bufio.MaxScanTokenSize
+	}
+	return maxSize
+}
+
+// ReadFrameContext reads the next frame and returns its payload.
+//
+// On context cancellation, the underlying [io.ReadCloser] is closed to unblock
+// any in-flight Read, so the [*FrameReader] is not usable anymore.
+//
+// The returned error is nil, [io.EOF] if the stream ends at a frame boundary,
+// [io.ErrUnexpectedEOF] if it ends within a frame, [ErrFrameTooLarge] if the
+// frame exceeds the maximum frame size, or the error caused by I/O or by the context.
+func (fr *FrameReader) ReadFrameContext(ctx context.Context) ([]byte, error) {
+	var header [4]byte
+	if _, err := ReadFullContext(ctx, fr.rc, header[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if uint64(size) > uint64(fr.maxSize) {
+		return nil, ErrFrameTooLarge
+	}
+	payload := make([]byte, size)
+	if _, err := ReadFullContext(ctx, fr.rc, payload); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return payload, nil
+}
+
+// Close closes the underlying [io.ReadCloser].
+func (fr *FrameReader) Close() error {
+	return fr.rc.Close()
+}
@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrameWriterAndReader(t *testing.T) {
+	// Write frames concurrently and make sure they are not interleaved.
+	buff := &bytes.Buffer{}
+	fw := NewFrameWriter(NewLockedWriteCloser(NopWriteCloser(buff)), 64)
+	payloads := map[string]bool{"": true, "a": true, "frame": true, "another frame": true}
+	wg := &sync.WaitGroup{}
+	for payload := range payloads {
+		wg.Go(func() {
+			require.NoError(t, fw.WriteFrame([]byte(payload)))
+		})
+	}
+	wg.Wait()
+	require.NoError(t, fw.Close())
+
+	// Read the frames back.
+	fr := NewFrameReader(NopReadCloser(buff), 64)
+	for range len(payloads) {
+		payload, err := fr.ReadFrameContext(context.Background())
+		require.NoError(t, err)
+		assert.True(t, payloads[string(payload)])
+		delete(payloads, string(payload))
+	}
+	_, err := fr.ReadFrameContext(context.Background())
+	require.ErrorIs(t, err, io.EOF)
+	require.NoError(t, fr.Close())
+}
+
+func TestFrameWriterWithFrameTooLarge(t *testing.T) {
+	buff := &bytes.Buffer{}
+	fw := NewFrameWriter(NewLockedWriteCloser(NopWriteCloser(buff)), 4)
+	require.ErrorIs(t, fw.WriteFrame([]byte("too large")), ErrFrameTooLarge)
+	assert.Equal(t, 0, buff.Len())
+}
+
+func TestFrameWriterAndReaderWithNonpositiveMaxSize(t *testing.T) {
+	// Nonpositive sizes mean bufio.MaxScanTokenSize.
+	buff := &bytes.Buffer{}
+	fw := NewFrameWriter(NewLockedWriteCloser(NopWriteCloser(buff)), 0)
+	payload := bytes.Repeat([]byte("x"), bufio.MaxScanTokenSize)
+	require.NoError(t, fw.WriteFrame(payload))
+	require.ErrorIs(t, fw.WriteFrame(append(payload, 'x')), ErrFrameTooLarge)
+
+	fr := NewFrameReader(NopReadCloser(buff), -1)
+	data, err := fr.ReadFrameContext(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, payload, data)
+}
+
+func TestFrameReaderErrors(t *testing.T) {
+	t.Run("with a frame too large", func(t *testing.T) {
+		fr := NewFrameReader(NopReadCloser(bytes.NewReader([]byte{0, 0, 1, 0})), 255)
+		_, err := fr.ReadFrameContext(context.Background())
+		require.ErrorIs(t, err, ErrFrameTooLarge)
+	})
+
+	t.Run("with a hostile length and a nonpositive maximum size", func(t *testing.T) {
+		fr := NewFrameReader(NopReadCloser(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff})), -1)
+		_, err := fr.ReadFrameContext(context.Background())
+		require.ErrorIs(t, err, ErrFrameTooLarge)
+	})
+
+	t.Run("with a truncated header", func(t *testing.T) {
+		fr := NewFrameReader(NopReadCloser(bytes.NewReader([]byte{0, 0})), 255)
+		_, err := fr.ReadFrameContext(context.Background())
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("with a truncated payload", func(t *testing.T) {
+		fr := NewFrameReader(NopReadCloser(bytes.NewReader([]byte{0, 0, 0, 4, 'i'})), 255)
+		_, err := fr.ReadFrameContext(context.Background())
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("with a cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		fr := NewFrameReader(NopReadCloser(bytes.NewReader([]byte{0, 0, 0, 0})), 255)
+		_, err := fr.ReadFrameContext(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
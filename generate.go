@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"encoding/binary"
+	"io"
+	"math/rand/v2"
+)
+
+// RandReader returns an [io.ReadCloser] producing n bytes of pseudo-random data.
+//
+// The data only depends on the seed, so readers created with the same seed
+// produce the same bytes, which is useful for reproducible benchmarks and tests.
+// A negative n produces an endless stream. Reads do not allocate and Close is a no-op.
+//
+// The data is NOT suitable for cryptographic purposes.
+func RandReader(seed uint64, n int64) io.ReadCloser {
+	var key [32]byte
+	binary.LittleEndian.PutUint64(key[:], seed)
+	return &randReader{remaining: n, src: rand.NewChaCha8(key)}
+}
+
+// randReader is the [io.ReadCloser] returned by [RandReader].
+type randReader struct {
+	remaining int64
+	src       *rand.ChaCha8
+}
+
+// Read implements [io.Reader].
+func (r *randReader) Read(buf []byte) (int, error) {
+	buf, err := limitBuffer(buf, r.remaining)
+	if err != nil {
+		return 0, err
+	}
+	count, _ := r.src.Read(buf)
+	r.remaining -= int64(count)
+	return count, nil
+}
+
+// Close implements [io.Closer].
+func (r *randReader) Close() error {
+	return nil
+}
+
+// PatternReader returns an [io.ReadCloser] producing n bytes by repeating pattern.
+//
+// A negative n produces an endless stream. An empty pattern produces no data.
+// Reads do not allocate and Close is a no-op.
+func PatternReader(pattern []byte, n int64) io.ReadCloser {
+	if len(pattern) <= 0 {
+		n = 0
+	}
+	return &patternReader{pattern: pattern, remaining: n}
+}
+
+// patternReader is the [io.ReadCloser] returned by [PatternReader].
+type patternReader struct {
+	offset    int
+	pattern   []byte
+	remaining int64
+}
+
+// Read implements [io.Reader].
+func (r *patternReader) Read(buf []byte) (int, error) {
+	buf, err := limitBuffer(buf, r.remaining)
+	if err != nil {
+		return 0, err
+	}
+	for idx := range buf {
+		buf[idx] = r.pattern[r.offset]
+		r.offset = (r.offset + 1) % len(r.pattern)
+	}
+	r.remaining -= int64(len(buf))
+	return len(buf), nil
+}
+
+// Close implements [io.Closer].
+func (r *patternReader) Close() error {
+	return nil
+}
+
+// limitBuffer limits buf to the remaining number of bytes to produce.
+//
+// A negative remaining value means no limit, while zero means [io.EOF].
+func limitBuffer(buf []byte, remaining int64) ([]byte, error) {
+	switch {
+	case remaining < 0:
+		return buf, nil
+	case remaining == 0:
+		return nil, io.EOF
+	default:
+		return buf[:min(int64(len(buf)), remaining)], nil
+	}
+}
+
+// ZeroReader returns an [io.ReadCloser] producing an endless stream of zeros.
+//
+// Combine with [LimitReadCloser] to produce a finite stream. Reads do not
+// allocate and Close is a no-op.
+func ZeroReader() io.ReadCloser {
+	return zeroReader{}
+}
+
+// zeroReader is the [io.ReadCloser] returned by [ZeroReader].
+type zeroReader struct{}
+
+// Read implements [io.Reader].
+func (zeroReader) Read(buf []byte) (int, error) {
+	clear(buf)
+	return len(buf), nil
+}
+
+// Close implements [io.Closer].
+func (zeroReader) Close() error {
+	return nil
+}
+
+// DiscardWriteCloser returns a [*LockedWriteCloser] discarding all the data.
+//
+// Like any [*LockedWriteCloser], it is concurrency safe, counts the bytes written,
+// and can be passed directly to [CopyContext], which is useful to measure throughput.
+func DiscardWriteCloser() *LockedWriteCloser {
+	return NewLockedWriteCloser(NopWriteCloser(io.Discard))
+}
@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRandReader(t *testing.T) {
+	// Readers with the same seed produce the same data.
+	data1, err := io.ReadAll(RandReader(42, 1000))
+	require.NoError(t, err)
+	assert.Len(t, data1, 1000)
+	data2, err := io.ReadAll(RandReader(42, 1000))
+	require.NoError(t, err)
+	assert.Equal(t, data1, data2)
+
+	// Readers with a different seed produce different data.
+	data3, err := io.ReadAll(RandReader(43, 1000))
+	require.NoError(t, err)
+	assert.NotEqual(t, data1, data3)
+
+	// A negative size produces an endless stream.
+	rc := RandReader(42, -1)
+	data4, err := io.ReadAll(io.LimitReader(rc, 5000))
+	require.NoError(t, err)
+	assert.Equal(t, data1, data4[:1000])
+	require.NoError(t, rc.Close())
+}
+
+func TestPatternReader(t *testing.T) {
+	t.Run("with a finite size", func(t *testing.T) {
+		rc := PatternReader([]byte("abc"), 8)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		assert.Equal(t, "abcabcab", string(data))
+		require.NoError(t, rc.Close())
+	})
+
+	t.Run("with an endless stream", func(t *testing.T) {
+		rc := PatternReader([]byte("ab"), -1)
+		data, err := io.ReadAll(io.LimitReader(rc, 5))
+		require.NoError(t, err)
+		assert.Equal(t, "ababa", string(data))
+	})
+
+	t.Run("with an empty pattern", func(t *testing.T) {
+		data, err := io.ReadAll(PatternReader(nil, -1))
+		require.NoError(t, err)
+		assert.Empty(t, data)
+	})
+}
+
+func BenchmarkRandReader(b *testing.B) {
+	buf := make([]byte, 32<<10)
+	rc := RandReader(42, -1)
+	b.SetBytes(int64(len(buf)))
+	b.ReportAllocs()
+	for b.Loop() {
+		rc.Read(buf)
+	}
+}
+
+func TestZeroReaderAndDiscardWriteCloser(t *testing.T) {
+	// Copy a finite amount of zeros into the discard sink.
+	const size = 1 << 20
+	lwc := DiscardWriteCloser()
+	count, err := CopyContext(context.Background(), lwc, LimitReadCloser(ZeroReader(), size))
+	require.NoError(t, err)
+	assert.Equal(t, size, count)
+	assert.Equal(t, size, lwc.Count())
+
+	// The zero reader clears the buffer.
+	buf := []byte("iox")
+	n, err := ZeroReader().Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []byte{0, 0, 0}, buf)
+}
+
+func BenchmarkCopyContext(b *testing.B) {
+	const size = 1 << 20
+	b.SetBytes(size)
+	b.ReportAllocs()
+	for b.Loop() {
+		CopyContext(context.Background(), DiscardWriteCloser(), LimitReadCloser(ZeroReader(), size))
+	}
+}
@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HeartbeatWriteCloser is an [io.WriteCloser] writing a keepalive payload when
+// no write happens within an interval.
+//
+// This keeps alive long-lived control channels crossing NATs and middleboxes,
+// which otherwise expire idle flows. Both application writes and heartbeats
+// use [*LockedWriteCloser.LockedWrite], therefore heartbeats are never
+// interleaved with application writes. Heartbeats count as writes, so the
+// payload is written every interval while the application is idle.
+//
+// All methods are safe for concurrent use.
+//
+// Construct using [NewHeartbeatWriteCloser].
+type HeartbeatWriteCloser struct {
+	count    atomic.Int64
+	done     chan struct{}
+	interval time.Duration
+	last     atomic.Int64
+	lwc      *LockedWriteCloser
+	once     sync.Once
+	payload  []byte
+	start    time.Time
+	wg       sync.WaitGroup
+}
+
+// NewHeartbeatWriteCloser returns a new [*HeartbeatWriteCloser] writing into lwc
+// and writing a copy of payload whenever no write happens within interval.
+//
+// It starts a background goroutine emitting heartbeats, which runs until Close
+// or until writing a heartbeat fails. A nonpositive interval disables heartbeats.
+func NewHeartbeatWriteCloser(lwc *LockedWriteCloser, interval time.Duration, payload []byte) *HeartbeatWriteCloser {
+	w := &HeartbeatWriteCloser{
+		done:     make(chan struct{}),
+		interval: interval,
+		lwc:      lwc,
+		payload:  bytes.Clone(payload),
+		start:    time.Now(),
+	}
+	if interval > 0 {
+		w.wg.Go(w.loop)
+	}
+	return w
+}
+
+// Write implements [io.Writer].
+func (w *HeartbeatWriteCloser) Write(data []byte) (int, error) {
+	count, err := w.lwc.LockedWrite(data)
+	w.touch()
+	return count, err
+}
+
+// touch records that a write has just happened.
+func (w *HeartbeatWriteCloser) touch() {
+	w.last.Store(int64(time.Since(w.start)))
+}
+
+// loop writes heartbeats until Close or a write error.
+func (w *HeartbeatWriteCloser) loop() {
+	timer := time.NewTimer(w.interval)
+	defer timer.Stop()
+	for {
+		// 1. wait for the interval to expire
+		select {
+		case <-w.done:
+			return
+		case <-timer.C:
+		}
+
+		// 2. postpone the heartbeat if we have written in the meanwhile
+		idle := time.Since(w.start) - time.Duration(w.last.Load())
+		if idle < w.interval {
+			timer.Reset(w.interval - idle)
+			continue
+		}
+
+		// 3. write the heartbeat and stop on failure
+		if _, err := w.lwc.LockedWrite(w.payload); err != nil {
+			return
+		}
+		w.count.Add(1)
+		w.touch()
+		timer.Reset(w.interval)
+	}
+}
+
+// Heartbeats returns the number of heartbeats written so far.
+func (w *HeartbeatWriteCloser) Heartbeats() int {
+	return int(w.count.Load())
+}
+
+// Close stops writing heartbeats and closes the underlying [*LockedWriteCloser].
+//
+// It waits for any in-flight heartbeat to be written, then returns the error
+// returned by [*LockedWriteCloser.Close].
+func (w *HeartbeatWriteCloser) Close() error {
+	w.once.Do(func() { close(w.done) })
+	w.wg.Wait()
+	return w.lwc.Close()
+}
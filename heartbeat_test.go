@@ -0,0 +1,81 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeartbeatWriteCloser(t *testing.T) {
+	t.Run("idle writers emit heartbeats", func(t *testing.T) {
+		var buf bytes.Buffer
+		payload := []byte("PING\n")
+		w := NewHeartbeatWriteCloser(NewLockedWriteCloser(NopWriteCloser(&buf)), time.Millisecond, payload)
+		payload[0] = 'X' // the payload is copied
+		require.Eventually(t, func() bool { return w.Heartbeats() >= 3 }, time.Second, time.Millisecond)
+		require.NoError(t, w.Close())
+		assert.Equal(t, strings.Repeat("PING\n", w.Heartbeats()), buf.String())
+	})
+
+	t.Run("heartbeats never interleave with writes", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewHeartbeatWriteCloser(NewLockedWriteCloser(NopWriteCloser(&buf)), time.Microsecond, []byte("PING\n"))
+		var wg sync.WaitGroup
+		for range 4 {
+			wg.Go(func() {
+				for range 100 {
+					_, err := w.Write([]byte("DATA\n"))
+					assert.NoError(t, err)
+				}
+			})
+		}
+		wg.Wait()
+		require.NoError(t, w.Close())
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		var data int
+		for _, line := range lines {
+			require.Contains(t, []string{"PING", "DATA"}, line)
+			if line == "DATA" {
+				data++
+			}
+		}
+		assert.Equal(t, 400, data)
+		assert.Equal(t, w.Heartbeats(), len(lines)-data)
+	})
+
+	t.Run("writes postpone heartbeats", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewHeartbeatWriteCloser(NewLockedWriteCloser(NopWriteCloser(&buf)), time.Hour, []byte("PING\n"))
+		_, err := w.Write([]byte("DATA\n"))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		assert.Equal(t, 0, w.Heartbeats())
+		assert.Equal(t, "DATA\n", buf.String())
+	})
+
+	t.Run("close stops heartbeats and closes the writer", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewHeartbeatWriteCloser(NewLockedWriteCloser(NopWriteCloser(&buf)), time.Millisecond, []byte("PING\n"))
+		require.NoError(t, w.Close())
+		count := w.Heartbeats()
+		time.Sleep(5 * time.Millisecond)
+		assert.Equal(t, count, w.Heartbeats())
+		_, err := w.Write([]byte("DATA\n"))
+		assert.ErrorIs(t, err, ErrClosed)
+		assert.ErrorIs(t, w.Close(), ErrClosed)
+	})
+
+	t.Run("nonpositive intervals disable heartbeats", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewHeartbeatWriteCloser(NewLockedWriteCloser(NopWriteCloser(&buf)), 0, []byte("PING\n"))
+		require.NoError(t, w.Close())
+		assert.Equal(t, 0, w.Heartbeats())
+	})
+}
@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"encoding/hex"
+	"errors"
+	"io"
+	"sync"
+)
+
+// HexDumpWriter wraps w such that the bytes successfully written into w are also
+// mirrored into dst formatted like the output of `hexdump -C`.
+//
+// Close flushes the last line of the hex dump into dst and closes w. It does NOT
+// close dst. This is useful to debug protocols by wrapping the writer passed to
+// [NewLockedWriteCloser], e.g., using os.Stderr as dst.
+//
+// Write and Close are serialized, so they are safe for concurrent use.
+func HexDumpWriter(w io.WriteCloser, dst io.Writer) io.WriteCloser {
+	return &hexDumpWriter{dumper: hex.Dumper(dst), w: w}
+}
+
+// hexDumpWriter is the [io.WriteCloser] returned by [HexDumpWriter].
+type hexDumpWriter struct {
+	dumper io.WriteCloser
+	mu     sync.Mutex
+	w      io.WriteCloser
+}
+
+// Write implements [io.Writer].
+func (w *hexDumpWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	count, err := w.w.Write(data)
+	w.dumper.Write(data[:count])
+	return count, err
+}
+
+// Close implements [io.Closer].
+func (w *hexDumpWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return errors.Join(w.dumper.Close(), w.w.Close())
+}
@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHexDumpWriter(t *testing.T) {
+	const payload = "GET / HTTP/1.1\r\nHost: example.com\r\n\r\n"
+	buff := &bytes.Buffer{}
+	dump := &strings.Builder{}
+	lwc := NewLockedWriteCloser(HexDumpWriter(NopWriteCloser(buff), dump))
+
+	count, err := CopyContext(context.Background(), lwc, newSmallReadsReadCloser(payload, 5))
+	require.NoError(t, err)
+	assert.Equal(t, len(payload), count)
+	assert.Equal(t, payload, buff.String())
+
+	expected := "" +
+		"00000000  47 45 54 20 2f 20 48 54  54 50 2f 31 2e 31 0d 0a  |GET / HTTP/1.1..|\n" +
+		"00000010  48 6f 73 74 3a 20 65 78  61 6d 70 6c 65 2e 63 6f  |Host: example.co|\n" +
+		"00000020  6d 0d 0a 0d 0a                                    |m....|\n"
+	assert.Equal(t, expected, dump.String())
+}
@@ -0,0 +1,215 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrUnknownTag is returned by [DemuxInterleavedContext] when reading a record
+// whose tag does not have a corresponding output.
+var ErrUnknownTag = errors.New("unknown interleaved record tag")
+
+// interleavedHeaderSize is the size of the header preceding each record's data,
+// containing the tag, the sequence number, the timestamp, and the data length.
+const interleavedHeaderSize = 2 + 8 + 8 + 4
+
+// InterleavedRecord is a record written by an [*InterleavedWriter].
+type InterleavedRecord struct {
+	// Tag identifies the stream that produced the record (e.g., 1 for stdout
+	// and 2 for stderr).
+	Tag uint16
+
+	// Seq is the sequence number of the record, starting from zero, which
+	// matches the position of the record within the output stream.
+	Seq uint64
+
+	// Time is when the record was written.
+	Time time.Time
+
+	// Data contains the bytes written.
+	Data []byte
+}
+
+// InterleavedWriter merges several streams into a single output stream of
+// tagged and timestamped records, such as when capturing both the stdout
+// and the stderr of a process while preserving their relative ordering.
+//
+// Each record consists of a header containing the tag as a big-endian uint16,
+// the sequence number as a big-endian uint64, the timestamp as big-endian Unix
+// nanoseconds, and the data length as a big-endian uint32, followed by the data.
+// It assigns sequence numbers and writes each record using a single
+// [*LockedWriteCloser.LockedWriteBuffers] call while holding its own lock, so
+// records are never torn and their order in the output matches their sequence
+// numbers. Use [InterleavedReader] or [DemuxInterleavedContext] to read them.
+//
+// All methods are safe for concurrent use.
+//
+// Construct using [NewInterleavedWriter].
+type InterleavedWriter struct {
+	lwc     *LockedWriteCloser
+	maxSize int
+	mu      sync.Mutex
+	seq     uint64
+}
+
+// NewInterleavedWriter returns a new [*InterleavedWriter] writing records into
+// lwc whose data is at most maxSize bytes. Nonpositive values of maxSize mean
+// [bufio.MaxScanTokenSize].
+func NewInterleavedWriter(lwc *LockedWriteCloser, maxSize int) *InterleavedWriter {
+	return &InterleavedWriter{lwc: lwc, maxSize: frameMaxSize(maxSize)}
+}
+
+// WriteRecord writes data as a single record with the given tag.
+//
+// The returned error is nil, [ErrFrameTooLarge] if data exceeds the
+// maximum record size, or the error returned by the [*LockedWriteCloser].
+func (iw *InterleavedWriter) WriteRecord(tag uint16, data []byte) error {
+	if len(data) > iw.maxSize || uint64(len(data)) > math.MaxUint32 {
+		return ErrFrameTooLarge
+	}
+	iw.mu.Lock()
+	defer iw.mu.Unlock()
+	var header [interleavedHeaderSize]byte
+	binary.BigEndian.PutUint16(header[0:], tag)
+	binary.BigEndian.PutUint64(header[2:], iw.seq)
+	binary.BigEndian.PutUint64(header[10:], uint64(time.Now().UnixNano()))
+	binary.BigEndian.PutUint32(header[18:], uint32(len(data)))
+	if _, err := iw.lwc.LockedWriteBuffers(net.Buffers{header[:], data}); err != nil {
+		return err
+	}
+	iw.seq++
+	return nil
+}
+
+// Stream returns an [io.Writer] writing each Write as records with the
+// given tag, splitting data exceeding the maximum record size into several
+// records, which is useful to set, e.g., [os/exec.Cmd.Stdout].
+func (iw *InterleavedWriter) Stream(tag uint16) io.Writer {
+	return interleavedStream{iw: iw, tag: tag}
+}
+
+// Close closes the underlying [*LockedWriteCloser].
+func (iw *InterleavedWriter) Close() error {
+	return iw.lwc.Close()
+}
+
+// interleavedStream is the [io.Writer] returned by [*InterleavedWriter.Stream].
+type interleavedStream struct {
+	iw  *InterleavedWriter
+	tag uint16
+}
+
+// Write implements [io.Writer].
+func (s interleavedStream) Write(data []byte) (int, error) {
+	count := 0
+	for count < len(data) {
+		chunk := data[count:]
+		chunk = chunk[:min(len(chunk), s.iw.maxSize)]
+		if err := s.iw.WriteRecord(s.tag, chunk); err != nil {
+			return count, err
+		}
+		count += len(chunk)
+	}
+	return count, nil
+}
+
+// InterleavedReader reads the records written by an [*InterleavedWriter].
+//
+// Reads are context-interruptible (see [ReadFullContext]).
+//
+// Not safe for concurrent use, except that Close may be called concurrently
+// with ReadRecordContext to interrupt it.
+//
+// Construct using [NewInterleavedReader].
+type InterleavedReader struct {
+	maxSize int
+	rc      io.ReadCloser
+}
+
+// NewInterleavedReader returns a new [*InterleavedReader] reading records from
+// rc whose data is at most maxSize bytes. Nonpositive values of maxSize mean
+// [bufio.MaxScanTokenSize].
+func NewInterleavedReader(rc io.ReadCloser, maxSize int) *InterleavedReader {
+	return &InterleavedReader{maxSize: frameMaxSize(maxSize), rc: rc}
+}
+
+// ReadRecordContext reads the next record.
+//
+// On context cancellation, the underlying [io.ReadCloser] is closed to unblock
+// any in-flight Read, so the [*InterleavedReader] is not usable anymore.
+//
+// The returned error is nil, [io.EOF] if the stream ends at a record boundary,
+// [io.ErrUnexpectedEOF] if it ends within a record, [ErrFrameTooLarge] if the
+// record exceeds the maximum record size, or the error caused by I/O or by the context.
+func (ir *InterleavedReader) ReadRecordContext(ctx context.Context) (InterleavedRecord, error) {
+	var header [interleavedHeaderSize]byte
+	if _, err := ReadFullContext(ctx, ir.rc, header[:]); err != nil {
+		return InterleavedRecord{}, err
+	}
+	size := binary.BigEndian.Uint32(header[18:])
+	if uint64(size) > uint64(ir.maxSize) {
+		return InterleavedRecord{}, ErrFrameTooLarge
+	}
+	data := make([]byte, size)
+	if _, err := ReadFullContext(ctx, ir.rc, data); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return InterleavedRecord{}, err
+	}
+	record := InterleavedRecord{
+		Tag:  binary.BigEndian.Uint16(header[0:]),
+		Seq:  binary.BigEndian.Uint64(header[2:]),
+		Time: time.Unix(0, int64(binary.BigEndian.Uint64(header[10:]))),
+		Data: data,
+	}
+	return record, nil
+}
+
+// Close closes the underlying [io.ReadCloser].
+func (ir *InterleavedReader) Close() error {
+	return ir.rc.Close()
+}
+
+// DemuxInterleavedContext reads the records written by an [*InterleavedWriter]
+// from rc and writes the data of each record into the output for its tag, thus
+// splitting the merged stream back into its original streams. The maxSize
+// argument is the maximum record size, as for [NewInterleavedReader].
+//
+// On success, rc is NOT closed. On context cancellation, rc is closed to unblock
+// any in-flight Read.
+//
+// The returned error is nil when the stream ends at a record boundary, an error
+// wrapping [ErrUnknownTag] when a record has no output, the error returned by
+// [*InterleavedReader.ReadRecordContext], or the error writing into an output.
+func DemuxInterleavedContext(ctx context.Context, rc io.ReadCloser, maxSize int, outputs map[uint16]io.Writer) error {
+	ir := NewInterleavedReader(rc, maxSize)
+	for {
+		// 1. read the next record
+		record, err := ir.ReadRecordContext(ctx)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// 2. route it to the output for its tag
+		w, found := outputs[record.Tag]
+		if !found {
+			return fmt.Errorf("%w: %d", ErrUnknownTag, record.Tag)
+		}
+		if _, err := w.Write(record.Data); err != nil {
+			return err
+		}
+	}
+}
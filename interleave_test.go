@@ -0,0 +1,197 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterleavedWriterAndReader(t *testing.T) {
+	// Write records and read them back with their tags, sequence numbers, and timestamps.
+	buff := &bytes.Buffer{}
+	iw := NewInterleavedWriter(NewLockedWriteCloser(NopWriteCloser(buff)), 64)
+	before := time.Now()
+	require.NoError(t, iw.WriteRecord(1, []byte("out")))
+	require.NoError(t, iw.WriteRecord(2, []byte("err")))
+	require.NoError(t, iw.WriteRecord(1, nil))
+	require.NoError(t, iw.Close())
+
+	ir := NewInterleavedReader(NopReadCloser(buff), 64)
+	expected := []struct {
+		tag  uint16
+		data string
+	}{{1, "out"}, {2, "err"}, {1, ""}}
+	var last time.Time
+	for idx, entry := range expected {
+		record, err := ir.ReadRecordContext(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, entry.tag, record.Tag)
+		assert.Equal(t, uint64(idx), record.Seq)
+		assert.Equal(t, entry.data, string(record.Data))
+		assert.False(t, record.Time.Before(before))
+		assert.False(t, record.Time.Before(last))
+		last = record.Time
+	}
+	_, err := ir.ReadRecordContext(context.Background())
+	require.ErrorIs(t, err, io.EOF)
+	require.NoError(t, ir.Close())
+}
+
+func TestInterleavedWriterConcurrentStreams(t *testing.T) {
+	// Concurrent streams never tear records and sequence numbers follow the output order.
+	buff := &bytes.Buffer{}
+	iw := NewInterleavedWriter(NewLockedWriteCloser(NopWriteCloser(buff)), 64)
+	wg := &sync.WaitGroup{}
+	for tag := range uint16(4) {
+		wg.Go(func() {
+			w := iw.Stream(tag)
+			for idx := range 50 {
+				_, err := fmt.Fprintf(w, "%d:%d", tag, idx)
+				require.NoError(t, err)
+			}
+		})
+	}
+	wg.Wait()
+
+	ir := NewInterleavedReader(NopReadCloser(buff), 64)
+	next := map[uint16]int{}
+	for seq := range uint64(200) {
+		record, err := ir.ReadRecordContext(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, seq, record.Seq)
+		assert.Equal(t, fmt.Sprintf("%d:%d", record.Tag, next[record.Tag]), string(record.Data))
+		next[record.Tag]++
+	}
+	_, err := ir.ReadRecordContext(context.Background())
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestInterleavedStreamSplitsLargeWrites(t *testing.T) {
+	// Writes exceeding the maximum record size become several records.
+	buff := &bytes.Buffer{}
+	iw := NewInterleavedWriter(NewLockedWriteCloser(NopWriteCloser(buff)), 4)
+	count, err := iw.Stream(7).Write([]byte("0123456789"))
+	require.NoError(t, err)
+	assert.Equal(t, 10, count)
+
+	ir := NewInterleavedReader(NopReadCloser(buff), 4)
+	for _, expected := range []string{"0123", "4567", "89"} {
+		record, err := ir.ReadRecordContext(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, uint16(7), record.Tag)
+		assert.Equal(t, expected, string(record.Data))
+	}
+}
+
+func TestInterleavedWriterAndReaderWithNonpositiveMaxSize(t *testing.T) {
+	// Nonpositive sizes mean bufio.MaxScanTokenSize rather than rejecting every record.
+	buff := &bytes.Buffer{}
+	iw := NewInterleavedWriter(NewLockedWriteCloser(NopWriteCloser(buff)), 0)
+	data := bytes.Repeat([]byte("x"), bufio.MaxScanTokenSize)
+	require.NoError(t, iw.WriteRecord(1, data))
+	require.ErrorIs(t, iw.WriteRecord(1, append(data, 'x')), ErrFrameTooLarge)
+
+	ir := NewInterleavedReader(NopReadCloser(buff), -1)
+	record, err := ir.ReadRecordContext(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, data, record.Data)
+}
+
+func TestInterleavedWriterErrors(t *testing.T) {
+	t.Run("record too large", func(t *testing.T) {
+		iw := NewInterleavedWriter(NewLockedWriteCloser(NopWriteCloser(io.Discard)), 2)
+		require.ErrorIs(t, iw.WriteRecord(1, []byte("abc")), ErrFrameTooLarge)
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		expected := errors.New("mocked write error")
+		wc := &iotest.FuncWriteCloser{
+			WriteFunc: func(b []byte) (int, error) { return 0, expected },
+			CloseFunc: func() error { return nil },
+		}
+		iw := NewInterleavedWriter(NewLockedWriteCloser(wc), 64)
+		count, err := iw.Stream(1).Write([]byte("abc"))
+		require.ErrorIs(t, err, expected)
+		assert.Equal(t, 0, count)
+	})
+}
+
+func TestInterleavedReaderErrors(t *testing.T) {
+	// Write a record to use as a template for the failure cases.
+	buff := &bytes.Buffer{}
+	iw := NewInterleavedWriter(NewLockedWriteCloser(NopWriteCloser(buff)), 64)
+	require.NoError(t, iw.WriteRecord(1, []byte("abc")))
+	record := buff.Bytes()
+
+	t.Run("record too large", func(t *testing.T) {
+		ir := NewInterleavedReader(NopReadCloser(bytes.NewReader(record)), 2)
+		_, err := ir.ReadRecordContext(context.Background())
+		require.ErrorIs(t, err, ErrFrameTooLarge)
+	})
+
+	t.Run("truncated header", func(t *testing.T) {
+		ir := NewInterleavedReader(NopReadCloser(bytes.NewReader(record[:5])), 64)
+		_, err := ir.ReadRecordContext(context.Background())
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("truncated data", func(t *testing.T) {
+		ir := NewInterleavedReader(NopReadCloser(bytes.NewReader(record[:len(record)-1])), 64)
+		_, err := ir.ReadRecordContext(context.Background())
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		ir := NewInterleavedReader(NopReadCloser(bytes.NewReader(record)), 64)
+		_, err := ir.ReadRecordContext(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestDemuxInterleavedContext(t *testing.T) {
+	// Merge two streams and split them back.
+	buff := &bytes.Buffer{}
+	iw := NewInterleavedWriter(NewLockedWriteCloser(NopWriteCloser(buff)), 64)
+	stdout, stderr := iw.Stream(1), iw.Stream(2)
+	fmt.Fprint(stdout, "hello, ")
+	fmt.Fprint(stderr, "warning\n")
+	fmt.Fprint(stdout, "world\n")
+	merged := bytes.Clone(buff.Bytes())
+
+	var out, errs bytes.Buffer
+	outputs := map[uint16]io.Writer{1: &out, 2: &errs}
+	require.NoError(t, DemuxInterleavedContext(context.Background(), NopReadCloser(buff), 64, outputs))
+	assert.Equal(t, "hello, world\n", out.String())
+	assert.Equal(t, "warning\n", errs.String())
+
+	t.Run("unknown tag", func(t *testing.T) {
+		outputs := map[uint16]io.Writer{1: io.Discard}
+		err := DemuxInterleavedContext(context.Background(), NopReadCloser(bytes.NewReader(merged)), 64, outputs)
+		require.ErrorIs(t, err, ErrUnknownTag)
+	})
+
+	t.Run("output error", func(t *testing.T) {
+		expected := errors.New("mocked write error")
+		failing := &iotest.FuncWriteCloser{
+			WriteFunc: func(b []byte) (int, error) { return 0, expected },
+			CloseFunc: func() error { return nil },
+		}
+		outputs := map[uint16]io.Writer{1: failing, 2: io.Discard}
+		err := DemuxInterleavedContext(context.Background(), NopReadCloser(bytes.NewReader(merged)), 64, outputs)
+		require.ErrorIs(t, err, expected)
+	})
+}
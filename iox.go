@@ -8,11 +8,17 @@ import (
 	"context"
 	"errors"
 	"io"
+	"math"
+	"net"
+	"slices"
 	"sync"
+	"sync/atomic"
 )
 
-// ErrClosed is returned when writing on a closed [*LockedWriteCloser].
-var ErrClosed = errors.New("locked writer is closed")
+// ErrClosed is returned when using a closed value, such as a wrapper, a reader,
+// a writer, or a pool, e.g., when writing on a closed [*LockedWriteCloser], when
+// reading from a closed [*ResumableReadCloser], or when closing them more than once.
+var ErrClosed = errors.New("iox: use of closed value")
 
 // LockedWriteCloser is a concurrency safe [io.WriteCloser] wrapper.
 //
@@ -25,37 +31,292 @@ var ErrClosed = errors.New("locked writer is closed")
 //
 // Construct using [NewLockedWriteCloser].
 type LockedWriteCloser struct {
-	err error
-	mu  sync.RWMutex
-	num int
-	w   io.WriteCloser
+	// RecordSizes enables recording the histogram of the per-write
+	// sizes returned by [*LockedWriteCloser.Stats].
+	//
+	// [NewLockedWriteCloser] sets it to false.
+	//
+	// You MUST NOT modify it after the first write.
+	RecordSizes bool
+
+	// StickyErrors causes the first write error to be latched, such that all
+	// the subsequent writes fail fast with it, without reaching the underlying
+	// [io.WriteCloser], which is useful when many goroutines share a writer that
+	// may break. Use [*LockedWriteCloser.Err] to get the latched error.
+	//
+	// Flush errors are latched too, while errors caused by the reader passed to
+	// LockedReadFrom are not. Since zero-copy fast paths cannot tell read errors
+	// apart from write errors, it disables them for LockedReadFrom and [CopyContext].
+	//
+	// [NewLockedWriteCloser] sets it to false.
+	//
+	// You MUST NOT modify it after the first write.
+	StickyErrors bool
+
+	calls int64
+	cs    closeState
+	err   error
+	mu    sync.RWMutex
+	num   atomic.Int64
+	sizes *sizeCounters
+	w     io.WriteCloser
 }
 
 // NewLockedWriteCloser wraps an [io.WriteCloser] and returns a concurrency-safe wrapper.
 func NewLockedWriteCloser(w io.WriteCloser) *LockedWriteCloser {
-	return &LockedWriteCloser{w: w}
+	return &LockedWriteCloser{cs: closeState{idempotent: true}, w: w}
 }
 
 // LockedWrite writes the given bytes to the underlying [io.WriteCloser].
 //
-// The returned error is nil, [ErrClosed] when closed, or the error ocurred
-// when attempting to write into the underlying [io.WriteCloser].
+// The returned error is nil, [ErrClosed] when closed, the latched error when
+// using StickyErrors, or the error ocurred when attempting to write into the
+// underlying [io.WriteCloser].
 func (w *LockedWriteCloser) LockedWrite(data []byte) (int, error) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	if err := w.err; err != nil {
+	if err := w.check(); err != nil {
 		return 0, err
 	}
 	count, err := w.w.Write(data)
-	w.num += count
-	return count, err
+	w.account(count)
+	return count, w.latch(err)
+}
+
+// LockedReadFrom reads from r until EOF or error and writes into the underlying
+// [io.WriteCloser], counting the bytes written.
+//
+// When r and the underlying writer, possibly through transparent wrappers (see [As]),
+// support a zero-copy copy (e.g., splice between two [*net.TCPConn]), LockedReadFrom
+// forwards to the [io.ReaderFrom] of the writer holding the lock for the whole copy.
+// Otherwise, it copies using [*LockedWriteCloser.LockedWrite], so it never holds the
+// lock while reading from r, and concurrent writes may interleave with the copied chunks.
+//
+// The returned error is nil on EOF, [ErrClosed] when closed, or the error occurred
+// when reading from r or writing into the underlying [io.WriteCloser].
+func (w *LockedWriteCloser) LockedReadFrom(r io.Reader) (int64, error) {
+	if count, ok, err := w.lockedZeroCopy(r); ok {
+		return count, err
+	}
+	bp := getBuffer()
+	defer putBuffer(bp)
+	return io.CopyBuffer(writerAdapter{w}, r, *bp)
+}
+
+// LockedFlush flushes the underlying [io.WriteCloser] while holding the lock.
+//
+// It supports writers with a Flush method returning an error, such as a
+// [*bufio.Writer], and writers with a Flush method without return values, such
+// as the ones implementing [net/http.Flusher]. It looks for the Flush method
+// through transparent wrappers (see [As]), such as the ones returned by
+// [NopWriteCloser]. For other writers, LockedFlush is a no-op.
+//
+// The returned error is nil, [ErrClosed] when closed, or the error occurred
+// when flushing the underlying [io.WriteCloser].
+func (w *LockedWriteCloser) LockedFlush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.check(); err != nil {
+		return err
+	}
+	return w.latch(flushWriter(w.w))
+}
+
+// flushWriter flushes w, if possible, looking through transparent wrappers (see [As]).
+func flushWriter(w io.Writer) error {
+	if fw, ok := As[Flusher](w); ok {
+		return fw.Flush()
+	}
+	if fw, ok := As[interface{ Flush() }](w); ok {
+		fw.Flush()
+	}
+	return nil
+}
+
+// LockedWriteString is like [*LockedWriteCloser.LockedWrite] but writes a string.
+//
+// When the underlying writer implements [io.StringWriter], possibly through
+// transparent wrappers (see [As]), it avoids copying s into a []byte.
+func (w *LockedWriteCloser) LockedWriteString(s string) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.check(); err != nil {
+		return 0, err
+	}
+	var (
+		count int
+		err   error
+	)
+	if sw, ok := As[io.StringWriter](w.w); ok {
+		count, err = sw.WriteString(s)
+	} else {
+		count, err = w.w.Write([]byte(s))
+	}
+	w.account(count)
+	return count, w.latch(err)
+}
+
+// LockedWriteByte is like [*LockedWriteCloser.LockedWrite] but writes a single byte.
+//
+// When the underlying writer implements [io.ByteWriter], possibly through transparent
+// wrappers (see [As]), LockedWriteByte forwards to its WriteByte method.
+func (w *LockedWriteCloser) LockedWriteByte(c byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.check(); err != nil {
+		return err
+	}
+	if bw, ok := As[io.ByteWriter](w.w); ok {
+		err := bw.WriteByte(c)
+		if err != nil {
+			w.account(0)
+			return w.latch(err)
+		}
+		w.account(1)
+		return nil
+	}
+	count, err := w.w.Write([]byte{c})
+	w.account(count)
+	return w.latch(err)
+}
+
+// LockedWriteBuffers writes the concatenation of bufs to the underlying [io.WriteCloser].
+//
+// When the underlying writer is a [*net.TCPConn] or a [*net.UnixConn], possibly
+// through transparent wrappers (see [As]), such as [HalfCloseWriter], it uses
+// [net.Buffers] to write using writev, which avoids copying, e.g., a header and
+// its payload into a single buffer. Otherwise, it concatenates bufs and performs
+// a single Write, since other connections, e.g., a [*crypto/tls.Conn] or those
+// returned by [net.Pipe], would see one Write per buffer. In both cases, writes
+// by other goroutines never interleave with bufs, and the bytes written count
+// towards the same total.
+//
+// The returned error is nil, [ErrClosed] when closed, or the error ocurred
+// when attempting to write into the underlying [io.WriteCloser].
+func (w *LockedWriteCloser) LockedWriteBuffers(bufs net.Buffers) (int64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.check(); err != nil {
+		return 0, err
+	}
+	if conn, ok := writevConn(w.w); ok {
+		bufs = slices.Clone(bufs) // WriteTo consumes bufs
+		count, err := bufs.WriteTo(conn)
+		w.account(int(count))
+		return count, w.latch(err)
+	}
+	count, err := w.w.Write(bytes.Join(bufs, nil))
+	w.account(count)
+	return int64(count), w.latch(err)
+}
+
+// writevConn returns the [net.Conn] underlying w when [net.Buffers] writes
+// into it using writev, that is, when it is a [*net.TCPConn] or a [*net.UnixConn].
+func writevConn(w io.Writer) (net.Conn, bool) {
+	if conn, ok := As[*net.TCPConn](w); ok {
+		return conn, true
+	}
+	if conn, ok := As[*net.UnixConn](w); ok {
+		return conn, true
+	}
+	return nil, false
+}
+
+// ByteWriter returns an [io.ByteWriter] view of the [*LockedWriteCloser].
+//
+// The returned value also implements [io.Writer] and is useful to pass the
+// [*LockedWriteCloser] to APIs requiring an [io.ByteWriter].
+func (w *LockedWriteCloser) ByteWriter() io.ByteWriter {
+	return writerAdapter{w}
+}
+
+// check returns [ErrClosed] when closed, the latched error when using
+// StickyErrors, or nil. The caller MUST hold the lock.
+func (w *LockedWriteCloser) check() error {
+	if err := w.cs.check(); err != nil {
+		return err
+	}
+	return w.err
+}
+
+// latch latches err, if it is the first error and StickyErrors is true, and
+// returns err. The caller MUST hold the lock.
+func (w *LockedWriteCloser) latch(err error) error {
+	if err != nil && w.StickyErrors && w.err == nil {
+		w.err = err
+	}
+	return err
+}
+
+// Err returns the write error latched when StickyErrors is true, or nil.
+func (w *LockedWriteCloser) Err() error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.err
+}
+
+// account accounts for a write of count bytes. The caller MUST hold the lock.
+func (w *LockedWriteCloser) account(count int) {
+	w.num.Add(int64(count))
+	w.calls++
+	if w.RecordSizes {
+		if w.sizes == nil {
+			w.sizes = &sizeCounters{}
+		}
+		w.sizes.observe(count)
+	}
 }
 
 // Count returns the number of bytes successfully written so far.
+//
+// It does not take the lock, so it never waits for in-flight writes.
 func (w *LockedWriteCloser) Count() int {
+	return int(w.num.Load())
+}
+
+// Stats returns statistics about the writes so far.
+//
+// Each LockedWrite, LockedWriteString, and LockedWriteByte counts as a call, as
+// does each LockedReadFrom using the zero-copy fast path. The histogram of
+// the per-call sizes is only available when RecordSizes is true.
+func (w *LockedWriteCloser) Stats() IOStats {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return IOStats{Bytes: w.num.Load(), Calls: w.calls, Sizes: w.sizes.snapshot()}
+}
+
+// SwapWriter atomically replaces the underlying [io.WriteCloser] with newW.
+//
+// Since it holds the lock, writes in progress complete using the old writer and
+// subsequent writes use newW, while Count and Stats keep accumulating. When closeOld
+// is true, SwapWriter also closes the old writer while holding the lock, so that no
+// write may reach it afterwards. This allows, e.g., rotating log files or reconnecting
+// without replacing the [*LockedWriteCloser] shared by several goroutines.
+//
+// The returned error is nil, [ErrClosed] when closed, in which case newW is not
+// used and the caller still owns it, or the error occurred when closing the old
+// writer, in which case newW has been installed anyway.
+func (w *LockedWriteCloser) SwapWriter(newW io.WriteCloser, closeOld bool) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.cs.check(); err != nil {
+		return err
+	}
+	oldW := w.w
+	w.w = newW
+	if closeOld {
+		return oldW.Close()
+	}
+	return nil
+}
+
+// Unwrap returns the underlying [io.WriteCloser] as an [io.Writer].
+//
+// Writing into it directly bypasses both the lock and the accounting.
+func (w *LockedWriteCloser) Unwrap() io.Writer {
 	w.mu.RLock()
 	defer w.mu.RUnlock()
-	return w.num
+	return w.w
 }
 
 // Close ensures that subsequent writes would fail with [ErrClosed].
@@ -64,14 +325,24 @@ func (w *LockedWriteCloser) Count() int {
 func (w *LockedWriteCloser) Close() error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	if err := w.err; err != nil {
-		return err
-	}
-	w.err = ErrClosed
-	return w.w.Close()
+	return w.cs.close(w.w.Close)
+}
+
+// closeCanceled is like Close but used by [CopyContext] on cancellation, in which
+// case the background goroutine may still attempt to write. Debugging (see
+// [SetDebug]) does not report these writes, nor subsequent double closes.
+func (w *LockedWriteCloser) closeCanceled() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.cs.quiet = true
+	return w.cs.close(w.w.Close)
 }
 
-// writeAdapter adapts [*LockedWriteCloser] to be an [io.Writer].
+// writerAdapter adapts [*LockedWriteCloser] to be an [io.Writer].
+//
+// It does not implement [io.ReaderFrom], which would cause [io.CopyBuffer] to
+// hold the lock while reading. It only contains a pointer, so converting it to
+// an [io.Writer] does not allocate.
 type writerAdapter struct {
 	w *LockedWriteCloser
 }
@@ -81,6 +352,31 @@ func (w writerAdapter) Write(buf []byte) (int, error) {
 	return w.w.LockedWrite(buf)
 }
 
+// WriteString implements [io.StringWriter].
+func (w writerAdapter) WriteString(s string) (int, error) {
+	return w.w.LockedWriteString(s)
+}
+
+// WriteByte implements [io.ByteWriter].
+func (w writerAdapter) WriteByte(c byte) error {
+	return w.w.LockedWriteByte(c)
+}
+
+// flushingWriterAdapter adapts [*LockedWriteCloser] to be an [io.Writer]
+// that flushes after each write.
+type flushingWriterAdapter struct {
+	w *LockedWriteCloser
+}
+
+// Write implements [io.Writer].
+func (w flushingWriterAdapter) Write(buf []byte) (int, error) {
+	count, err := w.w.LockedWrite(buf)
+	if err != nil {
+		return count, err
+	}
+	return count, w.w.LockedFlush()
+}
+
 // CopyContext is a context-interruptible variant of [io.Copy].
 //
 // It copies from rc into lwc in a background goroutine. On return, it always
@@ -88,38 +384,90 @@ func (w writerAdapter) Write(buf []byte) (int, error) {
 // in-flight Read in the background goroutine.
 //
 // On success, rc is NOT closed. The caller MUST ensure rc is closed after
-// CopyContext returns (e.g., via defer).
+// CopyContext returns (e.g., via defer). Use [WithOwnership], or wrap rc using
+// [NewOwnedReadCloser], [NewBorrowedReadCloser], or [NewCancelableReadCloser],
+// to change these rules.
+//
+// When rc implements [io.WriterTo], the copy uses it. Otherwise, like
+// [*LockedWriteCloser.LockedReadFrom], the copy only forwards to the [io.ReaderFrom]
+// of the writer wrapped by lwc for zero-copy copies, since it would otherwise hold
+// the lock of lwc while reading, thus blocking closing lwc on cancellation.
+//
+// On Linux, when copying from a [*net.TCPConn], [*net.UnixConn], or [*os.File]
+// into a [*net.TCPConn] or [*os.File], possibly through transparent wrappers (see
+// [As]), such as [HalfCloseWriter], the copy uses splice, sendfile, or
+// copy_file_range, like [io.Copy] does, holding the lock of lwc for the whole
+// copy. In such a case, the byte count of lwc is only updated when the copy
+// completes. Options wrapping the reader or the writer, or observing each chunk,
+// disable this zero-copy fast path.
 //
-// The returned error is either caused by I/O or by the context.
-func CopyContext(ctx context.Context, lwc *LockedWriteCloser, rc io.ReadCloser) (int, error) {
-	// 1. prepare for receiving the background read result
-	errch := make(chan error, 1)
+// This function honors [WithFlushEachWrite], [WithMaxDuration], [WithLogger],
+// [WithCollector], [WithTracer], [WithCopyTrace], [WithBandwidthSummary], [WithTee],
+// [WithTeeFile], [WithTransform], and [WithCopyResult], and invokes the hooks
+// attached to the context using [ContextWithCopyTrace].
+//
+// The returned error is either caused by the context or an [*Error] caused by I/O.
+// When the context has been canceled with a cause (see [context.WithCancelCause]),
+// the returned error wraps both the context error and the cause.
+func CopyContext(ctx context.Context, lwc *LockedWriteCloser, rc io.ReadCloser, opts ...CopyOption) (int, error) {
+	// 1. prepare the state shared with the background goroutine
+	st := newCopyState(opts...)
+	cfg := &st.cfg
+	ctx, cancel := cfg.withMaxDuration(ctx)
+	defer cancel()
+	ownership := cfg.ownershipOf(rc)
+	writer := io.Writer(writerAdapter{lwc})
+	if cfg.flushEachWrite {
+		writer = flushingWriterAdapter{lwc}
+	}
+	tracer, result := cfg.startTrace(ctx), cfg.startResult()
+	st.lwc, st.ownership, st.raw = lwc, ownership, rc
+	st.src = cfg.zeroCopySource(rc, tracer)
+	rc, writer = tracer.wrap(cfg.wrapReadCloser(result.wrap(rc)), cfg.wrapDestination(writer))
+	st.rc, st.writer = rc, writer
 
 	// 2. do in background so we can be interrupted
-	go func() {
-		_, err := io.Copy(writerAdapter{lwc}, rc)
-		errch <- err
-	}()
+	go st.run()
 
 	// 3. wait and collect the error
-	var err error
+	var (
+		canceled bool
+		err      error
+	)
 	select {
 	case <-ctx.Done():
-		err = ctx.Err()
-		// 4a. close the reader to unblock the goroutine's Read
-		rc.Close()
-	case err = <-errch:
-		// 4b. success: do NOT close rc, the caller is responsible
+		canceled, err = true, contextError(ctx)
+		// 4a. close the reader to unblock the goroutine's Read unless it is shared
+		if ownership != NeverClose && ownership != AbandonOnCancel {
+			rc.Close()
+		}
+	case err = <-st.errch:
+		// 4b. completed: do NOT close rc unless we own it
+		if ownership == OwnedAlwaysClose {
+			rc.Close()
+		}
+	}
+	if ownership == AbandonOnCancel {
+		st.abandoned <- canceled
 	}
 
-	// 5. always close the writer so the byte count is stable
-	lwc.Close()
+	// 5. always close the writer so the byte count is stable, and
+	// then close the resources created by the options
+	if canceled {
+		lwc.closeCanceled()
+	} else {
+		lwc.Close()
+	}
+	cfg.close()
+	st.release()
 
 	// 6. access the number of bytes written once we have closed the
 	// writer, so the number is stable ("happens after").
 	count := lwc.Count()
 
-	// 7. return to the caller
+	// 7. finish tracing and return to the caller
+	tracer.end(count, err)
+	result.end(count, err)
 	return count, err
 }
 
@@ -130,18 +478,81 @@ func CopyContext(ctx context.Context, lwc *LockedWriteCloser, rc io.ReadCloser)
 // rc is closed to unblock any in-flight Read.
 //
 // The returned byte slice contains whatever was read before the error (if any),
-// so partial results are available even when the context is canceled.
+// so partial results are available even when the context is canceled. Like for
+// [CopyContext], the returned error wraps the cause of the context cancellation.
 func ReadAllContext(ctx context.Context, rc io.ReadCloser) ([]byte, error) {
 	buf := &bytes.Buffer{}
 	_, err := CopyContext(ctx, NewLockedWriteCloser(NopWriteCloser(buf)), rc)
 	return buf.Bytes(), err
 }
 
+// ErrReadLimitExceeded is returned by [ReadAllLimitContext] when the data
+// exceeds the maximum size.
+var ErrReadLimitExceeded = errors.New("read limit exceeded")
+
+// MaxUnlimitedSizeHint is the maximum number of bytes that [ReadAllLimitContext]
+// preallocates when the maximum size is nonpositive, regardless of the size hint.
+const MaxUnlimitedSizeHint = 1 << 20
+
+// ReadAllLimitContext is like [ReadAllContext] but reads at most maxSize bytes.
+//
+// It fails with [ErrReadLimitExceeded] when rc contains more than maxSize bytes,
+// without reading more than maxSize+1 bytes, which protects against exhausting
+// memory when reading untrusted inputs. Nonpositive values of maxSize disable the check.
+// Like [ReadAllContext], it honors the ownership markers, such as [*BorrowedReadCloser].
+//
+// When positive, sizeHint is the expected size of the data (e.g., the Content-Length
+// of an HTTP response), used to preallocate the returned slice, which avoids the
+// reallocations caused by growing it while reading large bodies. The hint is
+// capped to maxSize, or to [MaxUnlimitedSizeHint] when maxSize is nonpositive,
+// so an untrusted hint cannot cause large allocations.
+//
+// The returned error is nil, [ErrReadLimitExceeded], or the error caused by I/O
+// or by the context. In all cases, the returned bytes are those read before the
+// error, truncated to maxSize.
+func ReadAllLimitContext(ctx context.Context, rc io.ReadCloser, maxSize, sizeHint int64) ([]byte, error) {
+	// 1. preallocate, leaving room for detecting EOF without growing
+	buf := &bytes.Buffer{}
+	if maxSize > 0 {
+		sizeHint = min(sizeHint, maxSize)
+	} else {
+		sizeHint = min(sizeHint, MaxUnlimitedSizeHint)
+	}
+	sizeHint = min(sizeHint, math.MaxInt-bytes.MinRead) // do not overflow int
+	if sizeHint > 0 {
+		buf.Grow(int(sizeHint) + bytes.MinRead)
+	}
+
+	// 2. read one byte more than the limit, to detect exceeding it, forwarding
+	// the ownership of rc, which the limiter would otherwise hide
+	ownership := ownershipOf(rc, Borrowed)
+	if maxSize > 0 {
+		rc = LimitReadCloser(rc, maxSize+1)
+	}
+	_, err := CopyContext(ctx, NewLockedWriteCloser(NopWriteCloser(buf)), rc, WithOwnership(ownership))
+
+	// 3. check whether we exceeded the limit
+	data := buf.Bytes()
+	if maxSize > 0 && int64(len(data)) > maxSize {
+		data = data[:maxSize]
+		if err == nil {
+			err = ErrReadLimitExceeded
+		}
+	}
+	return data, err
+}
+
 // NopWriteCloser wraps an [io.Writer] and returns a no-op [io.WriteCloser].
 //
 // This is useful when [CopyContext] needs to stream into a writer that does not
 // require closing, such as a [*bytes.Buffer].
+//
+// If w implements [io.ReaderFrom], the returned [io.WriteCloser] implements
+// [io.ReaderFrom] as well, forwarding calls to w.
 func NopWriteCloser(w io.Writer) io.WriteCloser {
+	if _, ok := w.(io.ReaderFrom); ok {
+		return nopWriteCloserReaderFrom{nopWriteCloser{w}}
+	}
 	return nopWriteCloser{w}
 }
 
@@ -155,10 +566,66 @@ func (w nopWriteCloser) Close() error {
 	return nil
 }
 
+// Unwrap returns the wrapped [io.Writer].
+func (w nopWriteCloser) Unwrap() io.Writer {
+	return w.Writer
+}
+
+// nopWriteCloserReaderFrom is a [nopWriteCloser] forwarding [io.ReaderFrom].
+type nopWriteCloserReaderFrom struct {
+	nopWriteCloser
+}
+
+// ReadFrom implements [io.ReaderFrom].
+func (w nopWriteCloserReaderFrom) ReadFrom(r io.Reader) (int64, error) {
+	return w.Writer.(io.ReaderFrom).ReadFrom(r)
+}
+
 // LimitReadCloser wraps rc such that reads are limited to n bytes
 // while Close forwards to the underlying rc.
+//
+// When rc implements [io.Seeker], the returned reader is a [SeekReadCloser]
+// exposing the n bytes starting at the current offset of rc, like an
+// [*io.SectionReader], hence seek offsets are relative to that offset.
 func LimitReadCloser(rc io.ReadCloser, n int64) io.ReadCloser {
-	return readCloser{io.LimitReader(rc, n), rc}
+	if src, ok := rc.(SeekReadCloser); ok {
+		if lsrc := newLimitedSeekReadCloser(src, n); lsrc != nil {
+			return lsrc
+		}
+	}
+	return JoinReadCloser(io.LimitReader(rc, n), rc)
+}
+
+// NopReadCloser wraps an [io.Reader] and returns a no-op [io.ReadCloser].
+//
+// This is the reader-side counterpart of [NopWriteCloser] and is useful to pass
+// readers that do not require closing, such as a [*strings.Reader], to [CopyContext].
+//
+// If r implements [io.WriterTo], the returned [io.ReadCloser] implements
+// [io.WriterTo] as well, forwarding calls to r. If r implements [io.Seeker],
+// the returned [io.ReadCloser] is a [SeekReadCloser].
+func NopReadCloser(r io.Reader) io.ReadCloser {
+	rs, ok := r.(io.ReadSeeker)
+	if !ok {
+		return io.NopCloser(r)
+	}
+	if _, ok := r.(io.WriterTo); ok {
+		return nopReadSeekCloserWriterTo{nopReadSeekCloser{rs}}
+	}
+	return nopReadSeekCloser{rs}
+}
+
+// JoinReadCloser returns an [io.ReadCloser] that reads from r and whose
+// Close forwards to c.
+//
+// This is useful to wrap the reader side of an [io.ReadCloser] while
+// still being able to close the original [io.ReadCloser]. If r implements
+// [io.Seeker], the returned [io.ReadCloser] is a [SeekReadCloser].
+func JoinReadCloser(r io.Reader, c io.Closer) io.ReadCloser {
+	if _, ok := r.(io.Seeker); ok {
+		return readSeekCloser{readCloser{r, c}}
+	}
+	return readCloser{r, c}
 }
 
 // readCloser adapts an [io.Reader] plus an [io.Closer] to an [io.ReadCloser].
@@ -166,3 +633,33 @@ type readCloser struct {
 	io.Reader
 	io.Closer
 }
+
+// Unwrap returns the wrapped [io.Reader].
+func (r readCloser) Unwrap() io.Reader {
+	return r.Reader
+}
+
+// ReaderFunc adapts a func to be an [io.Reader].
+type ReaderFunc func(buf []byte) (int, error)
+
+// Read implements [io.Reader].
+func (f ReaderFunc) Read(buf []byte) (int, error) {
+	return f(buf)
+}
+
+// CloserFunc adapts a func to be an [io.Closer].
+type CloserFunc func() error
+
+// Close implements [io.Closer].
+func (f CloserFunc) Close() error {
+	return f()
+}
+
+// ReadCloserFunc returns an [io.ReadCloser] whose Read calls readFunc and whose
+// Close calls closeFunc. A nil closeFunc makes Close a no-op.
+func ReadCloserFunc(readFunc func(buf []byte) (int, error), closeFunc func() error) io.ReadCloser {
+	if closeFunc == nil {
+		closeFunc = func() error { return nil }
+	}
+	return JoinReadCloser(ReaderFunc(readFunc), CloserFunc(closeFunc))
+}
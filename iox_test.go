@@ -3,12 +3,17 @@
 package iox
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"io"
+	"net"
+	"net/http/httptest"
 	"strings"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/bassosimone/iotest"
 	"github.com/stretchr/testify/assert"
@@ -144,6 +149,385 @@ func TestReadAllContextSuccess(t *testing.T) {
 	assert.False(t, closeCalled.Load())
 }
 
+func TestReadAllLimitContext(t *testing.T) {
+	t.Run("within the limit", func(t *testing.T) {
+		data, err := ReadAllLimitContext(context.Background(),
+			NopReadCloser(strings.NewReader("hello")), 5, 0)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(data))
+	})
+
+	t.Run("exceeding the limit", func(t *testing.T) {
+		reader := strings.NewReader(strings.Repeat("x", 1<<20))
+		data, err := ReadAllLimitContext(context.Background(), NopReadCloser(reader), 4, 0)
+		require.ErrorIs(t, err, ErrReadLimitExceeded)
+		assert.Equal(t, "xxxx", string(data))
+		assert.Equal(t, int64(1<<20-5), int64(reader.Len()))
+	})
+
+	t.Run("nonpositive limits disable the check", func(t *testing.T) {
+		data, err := ReadAllLimitContext(context.Background(),
+			NopReadCloser(strings.NewReader("hello")), 0, 0)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(data))
+	})
+
+	t.Run("the hint preallocates the result", func(t *testing.T) {
+		payload := strings.Repeat("x", 100000)
+		data, err := ReadAllLimitContext(context.Background(),
+			NopReadCloser(strings.NewReader(payload)), 0, int64(len(payload)))
+		require.NoError(t, err)
+		assert.Equal(t, payload, string(data))
+		assert.GreaterOrEqual(t, cap(data), len(payload)+bytes.MinRead)
+		assert.Less(t, cap(data), 2*len(payload)) // no reallocation
+	})
+
+	t.Run("the hint is capped to the limit", func(t *testing.T) {
+		data, err := ReadAllLimitContext(context.Background(),
+			NopReadCloser(strings.NewReader("hello")), 16, 1<<40)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(data))
+		assert.Less(t, cap(data), 1024)
+	})
+
+	t.Run("the hint is capped without a limit", func(t *testing.T) {
+		var data []byte
+		require.NotPanics(t, func() {
+			var err error
+			data, err = ReadAllLimitContext(context.Background(),
+				NopReadCloser(strings.NewReader("hello")), 0, 1<<62)
+			require.NoError(t, err)
+		})
+		assert.Equal(t, "hello", string(data))
+		assert.LessOrEqual(t, cap(data), 2*(MaxUnlimitedSizeHint+bytes.MinRead))
+	})
+
+	t.Run("BorrowedReadCloser is not closed on cancel", func(t *testing.T) {
+		unblock := make(chan struct{})
+		defer close(unblock)
+		closed := &atomic.Int64{}
+		rc := &iotest.FuncReadCloser{
+			ReadFunc: func(buf []byte) (int, error) {
+				<-unblock
+				return 0, io.EOF
+			},
+			CloseFunc: func() error {
+				closed.Add(1)
+				return nil
+			},
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := ReadAllLimitContext(ctx, NewBorrowedReadCloser(rc), 16, 0)
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, int64(0), closed.Load())
+	})
+
+	t.Run("errors take precedence over the limit", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := ReadAllLimitContext(ctx, NopReadCloser(ZeroReader()), 4, 0)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestCopyContextWithCancelCause(t *testing.T) {
+	cause := errors.New("shutting down")
+
+	t.Run("CopyContext", func(t *testing.T) {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(cause)
+		lwc := NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{}))
+		_, err := CopyContext(ctx, lwc, NopReadCloser(ZeroReader()))
+		require.ErrorIs(t, err, context.Canceled)
+		require.ErrorIs(t, err, cause)
+		assert.Equal(t, "context canceled: shutting down", err.Error())
+	})
+
+	t.Run("ReadAllContext", func(t *testing.T) {
+		ctx, cancel := context.WithDeadlineCause(context.Background(), time.Now(), cause)
+		defer cancel()
+		_, err := ReadAllContext(ctx, NopReadCloser(ZeroReader()))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.ErrorIs(t, err, cause)
+	})
+
+	t.Run("without a cause", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		lwc := NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{}))
+		_, err := CopyContext(ctx, lwc, NopReadCloser(ZeroReader()))
+		assert.Same(t, context.Canceled, err)
+	})
+}
+
+func TestCopyContextWithMaxDuration(t *testing.T) {
+	t.Run("the copy takes too long", func(t *testing.T) {
+		unblock := make(chan struct{})
+		rc := &iotest.FuncReadCloser{
+			ReadFunc: func(b []byte) (int, error) {
+				<-unblock
+				return 0, io.ErrClosedPipe
+			},
+			CloseFunc: func() error {
+				close(unblock)
+				return nil
+			},
+		}
+		lwc := NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{}))
+		_, err := CopyContext(context.Background(), lwc, rc, WithMaxDuration(10*time.Millisecond))
+		require.ErrorIs(t, err, ErrCopyTimeout)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.True(t, IsTimeout(err))
+	})
+
+	t.Run("the caller's context is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		lwc := NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{}))
+		_, err := CopyContext(ctx, lwc, NopReadCloser(ZeroReader()), WithMaxDuration(time.Hour))
+		require.ErrorIs(t, err, context.Canceled)
+		assert.NotErrorIs(t, err, ErrCopyTimeout)
+	})
+
+	t.Run("the copy completes in time", func(t *testing.T) {
+		var buf bytes.Buffer
+		lwc := NewLockedWriteCloser(NopWriteCloser(&buf))
+		count, err := CopyContext(context.Background(), lwc,
+			NopReadCloser(strings.NewReader("iox")), WithMaxDuration(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.Equal(t, "iox", buf.String())
+	})
+}
+
+func TestLockedWriteCloserStats(t *testing.T) {
+	t.Run("without recording sizes", func(t *testing.T) {
+		lwc := NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{}))
+		_, err := lwc.LockedWrite([]byte("hello"))
+		require.NoError(t, err)
+		stats := lwc.Stats()
+		assert.Equal(t, IOStats{Bytes: 5, Calls: 1}, stats)
+	})
+
+	t.Run("recording sizes", func(t *testing.T) {
+		lwc := NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{}))
+		lwc.RecordSizes = true
+		_, err := lwc.LockedWrite(make([]byte, 32768))
+		require.NoError(t, err)
+		_, err = lwc.LockedWriteString("hello")
+		require.NoError(t, err)
+		require.NoError(t, lwc.LockedWriteByte('x'))
+		_, err = lwc.LockedReadFrom(strings.NewReader("world"))
+		require.NoError(t, err)
+
+		stats := lwc.Stats()
+		assert.Equal(t, int64(32768+5+1+5), stats.Bytes)
+		assert.Equal(t, int64(4), stats.Calls)
+		require.NotNil(t, stats.Sizes)
+		assert.Equal(t, []SizeBucket{
+			{Min: 1, Max: 1, Count: 1},
+			{Min: 4, Max: 7, Count: 2},
+			{Min: 32768, Max: 65535, Count: 1},
+		}, stats.Sizes.Buckets())
+	})
+}
+
+func TestLockedWriteBuffers(t *testing.T) {
+	t.Run("concatenates into a single write", func(t *testing.T) {
+		var writes []string
+		lwc := NewLockedWriteCloser(&iotest.FuncWriteCloser{
+			WriteFunc: func(b []byte) (int, error) {
+				writes = append(writes, string(b))
+				return len(b), nil
+			},
+			CloseFunc: func() error { return nil },
+		})
+		count, err := lwc.LockedWriteBuffers(net.Buffers{[]byte("hea"), []byte("der"), []byte("payload")})
+		require.NoError(t, err)
+		assert.Equal(t, int64(13), count)
+		assert.Equal(t, []string{"headerpayload"}, writes)
+		assert.Equal(t, 13, lwc.Count())
+	})
+
+	t.Run("uses net.Buffers with connections", func(t *testing.T) {
+		client, server := newTCPConnPair(t)
+		received := make(chan []byte)
+		go func() {
+			data, _ := io.ReadAll(server)
+			received <- data
+		}()
+		lwc := NewLockedWriteCloser(HalfCloseWriter(client))
+		bufs := net.Buffers{[]byte("header"), []byte("payload")}
+		count, err := lwc.LockedWriteBuffers(bufs)
+		require.NoError(t, err)
+		assert.Equal(t, int64(13), count)
+		assert.Equal(t, net.Buffers{[]byte("header"), []byte("payload")}, bufs)
+		require.NoError(t, lwc.Close())
+		assert.Equal(t, "headerpayload", string(<-received))
+		assert.Equal(t, 13, lwc.Count())
+	})
+
+	t.Run("concatenates with connections not supporting writev", func(t *testing.T) {
+		client, server := net.Pipe()
+		defer server.Close()
+		received := make(chan []string)
+		go func() {
+			var reads []string
+			buff := make([]byte, 64)
+			for {
+				count, err := server.Read(buff)
+				if err != nil {
+					received <- reads
+					return
+				}
+				reads = append(reads, string(buff[:count]))
+			}
+		}()
+		lwc := NewLockedWriteCloser(client)
+		count, err := lwc.LockedWriteBuffers(net.Buffers{[]byte("header"), []byte("payload")})
+		require.NoError(t, err)
+		assert.Equal(t, int64(13), count)
+		require.NoError(t, lwc.Close())
+		assert.Equal(t, []string{"headerpayload"}, <-received)
+	})
+
+	t.Run("closed writer", func(t *testing.T) {
+		lwc := NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{}))
+		require.NoError(t, lwc.Close())
+		_, err := lwc.LockedWriteBuffers(net.Buffers{[]byte("x")})
+		assert.ErrorIs(t, err, ErrClosed)
+	})
+}
+
+func TestLockedWriteCloserSwapWriter(t *testing.T) {
+	t.Run("writes continue into the new writer", func(t *testing.T) {
+		var first, second bytes.Buffer
+		oldClosed := &atomic.Bool{}
+		lwc := NewLockedWriteCloser(&iotest.FuncWriteCloser{
+			WriteFunc: first.Write,
+			CloseFunc: func() error {
+				oldClosed.Store(true)
+				return nil
+			},
+		})
+		_, err := lwc.LockedWrite([]byte("before"))
+		require.NoError(t, err)
+
+		require.NoError(t, lwc.SwapWriter(NopWriteCloser(&second), true))
+		assert.True(t, oldClosed.Load())
+		found, ok := As[*bytes.Buffer](lwc.Unwrap())
+		require.True(t, ok)
+		assert.Same(t, &second, found)
+
+		_, err = lwc.LockedWrite([]byte("after"))
+		require.NoError(t, err)
+		assert.Equal(t, "before", first.String())
+		assert.Equal(t, "after", second.String())
+		assert.Equal(t, 11, lwc.Count())
+	})
+
+	t.Run("the old writer is left open unless requested", func(t *testing.T) {
+		old := &iotest.FuncWriteCloser{
+			WriteFunc: func(b []byte) (int, error) { return len(b), nil },
+			CloseFunc: func() error {
+				t.Fatal("should not be called")
+				return nil
+			},
+		}
+		lwc := NewLockedWriteCloser(old)
+		require.NoError(t, lwc.SwapWriter(NopWriteCloser(io.Discard), false))
+	})
+
+	t.Run("close errors are reported after swapping", func(t *testing.T) {
+		expected := errors.New("mocked close error")
+		lwc := NewLockedWriteCloser(&iotest.FuncWriteCloser{
+			CloseFunc: func() error { return expected },
+		})
+		var buf bytes.Buffer
+		require.ErrorIs(t, lwc.SwapWriter(NopWriteCloser(&buf), true), expected)
+		_, err := lwc.LockedWrite([]byte("x"))
+		require.NoError(t, err)
+		assert.Equal(t, "x", buf.String())
+	})
+
+	t.Run("closed writer", func(t *testing.T) {
+		lwc := NewLockedWriteCloser(NopWriteCloser(io.Discard))
+		require.NoError(t, lwc.Close())
+		assert.ErrorIs(t, lwc.SwapWriter(NopWriteCloser(io.Discard), true), ErrClosed)
+	})
+}
+
+func TestLockedWriteCloserStickyErrors(t *testing.T) {
+	t.Run("the first write error is latched", func(t *testing.T) {
+		first := errors.New("first error")
+		var calls atomic.Int64
+		lwc := NewLockedWriteCloser(&iotest.FuncWriteCloser{
+			WriteFunc: func(b []byte) (int, error) {
+				if calls.Add(1) == 1 {
+					return 1, first
+				}
+				return 0, errors.New("another error")
+			},
+			CloseFunc: func() error { return nil },
+		})
+		lwc.StickyErrors = true
+		require.NoError(t, lwc.Err())
+
+		count, err := lwc.LockedWrite([]byte("abc"))
+		require.ErrorIs(t, err, first)
+		assert.Equal(t, 1, count)
+		assert.Same(t, first, lwc.Err())
+
+		count, err = lwc.LockedWrite([]byte("abc"))
+		assert.Same(t, first, err)
+		assert.Equal(t, 0, count)
+		_, err = lwc.LockedWriteString("abc")
+		assert.Same(t, first, err)
+		assert.Same(t, first, lwc.LockedWriteByte('a'))
+		_, err = lwc.LockedWriteBuffers(net.Buffers{[]byte("abc")})
+		assert.Same(t, first, err)
+		assert.Same(t, first, lwc.LockedFlush())
+		_, err = lwc.LockedReadFrom(strings.NewReader("abc"))
+		assert.ErrorIs(t, err, first)
+
+		assert.Equal(t, int64(1), calls.Load())
+		assert.Equal(t, 1, lwc.Count())
+		require.NoError(t, lwc.Close())
+		assert.ErrorIs(t, lwc.Close(), ErrClosed)
+	})
+
+	t.Run("read errors are not latched", func(t *testing.T) {
+		expected := errors.New("mocked read error")
+		var buf bytes.Buffer
+		lwc := NewLockedWriteCloser(NopWriteCloser(&buf))
+		lwc.StickyErrors = true
+		_, err := lwc.LockedReadFrom(&iotest.FuncReadCloser{
+			ReadFunc: func(b []byte) (int, error) { return 0, expected },
+		})
+		require.ErrorIs(t, err, expected)
+		require.NoError(t, lwc.Err())
+		_, err = lwc.LockedWrite([]byte("abc"))
+		require.NoError(t, err)
+		assert.Equal(t, "abc", buf.String())
+	})
+
+	t.Run("errors are not latched by default", func(t *testing.T) {
+		expected := errors.New("mocked write error")
+		var calls atomic.Int64
+		lwc := NewLockedWriteCloser(&iotest.FuncWriteCloser{
+			WriteFunc: func(b []byte) (int, error) {
+				calls.Add(1)
+				return 0, expected
+			},
+		})
+		lwc.LockedWrite([]byte("abc"))
+		lwc.LockedWrite([]byte("abc"))
+		assert.Equal(t, int64(2), calls.Load())
+		assert.NoError(t, lwc.Err())
+	})
+}
+
 func TestLimitReadCloser(t *testing.T) {
 	// Limit reads while keeping the close behavior of the wrapped reader.
 	payload := "iox-extra"
@@ -165,3 +549,270 @@ func TestLimitReadCloser(t *testing.T) {
 	require.NoError(t, err)
 	assert.True(t, closed.Load())
 }
+
+// writerOnly hides the optional interfaces of an [io.Writer].
+type writerOnly struct {
+	io.Writer
+}
+
+// readerFromBuffer is a [*bytes.Buffer] recording calls to ReadFrom.
+type readerFromBuffer struct {
+	bytes.Buffer
+	calls atomic.Int64
+}
+
+// ReadFrom implements [io.ReaderFrom].
+func (b *readerFromBuffer) ReadFrom(r io.Reader) (int64, error) {
+	b.calls.Add(1)
+	return b.Buffer.ReadFrom(r)
+}
+
+func TestLockedReadFrom(t *testing.T) {
+	t.Run("with a writer implementing io.ReaderFrom", func(t *testing.T) {
+		// Without a zero-copy pair, we must not hold the lock across reads,
+		// hence we must not forward to the writer's ReadFrom.
+		buff := &readerFromBuffer{}
+		lwc := NewLockedWriteCloser(NopWriteCloser(buff))
+
+		count, err := lwc.LockedReadFrom(strings.NewReader("iox"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+		assert.Equal(t, 3, lwc.Count())
+		assert.Equal(t, "iox", buff.String())
+		assert.Equal(t, int64(0), buff.calls.Load())
+
+		// Once closed, we must not write anymore.
+		require.NoError(t, lwc.Close())
+		count, err = lwc.LockedReadFrom(strings.NewReader("iox"))
+		require.ErrorIs(t, err, ErrClosed)
+		assert.Equal(t, int64(0), count)
+		assert.Equal(t, "iox", buff.String())
+	})
+
+	t.Run("with a writer not implementing io.ReaderFrom", func(t *testing.T) {
+		buff := &bytes.Buffer{}
+		lwc := NewLockedWriteCloser(&iotest.FuncWriteCloser{
+			WriteFunc: buff.Write,
+			CloseFunc: func() error { return nil },
+		})
+
+		count, err := lwc.LockedReadFrom(strings.NewReader("iox"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+		assert.Equal(t, 3, lwc.Count())
+		assert.Equal(t, "iox", buff.String())
+	})
+
+	t.Run("does not hold the lock while reading", func(t *testing.T) {
+		pr, pw := io.Pipe()
+		lwc := NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{}))
+		done := make(chan error, 1)
+		go func() {
+			_, err := lwc.LockedReadFrom(pr)
+			done <- err
+		}()
+		_, err := pw.Write([]byte("iox"))
+		require.NoError(t, err)
+
+		// The read is now blocked, yet we can observe the count and close.
+		require.Eventually(t, func() bool { return lwc.Count() == 3 }, time.Second, time.Millisecond)
+		require.NoError(t, lwc.Close())
+		pw.Close()
+		require.NoError(t, <-done)
+	})
+}
+
+func TestCopyContextWithStuckReadAndReaderFromDestination(t *testing.T) {
+	// A destination implementing io.ReaderFrom must not cause CopyContext to
+	// hold the lock across reads, otherwise, on cancellation, closing the writer
+	// would wait for a Read that closing the reader does not unblock.
+	unblock := make(chan struct{})
+	defer close(unblock)
+	rc := &iotest.FuncReadCloser{
+		ReadFunc: func(b []byte) (int, error) {
+			<-unblock
+			return 0, io.EOF
+		},
+		CloseFunc: func() error { return nil },
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	buff := &readerFromBuffer{}
+	_, err := CopyContext(ctx, NewLockedWriteCloser(NopWriteCloser(buff)), rc)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int64(0), buff.calls.Load())
+
+	// The adapter used by CopyContext does not implement io.ReaderFrom, hence
+	// io.CopyBuffer does not retry the zero-copy fast path through LockedReadFrom.
+	_, ok := io.Writer(writerAdapter{}).(io.ReaderFrom)
+	assert.False(t, ok)
+}
+
+func TestNopWriteCloser(t *testing.T) {
+	// The io.ReaderFrom implementation is only exposed when available.
+	_, ok := NopWriteCloser(&bytes.Buffer{}).(io.ReaderFrom)
+	assert.True(t, ok)
+	_, ok = NopWriteCloser(writerOnly{&bytes.Buffer{}}).(io.ReaderFrom)
+	assert.False(t, ok)
+}
+
+func TestNopReadCloser(t *testing.T) {
+	rc := NopReadCloser(strings.NewReader("iox"))
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "iox", string(data))
+	require.NoError(t, rc.Close())
+
+	// The io.WriterTo implementation is only exposed when available.
+	_, ok := NopReadCloser(strings.NewReader("iox")).(io.WriterTo)
+	assert.True(t, ok)
+	_, ok = NopReadCloser(ReaderFunc(strings.NewReader("iox").Read)).(io.WriterTo)
+	assert.False(t, ok)
+}
+
+func TestJoinReadCloser(t *testing.T) {
+	closed := &atomic.Bool{}
+	rc := JoinReadCloser(strings.NewReader("iox"), CloserFunc(func() error {
+		closed.Store(true)
+		return nil
+	}))
+
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "iox", string(data))
+	require.NoError(t, rc.Close())
+	assert.True(t, closed.Load())
+}
+
+func TestReadCloserFunc(t *testing.T) {
+	t.Run("with a close func", func(t *testing.T) {
+		closed := &atomic.Bool{}
+		rc := ReadCloserFunc(strings.NewReader("iox").Read, func() error {
+			closed.Store(true)
+			return nil
+		})
+
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		assert.Equal(t, "iox", string(data))
+		require.NoError(t, rc.Close())
+		assert.True(t, closed.Load())
+	})
+
+	t.Run("without a close func", func(t *testing.T) {
+		rc := ReadCloserFunc(strings.NewReader("iox").Read, nil)
+		require.NoError(t, rc.Close())
+	})
+}
+
+func TestLockedFlush(t *testing.T) {
+	t.Run("with a writer whose Flush returns an error", func(t *testing.T) {
+		buff := &bytes.Buffer{}
+		bw := bufio.NewWriter(buff)
+		lwc := NewLockedWriteCloser(NopWriteCloser(bw))
+
+		_, err := lwc.LockedWrite([]byte("iox"))
+		require.NoError(t, err)
+		assert.Equal(t, 0, buff.Len())
+
+		require.NoError(t, lwc.LockedFlush())
+		assert.Equal(t, "iox", buff.String())
+	})
+
+	t.Run("with a writer implementing http.Flusher", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		lwc := NewLockedWriteCloser(NopWriteCloser(rr))
+		require.NoError(t, lwc.LockedFlush())
+		assert.True(t, rr.Flushed)
+	})
+
+	t.Run("with a writer that cannot flush", func(t *testing.T) {
+		lwc := NewLockedWriteCloser(NopWriteCloser(writerOnly{&bytes.Buffer{}}))
+		require.NoError(t, lwc.LockedFlush())
+	})
+
+	t.Run("once closed", func(t *testing.T) {
+		lwc := NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{}))
+		require.NoError(t, lwc.Close())
+		require.ErrorIs(t, lwc.LockedFlush(), ErrClosed)
+	})
+}
+
+func TestCopyContextWithFlushEachWrite(t *testing.T) {
+	// Count the flushes while copying using small reads.
+	const payload = "hello from iox"
+	sr := strings.NewReader(payload)
+	rc := &iotest.FuncReadCloser{
+		ReadFunc: func(b []byte) (int, error) {
+			return sr.Read(b[:min(len(b), 5)])
+		},
+		CloseFunc: func() error { return nil },
+	}
+	buff := &bytes.Buffer{}
+	flushes := 0
+	fw := &flushRecorder{Writer: buff, flushes: &flushes}
+
+	count, err := CopyContext(context.Background(), NewLockedWriteCloser(NopWriteCloser(fw)), rc, WithFlushEachWrite())
+	require.NoError(t, err)
+	assert.Equal(t, len(payload), count)
+	assert.Equal(t, payload, buff.String())
+	assert.Equal(t, 3, flushes)
+}
+
+// flushRecorder is an [io.Writer] counting the calls to Flush.
+type flushRecorder struct {
+	io.Writer
+	flushes *int
+}
+
+// Flush implements [Flusher].
+func (fr *flushRecorder) Flush() error {
+	*fr.flushes++
+	return nil
+}
+
+// stringWriterRecorder is an [io.Writer] counting the calls to WriteString.
+type stringWriterRecorder struct {
+	strings.Builder
+	calls int
+}
+
+// WriteString implements [io.StringWriter].
+func (sw *stringWriterRecorder) WriteString(s string) (int, error) {
+	sw.calls++
+	return sw.Builder.WriteString(s)
+}
+
+func TestLockedWriteString(t *testing.T) {
+	t.Run("with a writer implementing io.StringWriter", func(t *testing.T) {
+		sw := &stringWriterRecorder{}
+		lwc := NewLockedWriteCloser(NopWriteCloser(sw))
+
+		// Also make sure the adapter forwards WriteString.
+		count, err := io.WriteString(writerAdapter{lwc}, "iox")
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.Equal(t, 3, lwc.Count())
+		assert.Equal(t, "iox", sw.String())
+		assert.Equal(t, 1, sw.calls)
+	})
+
+	t.Run("with a writer not implementing io.StringWriter", func(t *testing.T) {
+		buff := &bytes.Buffer{}
+		lwc := NewLockedWriteCloser(NopWriteCloser(writerOnly{buff}))
+
+		count, err := lwc.LockedWriteString("iox")
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.Equal(t, "iox", buff.String())
+	})
+
+	t.Run("once closed", func(t *testing.T) {
+		lwc := NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{}))
+		require.NoError(t, lwc.Close())
+		count, err := lwc.LockedWriteString("iox")
+		require.ErrorIs(t, err, ErrClosed)
+		assert.Equal(t, 0, count)
+	})
+}
@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"io"
+	"runtime/debug"
+	"sync"
+)
+
+// TB is the subset of [testing.TB] used by [VerifyNoLeaks].
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+var (
+	// trackedMu protects tracked.
+	trackedMu sync.Mutex
+
+	// tracked maps the tracked closers not closed yet to their creation stack.
+	tracked = map[*trackedCloser][]byte{}
+)
+
+// TrackCloser registers c for leak detection and returns a wrapper to use instead of c.
+//
+// It records the current stack trace, which [VerifyNoLeaks] reports if the returned
+// wrapper has not been closed yet. Because the registry is global, leak detection
+// is opt-in and is meant to be used in tests.
+func TrackCloser(c io.Closer) io.Closer {
+	tc := &trackedCloser{c}
+	trackedMu.Lock()
+	tracked[tc] = debug.Stack()
+	trackedMu.Unlock()
+	return tc
+}
+
+// TrackReadCloser is like [TrackCloser] but for an [io.ReadCloser].
+func TrackReadCloser(rc io.ReadCloser) io.ReadCloser {
+	return JoinReadCloser(rc, TrackCloser(rc))
+}
+
+// TrackWriteCloser is like [TrackCloser] but for an [io.WriteCloser].
+func TrackWriteCloser(wc io.WriteCloser) io.WriteCloser {
+	return writeCloser{wc, TrackCloser(wc)}
+}
+
+// writeCloser adapts an [io.Writer] plus an [io.Closer] to an [io.WriteCloser].
+type writeCloser struct {
+	io.Writer
+	io.Closer
+}
+
+// Unwrap returns the wrapped [io.Writer].
+func (w writeCloser) Unwrap() io.Writer {
+	return w.Writer
+}
+
+// trackedCloser is the [io.Closer] returned by [TrackCloser].
+type trackedCloser struct {
+	c io.Closer
+}
+
+// Close implements [io.Closer].
+func (tc *trackedCloser) Close() error {
+	trackedMu.Lock()
+	delete(tracked, tc)
+	trackedMu.Unlock()
+	return tc.c.Close()
+}
+
+// VerifyNoLeaks reports an error for each tracked closer not closed yet, including
+// the stack trace recorded when it was tracked, and then stops tracking them.
+//
+// Since the registry is global, tests using VerifyNoLeaks should not run in parallel.
+func VerifyNoLeaks(t TB) {
+	t.Helper()
+	trackedMu.Lock()
+	leaked := tracked
+	tracked = map[*trackedCloser][]byte{}
+	trackedMu.Unlock()
+	for _, stack := range leaked {
+		t.Errorf("iox: closer not closed, created at:\n%s", stack)
+	}
+}
@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingTB is a [TB] recording the reported errors.
+type recordingTB struct {
+	errors []string
+}
+
+// Helper implements [TB].
+func (tb *recordingTB) Helper() {}
+
+// Errorf implements [TB].
+func (tb *recordingTB) Errorf(format string, args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func TestVerifyNoLeaks(t *testing.T) {
+	// Track three closers and only close one of them.
+	rc := TrackReadCloser(NopReadCloser(strings.NewReader("iox")))
+	wc := TrackWriteCloser(NopWriteCloser(&bytes.Buffer{}))
+	_ = TrackCloser(CloserFunc(func() error { return nil }))
+	require.NoError(t, rc.Close())
+
+	// We should see the two leaked closers and where they were created.
+	tb := &recordingTB{}
+	VerifyNoLeaks(tb)
+	require.Len(t, tb.errors, 2)
+	for _, msg := range tb.errors {
+		assert.Contains(t, msg, "TestVerifyNoLeaks")
+	}
+
+	// Once reported, closers are not tracked anymore.
+	require.NoError(t, wc.Close())
+	tb = &recordingTB{}
+	VerifyNoLeaks(tb)
+	assert.Empty(t, tb.errors)
+	VerifyNoLeaks(t)
+}
@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"iter"
+)
+
+// LinesContext returns an iterator over the lines read from rc.
+//
+// Each line is yielded without the trailing end-of-line marker ("\n" or "\r\n")
+// and with a nil error. On failure, the iterator yields an empty line along
+// with the error and stops. Lines longer than maxLineSize bytes, not counting
+// the end-of-line marker, cause the iterator to fail with [bufio.ErrTooLong].
+// Nonpositive values of maxLineSize mean [bufio.MaxScanTokenSize]. See also
+// [ScannerContext].
+//
+// Reads are context-interruptible: on context cancellation, rc is closed to
+// unblock any in-flight Read and the iterator fails with the context error.
+// Otherwise, rc is NOT closed and the caller MUST close it (e.g., via defer).
+func LinesContext(ctx context.Context, rc io.ReadCloser, maxLineSize int) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		// 1. make room for the line and for its "\r\n" end-of-line marker
+		if maxLineSize <= 0 {
+			maxLineSize = bufio.MaxScanTokenSize
+		}
+		scanner := NewScannerContext(ctx, rc)
+		scanner.Buffer(nil, maxLineSize+2)
+
+		// 2. yield each line enforcing the limit on the line itself
+		for scanner.Scan() {
+			if len(scanner.Bytes()) > maxLineSize {
+				yield("", bufio.ErrTooLong)
+				return
+			}
+			if !yield(scanner.Text(), nil) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield("", err)
+		}
+	}
+}
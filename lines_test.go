@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinesContextSuccess(t *testing.T) {
+	rc := NopReadCloser(strings.NewReader("data: 1\r\ndata: 2\n\ndata: 3"))
+	var lines []string
+	for line, err := range LinesContext(context.Background(), rc, 1024) {
+		require.NoError(t, err)
+		lines = append(lines, line)
+	}
+	assert.Equal(t, []string{"data: 1", "data: 2", "", "data: 3"}, lines)
+}
+
+func TestLinesContextWithLineTooLong(t *testing.T) {
+	rc := NopReadCloser(strings.NewReader("short\n" + strings.Repeat("x", 64) + "\n"))
+	var (
+		lines []string
+		errs  []error
+	)
+	for line, err := range LinesContext(context.Background(), rc, 16) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		lines = append(lines, line)
+	}
+	assert.Equal(t, []string{"short"}, lines)
+	require.Len(t, errs, 1)
+	require.ErrorIs(t, errs[0], bufio.ErrTooLong)
+}
+
+func TestLinesContextLineSizeLimit(t *testing.T) {
+	// collect returns the lines, or the error, read with the given limit.
+	collect := func(input string, maxLineSize int) ([]string, error) {
+		var lines []string
+		for line, err := range LinesContext(context.Background(), NopReadCloser(strings.NewReader(input)), maxLineSize) {
+			if err != nil {
+				return lines, err
+			}
+			lines = append(lines, line)
+		}
+		return lines, nil
+	}
+
+	t.Run("lines of exactly maxLineSize bytes are accepted", func(t *testing.T) {
+		for _, input := range []string{"abcd\n", "abcd\r\n", "abcd"} {
+			lines, err := collect(input, 4)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"abcd"}, lines)
+		}
+	})
+
+	t.Run("lines of maxLineSize+1 bytes are rejected", func(t *testing.T) {
+		for _, input := range []string{"abcde\n", "abcde\r\n", "abcde"} {
+			_, err := collect(input, 4)
+			require.ErrorIs(t, err, bufio.ErrTooLong)
+		}
+	})
+
+	t.Run("nonpositive values mean bufio.MaxScanTokenSize", func(t *testing.T) {
+		line := strings.Repeat("x", bufio.MaxScanTokenSize)
+		lines, err := collect(line+"\n", 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{line}, lines)
+		_, err = collect(line+"x\n", -1)
+		require.ErrorIs(t, err, bufio.ErrTooLong)
+	})
+}
+
+func TestLinesContextWithCancelledContext(t *testing.T) {
+	// Create a reader returning a line and then blocking until Close is called.
+	unblockReader := make(chan struct{})
+	first := true
+	rc := &iotest.FuncReadCloser{
+		ReadFunc: func(b []byte) (int, error) {
+			if first {
+				first = false
+				return copy(b, "event\n"), nil
+			}
+			<-unblockReader
+			return 0, io.EOF
+		},
+		CloseFunc: func() error {
+			close(unblockReader)
+			return nil
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var lines []string
+	for line, err := range LinesContext(ctx, rc, 1024) {
+		if err != nil {
+			require.ErrorIs(t, err, context.Canceled)
+			break
+		}
+		lines = append(lines, line)
+
+		// Cancel once we have received the first line.
+		cancel()
+	}
+	assert.Equal(t, []string{"event"}, lines)
+}
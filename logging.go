@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// LoggingReadCloser is an [io.ReadCloser] emitting a structured log event per operation.
+//
+// Each Read and Close emits an event whose message is the operation name ("read"
+// or "close") with the number of bytes, the error, the duration, and optionally a
+// prefix of the payload as attributes.
+//
+// Construct using [NewLoggingReadCloser].
+type LoggingReadCloser struct {
+	// Level is the level of the emitted events.
+	//
+	// [NewLoggingReadCloser] sets it to [slog.LevelDebug]. You MUST NOT
+	// modify it after you started reading.
+	Level slog.Level
+
+	// PayloadPrefix is the maximum number of payload bytes to include in the events.
+	//
+	// [NewLoggingReadCloser] sets it to zero, meaning that events do not
+	// include the payload. You MUST NOT modify it after you started reading.
+	PayloadPrefix int
+
+	logger *slog.Logger
+	rc     io.ReadCloser
+}
+
+// NewLoggingReadCloser wraps rc and returns a [*LoggingReadCloser] using logger.
+func NewLoggingReadCloser(rc io.ReadCloser, logger *slog.Logger) *LoggingReadCloser {
+	return &LoggingReadCloser{Level: slog.LevelDebug, logger: logger, rc: rc}
+}
+
+// Read implements [io.Reader].
+func (r *LoggingReadCloser) Read(buf []byte) (int, error) {
+	t0 := time.Now()
+	count, err := r.rc.Read(buf)
+	logIOEvent(r.logger, r.Level, "read", t0, buf[:count], err, r.PayloadPrefix)
+	return count, err
+}
+
+// Close implements [io.Closer].
+func (r *LoggingReadCloser) Close() error {
+	t0 := time.Now()
+	err := r.rc.Close()
+	logIOEvent(r.logger, r.Level, "close", t0, nil, err, 0)
+	return err
+}
+
+// LoggingWriteCloser is an [io.WriteCloser] emitting a structured log event per operation.
+//
+// Each Write and Close emits an event whose message is the operation name ("write"
+// or "close") with the number of bytes, the error, the duration, and optionally a
+// prefix of the payload as attributes.
+//
+// Construct using [NewLoggingWriteCloser].
+type LoggingWriteCloser struct {
+	// Level is the level of the emitted events.
+	//
+	// [NewLoggingWriteCloser] sets it to [slog.LevelDebug]. You MUST NOT
+	// modify it after you started writing.
+	Level slog.Level
+
+	// PayloadPrefix is the maximum number of payload bytes to include in the events.
+	//
+	// [NewLoggingWriteCloser] sets it to zero, meaning that events do not
+	// include the payload. You MUST NOT modify it after you started writing.
+	PayloadPrefix int
+
+	logger *slog.Logger
+	w      io.WriteCloser
+}
+
+// NewLoggingWriteCloser wraps w and returns a [*LoggingWriteCloser] using logger.
+func NewLoggingWriteCloser(w io.WriteCloser, logger *slog.Logger) *LoggingWriteCloser {
+	return &LoggingWriteCloser{Level: slog.LevelDebug, logger: logger, w: w}
+}
+
+// Write implements [io.Writer].
+func (w *LoggingWriteCloser) Write(data []byte) (int, error) {
+	t0 := time.Now()
+	count, err := w.w.Write(data)
+	logIOEvent(w.logger, w.Level, "write", t0, data[:count], err, w.PayloadPrefix)
+	return count, err
+}
+
+// Close implements [io.Closer].
+func (w *LoggingWriteCloser) Close() error {
+	t0 := time.Now()
+	err := w.w.Close()
+	logIOEvent(w.logger, w.Level, "close", t0, nil, err, 0)
+	return err
+}
+
+// logIOEvent emits the structured log event describing an I/O operation.
+func logIOEvent(logger *slog.Logger, level slog.Level, op string,
+	t0 time.Time, data []byte, err error, prefix int) {
+	ctx := context.Background()
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.Int("bytes", len(data)),
+		slog.Any("err", err),
+		slog.Duration("duration", time.Since(t0)),
+	}
+	if prefix > 0 {
+		attrs = append(attrs, slog.String("payload", string(data[:min(len(data), prefix)])))
+	}
+	logger.LogAttrs(ctx, level, op, attrs...)
+}
+
+// WithLogger causes the copy to log each read and write using the given logger.
+//
+// It wraps the source and the destination using [LoggingReadCloser] and
+// [LoggingWriteCloser] with default settings, adding a "side" attribute
+// set to "src" or "dst" to the events. Logging disables the [io.ReaderFrom]
+// fast path of [CopyContext].
+func WithLogger(logger *slog.Logger) CopyOption {
+	return func(cfg *copyConfig) {
+		cfg.wrapReader = append(cfg.wrapReader, func(rc io.ReadCloser) io.ReadCloser {
+			return NewLoggingReadCloser(rc, logger.With("side", "src"))
+		})
+		cfg.wrapWriter = append(cfg.wrapWriter, func(w io.Writer) io.Writer {
+			return NewLoggingWriteCloser(NopWriteCloser(w), logger.With("side", "dst"))
+		})
+	}
+}
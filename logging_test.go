@@ -0,0 +1,132 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeLogEvents parses the JSON events written by a [*slog.JSONHandler].
+func decodeLogEvents(t *testing.T, logs *bytes.Buffer) []map[string]any {
+	var events []map[string]any
+	for line := range strings.Lines(logs.String()) {
+		var ev map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &ev))
+		events = append(events, ev)
+	}
+	return events
+}
+
+func TestLoggingReadCloser(t *testing.T) {
+	logs := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	expected := errors.New("mocked error")
+	rc := NewLoggingReadCloser(ReadCloserFunc(func(buf []byte) (int, error) {
+		return copy(buf, "abcdef"), nil
+	}, func() error {
+		return expected
+	}), logger)
+	rc.PayloadPrefix = 3
+
+	buf := make([]byte, 16)
+	count, err := rc.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, 6, count)
+	assert.ErrorIs(t, rc.Close(), expected)
+
+	events := decodeLogEvents(t, logs)
+	require.Len(t, events, 2)
+	assert.Equal(t, "read", events[0]["msg"])
+	assert.Equal(t, "DEBUG", events[0]["level"])
+	assert.Equal(t, float64(6), events[0]["bytes"])
+	assert.Equal(t, "abc", events[0]["payload"])
+	assert.Nil(t, events[0]["err"])
+	assert.Contains(t, events[0], "duration")
+	assert.Equal(t, "close", events[1]["msg"])
+	assert.Equal(t, "mocked error", events[1]["err"])
+	assert.NotContains(t, events[1], "payload")
+}
+
+func TestLoggingWriteCloser(t *testing.T) {
+	logs := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(logs, nil))
+	buff := &bytes.Buffer{}
+	wc := NewLoggingWriteCloser(NopWriteCloser(buff), logger)
+
+	t.Run("events below the handler level are not emitted", func(t *testing.T) {
+		_, err := wc.Write([]byte("hidden"))
+		require.NoError(t, err)
+		assert.Empty(t, logs.String())
+	})
+
+	t.Run("events at the configured level are emitted", func(t *testing.T) {
+		wc.Level = slog.LevelInfo
+		_, err := wc.Write([]byte("hello"))
+		require.NoError(t, err)
+		require.NoError(t, wc.Close())
+
+		events := decodeLogEvents(t, logs)
+		require.Len(t, events, 2)
+		assert.Equal(t, "write", events[0]["msg"])
+		assert.Equal(t, "INFO", events[0]["level"])
+		assert.Equal(t, float64(5), events[0]["bytes"])
+		assert.NotContains(t, events[0], "payload")
+		assert.Equal(t, "close", events[1]["msg"])
+		assert.Equal(t, "hiddenhello", buff.String())
+	})
+
+	t.Run("short writes log the bytes actually written", func(t *testing.T) {
+		logs.Reset()
+		wc := NewLoggingWriteCloser(&iotest.FuncWriteCloser{
+			WriteFunc: func(data []byte) (int, error) {
+				return 2, io.ErrShortWrite
+			},
+			CloseFunc: func() error {
+				return nil
+			},
+		}, logger)
+		wc.Level = slog.LevelInfo
+		wc.PayloadPrefix = 16
+		count, err := wc.Write([]byte("hello"))
+		assert.ErrorIs(t, err, io.ErrShortWrite)
+		assert.Equal(t, 2, count)
+
+		events := decodeLogEvents(t, logs)
+		require.Len(t, events, 1)
+		assert.Equal(t, float64(2), events[0]["bytes"])
+		assert.Equal(t, "he", events[0]["payload"])
+		assert.Equal(t, io.ErrShortWrite.Error(), events[0]["err"])
+	})
+}
+
+func TestCopyContextWithLogger(t *testing.T) {
+	const payload = "hello, world"
+	logs := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	buff := &bytes.Buffer{}
+	lwc := NewLockedWriteCloser(NopWriteCloser(buff))
+
+	count, err := CopyContext(context.Background(), lwc, NopReadCloser(strings.NewReader(payload)), WithLogger(logger))
+	require.NoError(t, err)
+	assert.Equal(t, len(payload), count)
+	assert.Equal(t, payload, buff.String())
+
+	sides := map[string][]string{}
+	for _, ev := range decodeLogEvents(t, logs) {
+		side := ev["side"].(string)
+		sides[side] = append(sides[side], ev["msg"].(string))
+	}
+	assert.Equal(t, []string{"read", "read"}, sides["src"])
+	assert.Equal(t, []string{"write"}, sides["dst"])
+}
@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"encoding/hex"
+	"hash"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// Manifest contains the size and the digests of some data.
+type Manifest struct {
+	// Size is the size of the data in bytes.
+	Size int64
+
+	// Digests maps the name of each hash to the digest of the data.
+	Digests map[string][]byte
+}
+
+// Hex returns the hex-encoded digest computed by the hash with the given name,
+// or an empty string if there is no such hash.
+func (m Manifest) Hex(name string) string {
+	return hex.EncodeToString(m.Digests[name])
+}
+
+// ManifestHasher is an [io.Writer] computing several digests of the data
+// written into it, feeding each hash concurrently.
+//
+// This allows computing several checksums in a single pass, e.g., while
+// mirroring artifacts, without reading the data once per hash. To hash the
+// data copied by [CopyContext], pass the hasher to [WithTee].
+//
+// All methods are safe for concurrent use.
+//
+// Construct using [NewManifestHasher].
+type ManifestHasher struct {
+	hashes map[string]hash.Hash
+	mu     sync.Mutex
+	names  []string
+	size   int64
+}
+
+// NewManifestHasher returns a new [*ManifestHasher] feeding the given hashes,
+// indexed by name (e.g., "sha256"), such as the ones returned by [crypto/sha256.New].
+func NewManifestHasher(hashes map[string]hash.Hash) *ManifestHasher {
+	return &ManifestHasher{
+		hashes: maps.Clone(hashes),
+		names:  slices.Sorted(maps.Keys(hashes)),
+	}
+}
+
+// manifestParallelMin is the minimum write size for which hashing in parallel
+// is worth the cost of synchronizing goroutines.
+const manifestParallelMin = 4096
+
+// Write implements [io.Writer].
+//
+// It feeds data to each hash in parallel and waits for all of them to finish, so
+// data is not retained after Write returns. It never fails, like [hash.Hash].
+func (h *ManifestHasher) Write(data []byte) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.size += int64(len(data))
+	if len(h.names) < 2 || len(data) < manifestParallelMin {
+		for _, name := range h.names {
+			h.hashes[name].Write(data)
+		}
+		return len(data), nil
+	}
+	var wg sync.WaitGroup
+	for _, name := range h.names {
+		wg.Go(func() {
+			h.hashes[name].Write(data)
+		})
+	}
+	wg.Wait()
+	return len(data), nil
+}
+
+// Manifest returns the [Manifest] of the data written so far.
+func (h *ManifestHasher) Manifest() Manifest {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	m := Manifest{Size: h.size, Digests: make(map[string][]byte, len(h.names))}
+	for _, name := range h.names {
+		m.Digests[name] = h.hashes[name].Sum(nil)
+	}
+	return m
+}
@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"hash/crc32"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifestHasher(t *testing.T) {
+	payload := make([]byte, 1<<20+3)
+	rand.Read(payload)
+	sum256 := sha256.Sum256(payload)
+	sum512 := sha512.Sum512(payload)
+
+	t.Run("hashing while copying", func(t *testing.T) {
+		h := NewManifestHasher(map[string]hash.Hash{
+			"sha256": sha256.New(),
+			"sha512": sha512.New(),
+			"crc32":  crc32.NewIEEE(),
+		})
+		var dst bytes.Buffer
+		_, err := CopyContext(context.Background(), NewLockedWriteCloser(NopWriteCloser(&dst)),
+			NopReadCloser(bytes.NewReader(payload)), WithTee(h))
+		require.NoError(t, err)
+
+		m := h.Manifest()
+		assert.Equal(t, int64(len(payload)), m.Size)
+		assert.Len(t, m.Digests, 3)
+		assert.Equal(t, hex.EncodeToString(sum256[:]), m.Hex("sha256"))
+		assert.Equal(t, sum512[:], m.Digests["sha512"])
+		assert.Equal(t, crc32.ChecksumIEEE(payload), binary.BigEndian.Uint32(m.Digests["crc32"]))
+		assert.Empty(t, m.Hex("md5"))
+	})
+
+	t.Run("small writes", func(t *testing.T) {
+		h := NewManifestHasher(map[string]hash.Hash{"sha256": sha256.New(), "sha512": sha512.New()})
+		for data := payload; len(data) > 0; data = data[min(len(data), 100):] {
+			_, err := h.Write(data[:min(len(data), 100)])
+			require.NoError(t, err)
+		}
+		m := h.Manifest()
+		assert.Equal(t, hex.EncodeToString(sum256[:]), m.Hex("sha256"))
+		assert.Equal(t, sum512[:], m.Digests["sha512"])
+	})
+}
@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"expvar"
+	"io"
+	"time"
+)
+
+// Collector receives metrics about I/O operations.
+//
+// The op argument is the operation name ("read", "write", or "close"), count is the
+// number of bytes transferred, err is the operation error, and elapsed is the
+// operation duration.
+//
+// Implementations MUST be safe for concurrent use.
+//
+// See [NewExpvarCollector] for an adapter publishing metrics using the expvar
+// package and the prommetrics subpackage for a Prometheus adapter.
+type Collector interface {
+	Observe(op string, count int, err error, elapsed time.Duration)
+}
+
+// CollectorFunc is a function implementing [Collector].
+type CollectorFunc func(op string, count int, err error, elapsed time.Duration)
+
+var _ Collector = CollectorFunc(nil)
+
+// Observe implements [Collector].
+func (fx CollectorFunc) Observe(op string, count int, err error, elapsed time.Duration) {
+	fx(op, count, err, elapsed)
+}
+
+// NewExpvarCollector returns a [Collector] updating the given [*expvar.Map].
+//
+// For each operation, it maintains the "<op>.bytes", "<op>.count", "<op>.errors"
+// integer counters and the "<op>.seconds" float counter.
+func NewExpvarCollector(m *expvar.Map) Collector {
+	return CollectorFunc(func(op string, count int, err error, elapsed time.Duration) {
+		m.Add(op+".bytes", int64(count))
+		m.Add(op+".count", 1)
+		if err != nil && err != io.EOF {
+			m.Add(op+".errors", 1)
+		}
+		m.AddFloat(op+".seconds", elapsed.Seconds())
+	})
+}
+
+// MeteredReadCloser is an [io.ReadCloser] reporting each Read and Close to a [Collector].
+//
+// Construct using [NewMeteredReadCloser].
+type MeteredReadCloser struct {
+	c  Collector
+	rc io.ReadCloser
+}
+
+// NewMeteredReadCloser wraps rc and returns a [*MeteredReadCloser] reporting to c.
+func NewMeteredReadCloser(rc io.ReadCloser, c Collector) *MeteredReadCloser {
+	return &MeteredReadCloser{c: c, rc: rc}
+}
+
+// Read implements [io.Reader].
+func (r *MeteredReadCloser) Read(buf []byte) (int, error) {
+	t0 := time.Now()
+	count, err := r.rc.Read(buf)
+	r.c.Observe("read", count, err, time.Since(t0))
+	return count, err
+}
+
+// Close implements [io.Closer].
+func (r *MeteredReadCloser) Close() error {
+	t0 := time.Now()
+	err := r.rc.Close()
+	r.c.Observe("close", 0, err, time.Since(t0))
+	return err
+}
+
+// MeteredWriteCloser is an [io.WriteCloser] reporting each Write and Close to a [Collector].
+//
+// Wrap it using [NewLockedWriteCloser] to obtain a metered locked writer.
+//
+// Construct using [NewMeteredWriteCloser].
+type MeteredWriteCloser struct {
+	c Collector
+	w io.WriteCloser
+}
+
+// NewMeteredWriteCloser wraps w and returns a [*MeteredWriteCloser] reporting to c.
+func NewMeteredWriteCloser(w io.WriteCloser, c Collector) *MeteredWriteCloser {
+	return &MeteredWriteCloser{c: c, w: w}
+}
+
+// Write implements [io.Writer].
+func (w *MeteredWriteCloser) Write(data []byte) (int, error) {
+	t0 := time.Now()
+	count, err := w.w.Write(data)
+	w.c.Observe("write", count, err, time.Since(t0))
+	return count, err
+}
+
+// Close implements [io.Closer].
+func (w *MeteredWriteCloser) Close() error {
+	t0 := time.Now()
+	err := w.w.Close()
+	w.c.Observe("close", 0, err, time.Since(t0))
+	return err
+}
+
+// WithCollector causes the copy to report each read and write to the given [Collector].
+//
+// It wraps the source and the destination using [MeteredReadCloser] and
+// [MeteredWriteCloser]. Metering disables the zero-copy fast path
+// of [CopyContext].
+func WithCollector(c Collector) CopyOption {
+	return func(cfg *copyConfig) {
+		cfg.wrapReader = append(cfg.wrapReader, func(rc io.ReadCloser) io.ReadCloser {
+			return NewMeteredReadCloser(rc, c)
+		})
+		cfg.wrapWriter = append(cfg.wrapWriter, func(w io.Writer) io.Writer {
+			return NewMeteredWriteCloser(NopWriteCloser(w), c)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"expvar"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collectorRecorder is a [Collector] recording the observed operations.
+type collectorRecorder struct {
+	mu  sync.Mutex
+	ops []string
+	n   map[string]int
+}
+
+func (c *collectorRecorder) Observe(op string, count int, err error, elapsed time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.n == nil {
+		c.n = map[string]int{}
+	}
+	c.ops = append(c.ops, op)
+	c.n[op] += count
+}
+
+func TestMeteredReadCloser(t *testing.T) {
+	rec := &collectorRecorder{}
+	rc := NewMeteredReadCloser(NopReadCloser(strings.NewReader("hello")), rec)
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+	require.NoError(t, rc.Close())
+	assert.Equal(t, []string{"read", "read", "close"}, rec.ops)
+	assert.Equal(t, 5, rec.n["read"])
+}
+
+func TestMeteredWriteCloser(t *testing.T) {
+	rec := &collectorRecorder{}
+	buff := &bytes.Buffer{}
+	w := NewMeteredWriteCloser(NopWriteCloser(buff), rec)
+	_, err := w.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	assert.Equal(t, []string{"write", "close"}, rec.ops)
+	assert.Equal(t, 5, rec.n["write"])
+}
+
+func TestNewExpvarCollector(t *testing.T) {
+	m := new(expvar.Map).Init()
+	expected := errors.New("mocked error")
+	w := NewMeteredWriteCloser(&iotest.FuncWriteCloser{
+		WriteFunc: func(data []byte) (int, error) {
+			if string(data) == "fail" {
+				return 0, expected
+			}
+			return len(data), nil
+		},
+		CloseFunc: func() error {
+			return nil
+		},
+	}, NewExpvarCollector(m))
+
+	_, err := w.Write([]byte("hello"))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("fail"))
+	assert.ErrorIs(t, err, expected)
+
+	assert.Equal(t, "5", m.Get("write.bytes").String())
+	assert.Equal(t, "2", m.Get("write.count").String())
+	assert.Equal(t, "1", m.Get("write.errors").String())
+	assert.NotNil(t, m.Get("write.seconds"))
+
+	t.Run("EOF is not counted as an error", func(t *testing.T) {
+		rc := NewMeteredReadCloser(NopReadCloser(strings.NewReader("")), NewExpvarCollector(m))
+		_, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		assert.Equal(t, "1", m.Get("read.count").String())
+		assert.Nil(t, m.Get("read.errors"))
+	})
+}
+
+func TestCopyContextWithCollector(t *testing.T) {
+	const payload = "hello, world"
+	rec := &collectorRecorder{}
+	buff := &bytes.Buffer{}
+	lwc := NewLockedWriteCloser(NopWriteCloser(buff))
+
+	count, err := CopyContext(context.Background(), lwc, NopReadCloser(strings.NewReader(payload)), WithCollector(rec))
+	require.NoError(t, err)
+	assert.Equal(t, len(payload), count)
+	assert.Equal(t, payload, buff.String())
+	assert.Equal(t, len(payload), rec.n["read"])
+	assert.Equal(t, len(payload), rec.n["write"])
+}
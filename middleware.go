@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"hash"
+	"io"
+	"time"
+)
+
+// ReaderMiddleware wraps an [io.ReadCloser] returning another [io.ReadCloser].
+//
+// The returned reader MUST forward Close to the wrapped reader.
+type ReaderMiddleware func(rc io.ReadCloser) io.ReadCloser
+
+// WriterMiddleware wraps an [io.WriteCloser] returning another [io.WriteCloser].
+//
+// The returned writer MUST forward Close to the wrapped writer.
+type WriterMiddleware func(w io.WriteCloser) io.WriteCloser
+
+// WrapReader applies the given middlewares to rc in order, so the first
+// middleware is the closest to rc and the last one is the closest to the caller.
+//
+// Closing the returned reader closes each layer from the outermost to rc.
+func WrapReader(rc io.ReadCloser, middlewares ...ReaderMiddleware) io.ReadCloser {
+	for _, mw := range middlewares {
+		rc = mw(rc)
+	}
+	return rc
+}
+
+// WrapWriter applies the given middlewares to w in order, so the first
+// middleware is the closest to w and the last one is the closest to the caller.
+//
+// Closing the returned writer closes each layer from the outermost to w.
+func WrapWriter(w io.WriteCloser, middlewares ...WriterMiddleware) io.WriteCloser {
+	for _, mw := range middlewares {
+		w = mw(w)
+	}
+	return w
+}
+
+// LimitReaderMiddleware returns a [ReaderMiddleware] using [LimitReadCloser].
+func LimitReaderMiddleware(n int64) ReaderMiddleware {
+	return func(rc io.ReadCloser) io.ReadCloser {
+		return LimitReadCloser(rc, n)
+	}
+}
+
+// TeeReaderMiddleware returns a [ReaderMiddleware] writing into w what it reads,
+// like [io.TeeReader]. Closing the reader does not close w.
+func TeeReaderMiddleware(w io.Writer) ReaderMiddleware {
+	return func(rc io.ReadCloser) io.ReadCloser {
+		return JoinReadCloser(io.TeeReader(rc, w), rc)
+	}
+}
+
+// TeeWriterMiddleware returns a [WriterMiddleware] also writing into tee, like
+// [io.MultiWriter]. Closing the writer does not close tee.
+func TeeWriterMiddleware(tee io.Writer) WriterMiddleware {
+	return func(w io.WriteCloser) io.WriteCloser {
+		return writeCloser{io.MultiWriter(w, tee), w}
+	}
+}
+
+// HashReaderMiddleware returns a [ReaderMiddleware] feeding h with what it reads.
+func HashReaderMiddleware(h hash.Hash) ReaderMiddleware {
+	return TeeReaderMiddleware(h)
+}
+
+// HashWriterMiddleware returns a [WriterMiddleware] feeding h with what it writes.
+func HashWriterMiddleware(h hash.Hash) WriterMiddleware {
+	return TeeWriterMiddleware(h)
+}
+
+// RateLimitReaderMiddleware returns a [ReaderMiddleware] reading at most
+// bytesPerSecond bytes per second on average, honoring ctx while waiting.
+//
+// Nonpositive values of bytesPerSecond disable rate limiting.
+func RateLimitReaderMiddleware(ctx context.Context, bytesPerSecond int) ReaderMiddleware {
+	return func(rc io.ReadCloser) io.ReadCloser {
+		if bytesPerSecond <= 0 {
+			return rc
+		}
+		return JoinReadCloser(&rateLimitedReader{newPacer(ctx, bytesPerSecond), rc}, rc)
+	}
+}
+
+// RateLimitWriterMiddleware returns a [WriterMiddleware] writing at most
+// bytesPerSecond bytes per second on average, honoring ctx while waiting.
+//
+// Nonpositive values of bytesPerSecond disable rate limiting.
+func RateLimitWriterMiddleware(ctx context.Context, bytesPerSecond int) WriterMiddleware {
+	return func(w io.WriteCloser) io.WriteCloser {
+		if bytesPerSecond <= 0 {
+			return w
+		}
+		return writeCloser{&rateLimitedWriter{newPacer(ctx, bytesPerSecond), w}, w}
+	}
+}
+
+// ObserveReaderMiddleware returns a [ReaderMiddleware] reporting to c using [MeteredReadCloser].
+func ObserveReaderMiddleware(c Collector) ReaderMiddleware {
+	return func(rc io.ReadCloser) io.ReadCloser {
+		return NewMeteredReadCloser(rc, c)
+	}
+}
+
+// ObserveWriterMiddleware returns a [WriterMiddleware] reporting to c using [MeteredWriteCloser].
+func ObserveWriterMiddleware(c Collector) WriterMiddleware {
+	return func(w io.WriteCloser) io.WriteCloser {
+		return NewMeteredWriteCloser(w, c)
+	}
+}
+
+// pacer paces I/O to a given average rate.
+type pacer struct {
+	ctx   context.Context
+	rate  int
+	start time.Time
+	total int64
+}
+
+// newPacer returns a new [*pacer] for the given rate in bytes per second.
+func newPacer(ctx context.Context, rate int) *pacer {
+	return &pacer{ctx: ctx, rate: rate, start: time.Now()}
+}
+
+// wait accounts for count bytes and sleeps until transferring them is due.
+func (p *pacer) wait(count int) error {
+	p.total += int64(count)
+	due := p.start.Add(time.Duration(float64(p.total) / float64(p.rate) * float64(time.Second)))
+	return sleepContext(p.ctx, time.Until(due))
+}
+
+// rateLimitedReader is the reader used by [RateLimitReaderMiddleware].
+type rateLimitedReader struct {
+	p *pacer
+	r io.Reader
+}
+
+// Read implements [io.Reader].
+func (r *rateLimitedReader) Read(buf []byte) (int, error) {
+	if err := contextError(r.p.ctx); err != nil {
+		return 0, err
+	}
+	count, err := r.r.Read(buf[:min(len(buf), r.p.rate)])
+	if werr := r.p.wait(count); werr != nil && err == nil {
+		err = werr
+	}
+	return count, err
+}
+
+// rateLimitedWriter is the writer used by [RateLimitWriterMiddleware].
+type rateLimitedWriter struct {
+	p *pacer
+	w io.Writer
+}
+
+// Write implements [io.Writer].
+func (w *rateLimitedWriter) Write(data []byte) (int, error) {
+	var total int
+	for len(data) > 0 {
+		count, err := w.w.Write(data[:min(len(data), w.p.rate)])
+		total += count
+		data = data[count:]
+		if err != nil {
+			return total, err
+		}
+		if err := w.p.wait(count); err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// closeOrderReaderMiddleware returns a [ReaderMiddleware] recording its name when closed.
+func closeOrderReaderMiddleware(name string, order *[]string) ReaderMiddleware {
+	return func(rc io.ReadCloser) io.ReadCloser {
+		return ReadCloserFunc(rc.Read, func() error {
+			*order = append(*order, name)
+			return rc.Close()
+		})
+	}
+}
+
+func TestWrapReader(t *testing.T) {
+	const payload = "hello, world"
+	var order []string
+	rc := &iotest.FuncReadCloser{
+		ReadFunc: strings.NewReader(payload).Read,
+		CloseFunc: func() error {
+			order = append(order, "source")
+			return nil
+		},
+	}
+	tee := &bytes.Buffer{}
+	h := sha256.New()
+	rec := &collectorRecorder{}
+
+	wrapped := WrapReader(rc,
+		closeOrderReaderMiddleware("inner", &order),
+		LimitReaderMiddleware(5),
+		TeeReaderMiddleware(tee),
+		HashReaderMiddleware(h),
+		ObserveReaderMiddleware(rec),
+		closeOrderReaderMiddleware("outer", &order),
+	)
+	data, err := io.ReadAll(wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+	assert.Equal(t, "hello", tee.String())
+	expected := sha256.Sum256([]byte("hello"))
+	assert.Equal(t, expected[:], h.Sum(nil))
+	assert.Equal(t, 5, rec.n["read"])
+
+	require.NoError(t, wrapped.Close())
+	assert.Equal(t, []string{"outer", "inner", "source"}, order)
+}
+
+func TestWrapWriter(t *testing.T) {
+	buff := &bytes.Buffer{}
+	tee := &bytes.Buffer{}
+	h := sha256.New()
+	rec := &collectorRecorder{}
+	w := WrapWriter(NopWriteCloser(buff), TeeWriterMiddleware(tee), HashWriterMiddleware(h), ObserveWriterMiddleware(rec))
+
+	_, err := w.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	assert.Equal(t, "hello", buff.String())
+	assert.Equal(t, "hello", tee.String())
+	expected := sha256.Sum256([]byte("hello"))
+	assert.Equal(t, expected[:], h.Sum(nil))
+	assert.Equal(t, []string{"write", "close"}, rec.ops)
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	payload := strings.Repeat("x", 300)
+
+	t.Run("reader", func(t *testing.T) {
+		rc := WrapReader(NopReadCloser(strings.NewReader(payload)),
+			RateLimitReaderMiddleware(context.Background(), 1000))
+		t0 := time.Now()
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		assert.Equal(t, payload, string(data))
+		assert.GreaterOrEqual(t, time.Since(t0), 250*time.Millisecond)
+	})
+
+	t.Run("writer", func(t *testing.T) {
+		buff := &bytes.Buffer{}
+		w := WrapWriter(NopWriteCloser(buff), RateLimitWriterMiddleware(context.Background(), 1000))
+		t0 := time.Now()
+		count, err := w.Write([]byte(payload))
+		require.NoError(t, err)
+		assert.Equal(t, len(payload), count)
+		assert.GreaterOrEqual(t, time.Since(t0), 250*time.Millisecond)
+	})
+
+	t.Run("honors the context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		rc := WrapReader(NopReadCloser(strings.NewReader(payload)), RateLimitReaderMiddleware(ctx, 10))
+		_, err := rc.Read(make([]byte, 8))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("nonpositive rates disable rate limiting", func(t *testing.T) {
+		rc := NopReadCloser(strings.NewReader(payload))
+		assert.Equal(t, rc, WrapReader(rc, RateLimitReaderMiddleware(context.Background(), 0)))
+	})
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+// MappedFile is a [ReaderAtCloser] reading a file mapped in memory.
+//
+// Large read-mostly files benefit from memory-mapped reads, which avoid a system
+// call and a copy into kernel buffers per ReadAt. Where memory mapping is not
+// available, MappedFile falls back to reading using ReadAt, i.e., pread.
+//
+// Use [SectionReadCloser] to read a section as a stream, and [CopyReaderAtContext]
+// along with Size to copy the whole file using parallel workers.
+//
+// All methods are safe for concurrent use. Close waits for in-flight reads.
+//
+// Construct using [MmapReaderAt].
+type MappedFile struct {
+	cs   closeState
+	data []byte
+	file *os.File
+	mu   sync.RWMutex
+	size int64
+}
+
+var _ ReaderAtCloser = &MappedFile{}
+
+// MmapReaderAt opens the file at path and returns a [*MappedFile] reading it.
+//
+// The file should not be truncated while mapped, since reading pages beyond the
+// end of a truncated file causes a fatal error on most platforms.
+func MmapReaderAt(path string) (*MappedFile, error) {
+	// 1. open the file and get its size
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	size := info.Size()
+
+	// 2. map the file, unless empty, and close it since the mapping outlives it
+	if size > 0 && int64(int(size)) == size {
+		if data, err := mmapFile(file, int(size)); err == nil {
+			file.Close()
+			return &MappedFile{data: data, size: size}, nil
+		}
+	}
+
+	// 3. otherwise, fallback to reading using pread
+	return &MappedFile{file: file, size: size}, nil
+}
+
+var (
+	// errMmapUnsupported indicates that memory mapping is not supported.
+	errMmapUnsupported = errors.New("iox: mmap not supported")
+
+	// errNegativeReadOffset is returned when reading at a negative offset.
+	errNegativeReadOffset = errors.New("iox: read at a negative offset")
+)
+
+// ReadAt implements [io.ReaderAt].
+func (f *MappedFile) ReadAt(buf []byte, off int64) (int, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if err := f.cs.check(); err != nil {
+		return 0, err
+	}
+	if f.data == nil {
+		return f.file.ReadAt(buf, off)
+	}
+	if off < 0 {
+		return 0, errNegativeReadOffset
+	}
+	if off >= f.size {
+		return 0, io.EOF
+	}
+	count := copy(buf, f.data[off:])
+	if count < len(buf) {
+		return count, io.EOF
+	}
+	return count, nil
+}
+
+// Size returns the size of the file.
+func (f *MappedFile) Size() int64 {
+	return f.size
+}
+
+// Close unmaps, or closes, the file.
+//
+// The returned error is nil, [ErrClosed] when already closed, or the
+// error occurred when unmapping, or closing, the file.
+func (f *MappedFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.cs.close(func() error {
+		if f.data == nil {
+			return f.file.Close()
+		}
+		data := f.data
+		f.data = nil
+		return munmapFile(data)
+	})
+}
@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package iox
+
+import "os"
+
+// mmapFile maps the first size bytes of file in memory for reading.
+//
+// This platform does not support memory mapping, hence it always fails
+// and [MmapReaderAt] falls back to reading using pread.
+func mmapFile(file *os.File, size int) ([]byte, error) {
+	return nil, errMmapUnsupported
+}
+
+// munmapFile unmaps memory returned by mmapFile.
+func munmapFile(data []byte) error {
+	return errMmapUnsupported
+}
@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTempFile writes data into a temporary file and returns its path.
+func writeTempFile(t *testing.T, data []byte) string {
+	path := filepath.Join(t.TempDir(), "data")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	return path
+}
+
+func TestMmapReaderAt(t *testing.T) {
+	payload := make([]byte, 1<<20+17)
+	rand.Read(payload)
+
+	t.Run("ReadAt", func(t *testing.T) {
+		f, err := MmapReaderAt(writeTempFile(t, payload))
+		require.NoError(t, err)
+		defer f.Close()
+		if runtime.GOOS == "linux" {
+			assert.NotNil(t, f.data)
+		}
+		assert.Equal(t, int64(len(payload)), f.Size())
+
+		buf := make([]byte, 16)
+		count, err := f.ReadAt(buf, 4096)
+		require.NoError(t, err)
+		assert.Equal(t, payload[4096:4096+16], buf[:count])
+
+		count, err = f.ReadAt(buf, int64(len(payload)-5))
+		require.ErrorIs(t, err, io.EOF)
+		assert.Equal(t, payload[len(payload)-5:], buf[:count])
+
+		_, err = f.ReadAt(buf, int64(len(payload)))
+		require.ErrorIs(t, err, io.EOF)
+
+		_, err = f.ReadAt(buf, -1)
+		require.Error(t, err)
+	})
+
+	t.Run("CopyReaderAtContext", func(t *testing.T) {
+		f, err := MmapReaderAt(writeTempFile(t, payload))
+		require.NoError(t, err)
+		defer f.Close()
+		dst := &memWriterAt{buf: make([]byte, f.Size())}
+		count, err := CopyReaderAtContext(context.Background(), dst, f, f.Size(), WithChunkSize(4096))
+		require.NoError(t, err)
+		assert.Equal(t, f.Size(), count)
+		assert.True(t, bytes.Equal(payload, dst.buf))
+	})
+
+	t.Run("SectionReadCloser", func(t *testing.T) {
+		f, err := MmapReaderAt(writeTempFile(t, payload))
+		require.NoError(t, err)
+		rc := SectionReadCloser(f, 100, 200, f)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		assert.Equal(t, payload[100:300], data)
+		require.NoError(t, rc.Close())
+		_, err = f.ReadAt(make([]byte, 1), 0)
+		assert.ErrorIs(t, err, ErrClosed)
+		assert.ErrorIs(t, f.Close(), ErrClosed)
+	})
+
+	t.Run("empty files use pread", func(t *testing.T) {
+		f, err := MmapReaderAt(writeTempFile(t, nil))
+		require.NoError(t, err)
+		assert.Nil(t, f.data)
+		assert.Equal(t, int64(0), f.Size())
+		_, err = f.ReadAt(make([]byte, 1), 0)
+		require.ErrorIs(t, err, io.EOF)
+		require.NoError(t, f.Close())
+	})
+
+	t.Run("missing files", func(t *testing.T) {
+		_, err := MmapReaderAt(filepath.Join(t.TempDir(), "missing"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package iox
+
+import (
+	"os"
+	"syscall"
+)
+
+// mmapFile maps the first size bytes of file in memory for reading.
+func mmapFile(file *os.File, size int) ([]byte, error) {
+	return syscall.Mmap(int(file.Fd()), 0, size, syscall.PROT_READ, syscall.MAP_SHARED)
+}
+
+// munmapFile unmaps memory returned by mmapFile.
+func munmapFile(data []byte) error {
+	return syscall.Munmap(data)
+}
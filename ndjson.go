@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+)
+
+// JSONLinesContext returns an iterator over the newline-delimited JSON values read from rc.
+//
+// Each non-empty line is unmarshaled into a new T and yielded with a nil error. On
+// failure, the iterator yields the zero value of T along with the error and stops.
+// Unmarshal errors mention the line number.
+//
+// Reads are context-interruptible and subject to maxLineSize like in [LinesContext],
+// which also documents the default of maxLineSize and when rc is closed.
+func JSONLinesContext[T any](ctx context.Context, rc io.ReadCloser, maxLineSize int) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var lineno int
+		for line, err := range LinesContext(ctx, rc, maxLineSize) {
+			var value T
+			if err != nil {
+				yield(value, err)
+				return
+			}
+			lineno++
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			if err := json.Unmarshal([]byte(line), &value); err != nil {
+				yield(value, fmt.Errorf("line %d: %w", lineno, err))
+				return
+			}
+			if !yield(value, nil) {
+				return
+			}
+		}
+	}
+}
+
+// DecodeJSONLinesContext reads newline-delimited JSON values from rc and invokes fn
+// for each of them, stopping at the first error returned by fn.
+//
+// See [JSONLinesContext] for more details.
+//
+// The returned error is nil on EOF, the error returned by fn, or the error
+// caused by I/O, by unmarshaling, or by the context.
+func DecodeJSONLinesContext[T any](ctx context.Context, rc io.ReadCloser, maxLineSize int, fn func(T) error) error {
+	for value, err := range JSONLinesContext[T](ctx, rc, maxLineSize) {
+		if err != nil {
+			return err
+		}
+		if err := fn(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
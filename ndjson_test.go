@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// jsonEvent is the event type used to test NDJSON decoding.
+type jsonEvent struct {
+	Kind  string `json:"kind"`
+	Value int    `json:"value"`
+}
+
+func TestDecodeJSONLinesContextSuccess(t *testing.T) {
+	input := "{\"kind\":\"a\",\"value\":1}\n\n{\"kind\":\"b\",\"value\":2}\r\n"
+	var events []jsonEvent
+	err := DecodeJSONLinesContext(context.Background(), NopReadCloser(strings.NewReader(input)), 1024,
+		func(ev jsonEvent) error {
+			events = append(events, ev)
+			return nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, []jsonEvent{{"a", 1}, {"b", 2}}, events)
+}
+
+func TestDecodeJSONLinesContextLineOfExactlyMaxLineSize(t *testing.T) {
+	// The end-of-line marker does not count toward the limit.
+	const line = `{"kind":"a","value":1}`
+	var events []jsonEvent
+	err := DecodeJSONLinesContext(context.Background(), NopReadCloser(strings.NewReader(line+"\r\n")), len(line),
+		func(ev jsonEvent) error {
+			events = append(events, ev)
+			return nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, []jsonEvent{{"a", 1}}, events)
+}
+
+func TestDecodeJSONLinesContextErrors(t *testing.T) {
+	t.Run("with invalid JSON", func(t *testing.T) {
+		input := "{\"kind\":\"a\"}\n{invalid}\n"
+		err := DecodeJSONLinesContext(context.Background(), NopReadCloser(strings.NewReader(input)), 1024,
+			func(ev jsonEvent) error { return nil })
+		var syntaxErr *json.SyntaxError
+		require.ErrorAs(t, err, &syntaxErr)
+		assert.Contains(t, err.Error(), "line 2")
+	})
+
+	t.Run("when the callback fails", func(t *testing.T) {
+		expected := errors.New("mocked callback error")
+		input := "{}\n{}\n"
+		calls := 0
+		err := DecodeJSONLinesContext(context.Background(), NopReadCloser(strings.NewReader(input)), 1024,
+			func(ev jsonEvent) error {
+				calls++
+				return expected
+			})
+		require.ErrorIs(t, err, expected)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("with a cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := DecodeJSONLinesContext(ctx, NopReadCloser(strings.NewReader("{}\n")), 1024,
+			func(ev jsonEvent) error { return nil })
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestJSONLinesContextEarlyBreak(t *testing.T) {
+	input := "1\n2\n3\n"
+	var values []int
+	for value, err := range JSONLinesContext[int](context.Background(), NopReadCloser(strings.NewReader(input)), 1024) {
+		require.NoError(t, err)
+		values = append(values, value)
+		if value == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []int{1, 2}, values)
+}
@@ -0,0 +1,168 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrCopyTimeout is the cause of the context error returned when a copy
+// exceeds the duration configured using [WithMaxDuration].
+var ErrCopyTimeout = errors.New("copy exceeded its maximum duration")
+
+// CopyOption configures functions copying data, such as [CopyContext].
+//
+// Each function documents which options it honors and ignores the others.
+type CopyOption func(cfg *copyConfig)
+
+// copyConfig contains the configuration set using [CopyOption].
+type copyConfig struct {
+	bandwidth      []func(BandwidthSummary)
+	chunkSize      int64
+	closers        []io.Closer
+	concurrency    int
+	finishers      []func() error
+	flushEachWrite bool
+	label          string
+	maxDuration    time.Duration
+	maxRecordSize  int
+	ownership      Ownership
+	results        []func(CopyResult)
+	spanName       string
+	sync           bool
+	trace          *CopyTrace
+	tracer         Tracer
+	wrapReader     []ReaderMiddleware
+	wrapWriter     []func(io.Writer) io.Writer
+}
+
+// newCopyConfig returns a new [*copyConfig] with defaults and the given options applied.
+func newCopyConfig(opts ...CopyOption) *copyConfig {
+	cfg := &copyConfig{}
+	cfg.init(opts...)
+	return cfg
+}
+
+// init resets cfg to the defaults and applies the given options.
+func (cfg *copyConfig) init(opts ...CopyOption) {
+	*cfg = copyConfig{
+		chunkSize:     1 << 20,
+		concurrency:   4,
+		maxRecordSize: bufio.MaxScanTokenSize,
+		spanName:      "iox.CopyContext",
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+}
+
+// wrapReadCloser wraps rc using the reader wrappers configured by the options.
+func (cfg *copyConfig) wrapReadCloser(rc io.ReadCloser) io.ReadCloser {
+	return WrapReader(rc, cfg.wrapReader...)
+}
+
+// wrapDestination wraps w using the writer wrappers configured by the options.
+func (cfg *copyConfig) wrapDestination(w io.Writer) io.Writer {
+	for _, wrap := range cfg.wrapWriter {
+		w = wrap(w)
+	}
+	return w
+}
+
+// finish runs the functions registered by the options once the copy reaches
+// EOF, such as the ones writing the data retained by [WithTransform], in the
+// reverse order of registration, stopping at the first error.
+func (cfg *copyConfig) finish() error {
+	for i := len(cfg.finishers) - 1; i >= 0; i-- {
+		if err := cfg.finishers[i](); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// close closes the resources created by the options for a copy, such as the
+// files created by [WithTeeFile], once the copy is done.
+func (cfg *copyConfig) close() {
+	for _, c := range cfg.closers {
+		c.Close()
+	}
+}
+
+// withMaxDuration returns a context bounded by the duration configured using
+// [WithMaxDuration], if any, along with the function to release its resources.
+func (cfg *copyConfig) withMaxDuration(ctx context.Context) (context.Context, context.CancelFunc) {
+	if cfg.maxDuration <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, cfg.maxDuration, ErrCopyTimeout)
+}
+
+// MaxChunkSize is the maximum chunk size honored by [WithChunkSize].
+const MaxChunkSize = 64 << 20
+
+// WithChunkSize sets the size of the chunks copied independently.
+//
+// Nonpositive values are ignored and values larger than [MaxChunkSize] are
+// clamped to it, since each worker allocates a buffer of this size. The default
+// is 1 MiB.
+func WithChunkSize(size int64) CopyOption {
+	return func(cfg *copyConfig) {
+		if size > 0 {
+			cfg.chunkSize = min(size, MaxChunkSize)
+		}
+	}
+}
+
+// WithConcurrency sets the number of goroutines copying chunks in parallel.
+//
+// Nonpositive values are ignored. The default is 4.
+func WithConcurrency(n int) CopyOption {
+	return func(cfg *copyConfig) {
+		if n > 0 {
+			cfg.concurrency = n
+		}
+	}
+}
+
+// WithFlushEachWrite causes the copy to flush the destination after each write.
+//
+// This is useful when streaming through writers that would otherwise buffer
+// indefinitely, such as HTTP response writers. See [*LockedWriteCloser.LockedFlush]
+// for the supported writers. Flushing disables the zero-copy fast path.
+func WithFlushEachWrite() CopyOption {
+	return func(cfg *copyConfig) {
+		cfg.flushEachWrite = true
+	}
+}
+
+// WithMaxDuration sets a hard wall-clock bound on the whole copy.
+//
+// The bound is independent of the caller's context: when the copy takes longer
+// than d, it is interrupted as if the context had been canceled, and the returned
+// error wraps both [context.DeadlineExceeded] and [ErrCopyTimeout], so callers can
+// tell a copy that took too long apart from their own context being done.
+//
+// Nonpositive values are ignored. The default is no bound.
+func WithMaxDuration(d time.Duration) CopyOption {
+	return func(cfg *copyConfig) {
+		if d > 0 {
+			cfg.maxDuration = d
+		}
+	}
+}
+
+// WithMaxRecordSize sets the maximum size of a record for [CopyRecordsContext].
+//
+// Nonpositive values are ignored. The default is [bufio.MaxScanTokenSize].
+func WithMaxRecordSize(size int) CopyOption {
+	return func(cfg *copyConfig) {
+		if size > 0 {
+			cfg.maxRecordSize = size
+		}
+	}
+}
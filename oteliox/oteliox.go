@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package oteliox adapts OpenTelemetry tracing to [iox.Tracer].
+package oteliox
+
+import (
+	"context"
+	"time"
+
+	"github.com/bassosimone/iox"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewTracer returns an [iox.Tracer] recording spans using the given [trace.Tracer].
+//
+// Each span has the "iox.bytes" attribute containing the number of bytes copied
+// and, when the copy read at least one byte, the "iox.ttfb_seconds" attribute
+// containing the time to the first byte along with a "first_byte" event. When
+// the copy fails, the span records the error and has an error status.
+func NewTracer(tracer trace.Tracer) iox.Tracer {
+	return &otelTracer{tracer}
+}
+
+// otelTracer is the [iox.Tracer] returned by [NewTracer].
+type otelTracer struct {
+	tracer trace.Tracer
+}
+
+// StartSpan implements [iox.Tracer].
+func (t *otelTracer) StartSpan(ctx context.Context, name string) iox.Span {
+	_, span := t.tracer.Start(ctx, name)
+	return &otelSpan{span: span, t0: time.Now()}
+}
+
+// otelSpan is the [iox.Span] returned by [*otelTracer.StartSpan].
+type otelSpan struct {
+	span trace.Span
+	t0   time.Time
+}
+
+// RecordFirstByte implements [iox.Span].
+func (s *otelSpan) RecordFirstByte() {
+	s.span.AddEvent("first_byte")
+	s.span.SetAttributes(attribute.Float64("iox.ttfb_seconds", time.Since(s.t0).Seconds()))
+}
+
+// End implements [iox.Span].
+func (s *otelSpan) End(count int, err error) {
+	s.span.SetAttributes(attribute.Int("iox.bytes", count))
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
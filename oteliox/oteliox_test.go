@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package oteliox
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bassosimone/iox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// spanAttributes returns the attributes of a span as a map.
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	out := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		out[kv.Key] = kv.Value
+	}
+	return out
+}
+
+func TestNewTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := NewTracer(provider.Tracer("test"))
+
+	t.Run("success", func(t *testing.T) {
+		const payload = "hello, world"
+		buff := &bytes.Buffer{}
+		lwc := iox.NewLockedWriteCloser(iox.NopWriteCloser(buff))
+		_, err := iox.CopyContext(context.Background(), lwc,
+			iox.NopReadCloser(strings.NewReader(payload)), iox.WithTracer(tracer))
+		require.NoError(t, err)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "iox.CopyContext", spans[0].Name())
+		attrs := spanAttributes(spans[0])
+		assert.Equal(t, int64(len(payload)), attrs["iox.bytes"].AsInt64())
+		assert.Contains(t, attrs, attribute.Key("iox.ttfb_seconds"))
+		require.Len(t, spans[0].Events(), 1)
+		assert.Equal(t, "first_byte", spans[0].Events()[0].Name)
+		assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		lwc := iox.NewLockedWriteCloser(iox.NopWriteCloser(&bytes.Buffer{}))
+		_, err := iox.CopyContext(ctx, lwc, iox.NopReadCloser(iox.ZeroReader()), iox.WithTracer(tracer))
+		require.ErrorIs(t, err, context.Canceled)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 2)
+		assert.Equal(t, codes.Error, spans[1].Status().Code)
+		assert.Equal(t, context.Canceled.Error(), spans[1].Status().Description)
+	})
+}
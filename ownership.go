@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import "io"
+
+// AbandonDrainLimit is the maximum number of bytes that [CopyContext] reads and
+// discards from a reader abandoned on cancellation (see [AbandonOnCancel]).
+const AbandonDrainLimit = 64 << 10
+
+// Ownership describes whether [CopyContext] closes the reader it copies from.
+type Ownership int
+
+const (
+	// Borrowed means that the reader is closed only when the context is canceled,
+	// to unblock the in-flight Read, and the caller is otherwise responsible for
+	// closing it. This is the default.
+	Borrowed = Ownership(iota)
+
+	// OwnedAlwaysClose means that the reader is always closed before returning,
+	// regardless of whether the copy succeeds, fails, or is canceled.
+	OwnedAlwaysClose
+
+	// NeverClose means that the reader is never closed, not even when the context
+	// is canceled, which is useful for readers shared with other code (e.g., a
+	// connection). On cancellation, the function returns without waiting for the
+	// in-flight Read, which keeps running in the background. The bytes it returns
+	// are discarded, since the writer is closed by then. This mode disables
+	// zero-copy fast paths, which read until EOF while holding the lock of the
+	// [*LockedWriteCloser], thus preventing the function from returning.
+	NeverClose
+
+	// AbandonOnCancel means that the reader is never closed, like [NeverClose], but,
+	// on cancellation, the copy abandons the stream between reads: it discards the
+	// bytes returned by the in-flight Read, then reads and discards at most
+	// [AbandonDrainLimit] more bytes, and then stops reading. This is useful for
+	// streams multiplexed over a shared session (e.g., HTTP/2 or yamux), where Close
+	// has session-wide effects, while bounded draining returns flow-control credit
+	// to the peer. Since the stream is abandoned at an unspecified offset, its owner
+	// should eventually reset or close it. This mode disables zero-copy fast paths,
+	// which read the stream until EOF while holding the lock of the
+	// [*LockedWriteCloser], thus preventing the copy from stopping.
+	AbandonOnCancel
+)
+
+// WithOwnership sets the [Ownership] of the reader passed to [CopyContext].
+//
+// The markers, such as [*BorrowedReadCloser], take precedence over it, also
+// when wrapped by transparent wrappers (see [As]). Wrappers that are not
+// transparent, e.g., those limiting the data, hide the marker, so the code
+// wrapping a marked reader that way MUST forward its ownership using WithOwnership.
+//
+// The default is [Borrowed].
+func WithOwnership(ownership Ownership) CopyOption {
+	return func(cfg *copyConfig) {
+		cfg.ownership = ownership
+	}
+}
+
+// OwnedReadCloser marks an [io.ReadCloser] as owned by the function it is passed to.
+//
+// [CopyContext] closes an OwnedReadCloser on any terminal event, as if using
+// [OwnedAlwaysClose], regardless of [WithOwnership].
+//
+// Construct using [NewOwnedReadCloser].
+type OwnedReadCloser struct {
+	io.ReadCloser
+}
+
+// NewOwnedReadCloser wraps rc and returns an [*OwnedReadCloser].
+func NewOwnedReadCloser(rc io.ReadCloser) *OwnedReadCloser {
+	return &OwnedReadCloser{rc}
+}
+
+// Unwrap returns the wrapped [io.ReadCloser] as an [io.Reader].
+func (r *OwnedReadCloser) Unwrap() io.Reader {
+	return r.ReadCloser
+}
+
+// BorrowedReadCloser marks an [io.ReadCloser] as borrowed by the function it is passed to.
+//
+// [CopyContext] never closes a BorrowedReadCloser, as if using [NeverClose],
+// regardless of [WithOwnership]. The code owning the reader remains responsible
+// for closing it, e.g., through its own reference to the reader.
+//
+// Construct using [NewBorrowedReadCloser].
+type BorrowedReadCloser struct {
+	io.ReadCloser
+}
+
+// NewBorrowedReadCloser wraps rc and returns a [*BorrowedReadCloser].
+func NewBorrowedReadCloser(rc io.ReadCloser) *BorrowedReadCloser {
+	return &BorrowedReadCloser{rc}
+}
+
+// Unwrap returns the wrapped [io.ReadCloser] as an [io.Reader].
+func (r *BorrowedReadCloser) Unwrap() io.Reader {
+	return r.ReadCloser
+}
+
+// CancelableReadCloser marks an [io.ReadCloser] as abandoned, rather than closed,
+// when the function it is passed to is canceled.
+//
+// [CopyContext] treats a CancelableReadCloser as if using [AbandonOnCancel],
+// regardless of [WithOwnership].
+//
+// Construct using [NewCancelableReadCloser].
+type CancelableReadCloser struct {
+	io.ReadCloser
+}
+
+// NewCancelableReadCloser wraps rc and returns a [*CancelableReadCloser].
+func NewCancelableReadCloser(rc io.ReadCloser) *CancelableReadCloser {
+	return &CancelableReadCloser{rc}
+}
+
+// Unwrap returns the wrapped [io.ReadCloser] as an [io.Reader].
+func (r *CancelableReadCloser) Unwrap() io.Reader {
+	return r.ReadCloser
+}
+
+// ownershipMarker is implemented by the types marking the [Ownership] of
+// an [io.ReadCloser], such as [*BorrowedReadCloser].
+type ownershipMarker interface {
+	markedOwnership() Ownership
+}
+
+// markedOwnership implements ownershipMarker.
+func (r *OwnedReadCloser) markedOwnership() Ownership {
+	return OwnedAlwaysClose
+}
+
+// markedOwnership implements ownershipMarker.
+func (r *BorrowedReadCloser) markedOwnership() Ownership {
+	return NeverClose
+}
+
+// markedOwnership implements ownershipMarker.
+func (r *CancelableReadCloser) markedOwnership() Ownership {
+	return AbandonOnCancel
+}
+
+// ownershipOf returns the [Ownership] of rc, which depends on the outermost marker
+// type, if any, and otherwise is the configured ownership.
+//
+// It looks for the marker through transparent wrappers (see [As]), such as the
+// ones returned by [JoinReadCloser] and [NopReadCloser].
+func (cfg *copyConfig) ownershipOf(rc io.ReadCloser) Ownership {
+	return ownershipOf(rc, cfg.ownership)
+}
+
+// ownershipOf returns the [Ownership] marked on rc, or fallback if unmarked.
+//
+// Use it to forward the ownership of a marked reader using [WithOwnership]
+// before wrapping it using a wrapper that is not transparent (see [As]).
+func ownershipOf(rc io.ReadCloser, fallback Ownership) Ownership {
+	if marker, ok := As[ownershipMarker](rc); ok {
+		return marker.markedOwnership()
+	}
+	return fallback
+}
+
+// drainAbandoned reads and discards at most [AbandonDrainLimit] bytes from the
+// reader abandoned by [CopyContext], looking through transparent wrappers (see [As]).
+func drainAbandoned(rc io.ReadCloser) {
+	io.CopyN(io.Discard, unwrapReader(rc), AbandonDrainLimit)
+}
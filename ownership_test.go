@@ -0,0 +1,242 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyContextWithOwnership(t *testing.T) {
+	// newReader returns a reader that either returns the payload or blocks
+	// until unblock is closed, along with the number of Close calls.
+	newReader := func(block bool, unblock chan struct{}) (io.ReadCloser, *atomic.Int64) {
+		closed := &atomic.Int64{}
+		r := strings.NewReader("hello")
+		return &iotest.FuncReadCloser{
+			ReadFunc: func(buf []byte) (int, error) {
+				if block {
+					<-unblock
+					return 0, io.EOF
+				}
+				return r.Read(buf)
+			},
+			CloseFunc: func() error {
+				if closed.Add(1) == 1 && block {
+					close(unblock)
+				}
+				return nil
+			},
+		}, closed
+	}
+
+	cases := []struct {
+		name            string
+		ownership       Ownership
+		closedOnSuccess int64
+		closedOnCancel  int64
+	}{
+		{"Borrowed", Borrowed, 0, 1},
+		{"OwnedAlwaysClose", OwnedAlwaysClose, 1, 1},
+		{"NeverClose", NeverClose, 0, 0},
+		{"AbandonOnCancel", AbandonOnCancel, 0, 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name+" on success", func(t *testing.T) {
+			rc, closed := newReader(false, nil)
+			buff := &bytes.Buffer{}
+			_, err := CopyContext(context.Background(),
+				NewLockedWriteCloser(NopWriteCloser(buff)), rc, WithOwnership(tc.ownership))
+			require.NoError(t, err)
+			assert.Equal(t, "hello", buff.String())
+			assert.Equal(t, tc.closedOnSuccess, closed.Load())
+		})
+
+		t.Run(tc.name+" on cancel", func(t *testing.T) {
+			unblock := make(chan struct{})
+			rc, closed := newReader(true, unblock)
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err := CopyContext(ctx,
+				NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{})), rc, WithOwnership(tc.ownership))
+			require.ErrorIs(t, err, context.Canceled)
+			assert.Equal(t, tc.closedOnCancel, closed.Load())
+			if tc.closedOnCancel == 0 {
+				close(unblock) // release the background goroutine
+			}
+		})
+	}
+}
+
+func TestCopyContextWithOwnershipMarkers(t *testing.T) {
+	newReader := func() (io.ReadCloser, *atomic.Int64) {
+		closed := &atomic.Int64{}
+		return &iotest.FuncReadCloser{
+			ReadFunc: strings.NewReader("hello").Read,
+			CloseFunc: func() error {
+				closed.Add(1)
+				return nil
+			},
+		}, closed
+	}
+
+	t.Run("OwnedReadCloser is closed on success", func(t *testing.T) {
+		rc, closed := newReader()
+		buff := &bytes.Buffer{}
+		_, err := CopyContext(context.Background(),
+			NewLockedWriteCloser(NopWriteCloser(buff)), NewOwnedReadCloser(rc))
+		require.NoError(t, err)
+		assert.Equal(t, "hello", buff.String())
+		assert.Equal(t, int64(1), closed.Load())
+	})
+
+	t.Run("BorrowedReadCloser is not closed on cancel", func(t *testing.T) {
+		unblock := make(chan struct{})
+		defer close(unblock)
+		closed := &atomic.Int64{}
+		rc := &iotest.FuncReadCloser{
+			ReadFunc: func(buf []byte) (int, error) {
+				<-unblock
+				return 0, io.EOF
+			},
+			CloseFunc: func() error {
+				closed.Add(1)
+				return nil
+			},
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := CopyContext(ctx, NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{})),
+			NewBorrowedReadCloser(rc), WithOwnership(OwnedAlwaysClose))
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, int64(0), closed.Load())
+	})
+
+	t.Run("markers are found through transparent wrappers", func(t *testing.T) {
+		unblock := make(chan struct{})
+		defer close(unblock)
+		closed := &atomic.Int64{}
+		rc := &iotest.FuncReadCloser{
+			ReadFunc: func(buf []byte) (int, error) {
+				<-unblock
+				return 0, io.EOF
+			},
+			CloseFunc: func() error {
+				closed.Add(1)
+				return nil
+			},
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := CopyContext(ctx, NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{})),
+			JoinReadCloser(NewBorrowedReadCloser(rc), rc))
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, int64(0), closed.Load())
+	})
+}
+
+func TestCopyContextWithAbandonOnCancel(t *testing.T) {
+	// Create an endless reader whose first Read blocks until released.
+	unblock := make(chan struct{})
+	var (
+		closed atomic.Int64
+		total  atomic.Int64
+	)
+	rc := &iotest.FuncReadCloser{
+		ReadFunc: func(buf []byte) (int, error) {
+			if total.Load() == 0 {
+				<-unblock
+			}
+			total.Add(int64(len(buf)))
+			return len(buf), nil
+		},
+		CloseFunc: func() error {
+			closed.Add(1)
+			return nil
+		},
+	}
+
+	buff := &bytes.Buffer{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	count, err := CopyContext(ctx, NewLockedWriteCloser(NopWriteCloser(buff)),
+		NewCancelableReadCloser(rc), WithOwnership(OwnedAlwaysClose))
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, count)
+
+	// Once released, the in-flight Read is discarded and the stream is drained
+	// for at most AbandonDrainLimit bytes, without closing it.
+	close(unblock)
+	assert.Eventually(t, func() bool {
+		return total.Load() >= AbandonDrainLimit
+	}, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	assert.LessOrEqual(t, total.Load(), int64(BufferSize())+AbandonDrainLimit)
+	assert.Equal(t, int64(0), closed.Load())
+	assert.Equal(t, 0, buff.Len())
+}
+
+// requireCanceledCopyReturns runs a [CopyContext] canceled while a Read that
+// nothing unblocks is in flight, and fails unless the copy returns promptly.
+func requireCanceledCopyReturns(t *testing.T, lwc *LockedWriteCloser, rc io.ReadCloser, opts ...CopyOption) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		_, err := CopyContext(ctx, lwc, rc, opts...)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(5 * time.Second):
+		t.Fatal("CopyContext did not return after cancellation")
+	}
+}
+
+func TestCopyContextWithNeverCloseAndStuckRead(t *testing.T) {
+	// Since the reader is not closed, nothing unblocks the in-flight Read, yet the
+	// copy must return without waiting for it, i.e., without holding the lock
+	// of the writer across reads.
+	t.Run("with a destination implementing io.ReaderFrom", func(t *testing.T) {
+		pr, pw := io.Pipe()
+		defer pw.Close()
+		requireCanceledCopyReturns(t, NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{})), pr,
+			WithOwnership(NeverClose))
+	})
+
+	t.Run("with a zero-copy pair", func(t *testing.T) {
+		_, server := newTCPConnPair(t) // closed on cleanup, which releases the Read
+		file, err := os.CreateTemp(t.TempDir(), "iox")
+		require.NoError(t, err)
+		requireCanceledCopyReturns(t, NewLockedWriteCloser(file), server, WithOwnership(NeverClose))
+	})
+}
+
+func TestCopyContextWithBorrowedReadCloserAndStuckRead(t *testing.T) {
+	// The marker implies NeverClose, hence the copy must not wait for the Read.
+	t.Run("with a destination implementing io.ReaderFrom", func(t *testing.T) {
+		pr, pw := io.Pipe()
+		defer pw.Close()
+		requireCanceledCopyReturns(t, DiscardWriteCloser(), NewBorrowedReadCloser(pr))
+	})
+
+	t.Run("with a zero-copy pair", func(t *testing.T) {
+		_, server := newTCPConnPair(t) // closed on cleanup, which releases the Read
+		file, err := os.CreateTemp(t.TempDir(), "iox")
+		require.NoError(t, err)
+		requireCanceledCopyReturns(t, NewLockedWriteCloser(file), NewBorrowedReadCloser(server))
+	})
+}
@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// DefaultPacedChunkSize is the default size of the chunks written by [*PacedWriteCloser].
+const DefaultPacedChunkSize = 1500
+
+// PacedWriteCloser is an [io.WriteCloser] writing at a constant bitrate.
+//
+// It splits writes into chunks and spaces them evenly in time, sleeping between
+// chunks, so the output is smooth. Unlike [RateLimitWriterMiddleware], which
+// bounds the average rate, it never bursts to catch up after being idle, which
+// is what media streaming and traffic shaping need. Sleeping honors the
+// context passed to the constructor.
+//
+// Not safe for concurrent use.
+//
+// Construct using [NewPacedWriteCloser].
+type PacedWriteCloser struct {
+	// ChunkSize is the size of the chunks written at evenly spaced times.
+	//
+	// [NewPacedWriteCloser] sets it to [DefaultPacedChunkSize].
+	//
+	// You MUST NOT modify it after the first Write.
+	ChunkSize int
+
+	bitsPerSecond int64
+	ctx           context.Context
+	next          time.Time
+	w             io.WriteCloser
+}
+
+// NewPacedWriteCloser wraps w and returns a [*PacedWriteCloser] writing
+// bitsPerSecond bits per second. Nonpositive values of bitsPerSecond
+// disable pacing.
+func NewPacedWriteCloser(ctx context.Context, w io.WriteCloser, bitsPerSecond int64) *PacedWriteCloser {
+	return &PacedWriteCloser{
+		ChunkSize:     DefaultPacedChunkSize,
+		bitsPerSecond: bitsPerSecond,
+		ctx:           ctx,
+		w:             w,
+	}
+}
+
+// Write implements [io.Writer].
+//
+// The returned error is either the context error or the one returned by the underlying writer.
+func (w *PacedWriteCloser) Write(data []byte) (int, error) {
+	if w.bitsPerSecond <= 0 {
+		return w.w.Write(data)
+	}
+	var total int
+	for len(data) > 0 {
+		// 1. wait for the chunk's slot without bursting after idle periods
+		now := time.Now()
+		if w.next.Before(now) {
+			w.next = now
+		}
+		if err := sleepContext(w.ctx, time.Until(w.next)); err != nil {
+			return total, err
+		}
+
+		// 2. write the chunk and schedule the next one
+		count, err := w.w.Write(data[:min(len(data), max(w.ChunkSize, 1))])
+		total += count
+		data = data[count:]
+		w.next = w.next.Add(time.Duration(int64(count) * 8 * int64(time.Second) / w.bitsPerSecond))
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// Close implements [io.Closer].
+func (w *PacedWriteCloser) Close() error {
+	return w.w.Close()
+}
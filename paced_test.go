@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacedWriteCloser(t *testing.T) {
+	t.Run("chunks are evenly spaced", func(t *testing.T) {
+		var times []time.Time
+		var buf bytes.Buffer
+		w := NewPacedWriteCloser(context.Background(), &iotest.FuncWriteCloser{
+			WriteFunc: func(b []byte) (int, error) {
+				times = append(times, time.Now())
+				return buf.Write(b)
+			},
+			CloseFunc: func() error { return nil },
+		}, 8*100*1000) // 100 KB/s
+		w.ChunkSize = 1000 // 10 ms per chunk
+
+		data := bytes.Repeat([]byte("x"), 5000)
+		count, err := w.Write(data)
+		require.NoError(t, err)
+		assert.Equal(t, 5000, count)
+		assert.Equal(t, data, buf.Bytes())
+		require.Len(t, times, 5)
+		assert.GreaterOrEqual(t, times[4].Sub(times[0]), 40*time.Millisecond)
+		require.NoError(t, w.Close())
+	})
+
+	t.Run("no bursts after idle periods", func(t *testing.T) {
+		var times []time.Time
+		w := NewPacedWriteCloser(context.Background(), &iotest.FuncWriteCloser{
+			WriteFunc: func(b []byte) (int, error) {
+				times = append(times, time.Now())
+				return len(b), nil
+			},
+			CloseFunc: func() error { return nil },
+		}, 8*100*1000)
+		w.ChunkSize = 1000
+
+		_, err := w.Write(make([]byte, 1000))
+		require.NoError(t, err)
+		time.Sleep(50 * time.Millisecond)
+		_, err = w.Write(make([]byte, 3000))
+		require.NoError(t, err)
+		require.Len(t, times, 4)
+		assert.GreaterOrEqual(t, times[3].Sub(times[1]), 20*time.Millisecond)
+	})
+
+	t.Run("sleeping honors the context", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		var buf bytes.Buffer
+		w := NewPacedWriteCloser(ctx, NopWriteCloser(&buf), 8) // 1 B/s
+		w.ChunkSize = 1
+		count, err := w.Write([]byte("iox"))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, "i", buf.String())
+	})
+
+	t.Run("write errors are returned", func(t *testing.T) {
+		expected := errors.New("mocked error")
+		w := NewPacedWriteCloser(context.Background(), &iotest.FuncWriteCloser{
+			WriteFunc: func(b []byte) (int, error) { return 1, expected },
+			CloseFunc: func() error { return nil },
+		}, 8*1000*1000)
+		count, err := w.Write([]byte("iox"))
+		require.ErrorIs(t, err, expected)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("nonpositive bitrates disable pacing", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewPacedWriteCloser(context.Background(), NopWriteCloser(&buf), 0)
+		count, err := w.Write(make([]byte, 1<<20))
+		require.NoError(t, err)
+		assert.Equal(t, 1<<20, count)
+	})
+}
@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package prommetrics adapts [iox.Collector] to Prometheus.
+package prommetrics
+
+import (
+	"io"
+	"time"
+
+	"github.com/bassosimone/iox"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector is an [iox.Collector] exporting Prometheus metrics.
+//
+// It maintains the following metrics, all labeled by operation ("op"):
+//
+//   - <namespace>_io_bytes_total counting the bytes transferred;
+//
+//   - <namespace>_io_operations_total counting the operations;
+//
+//   - <namespace>_io_errors_total counting the failed operations;
+//
+//   - <namespace>_io_duration_seconds measuring the operations duration.
+//
+// Construct using [NewCollector].
+type Collector struct {
+	bytes    *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+	ops      *prometheus.CounterVec
+}
+
+var _ iox.Collector = &Collector{}
+
+// NewCollector creates a new [*Collector] and registers its metrics with reg.
+//
+// The returned error is nil or the error occurred when registering the metrics.
+func NewCollector(reg prometheus.Registerer, namespace string) (*Collector, error) {
+	// 1. create the metrics
+	c := &Collector{
+		bytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "io_bytes_total",
+			Help:      "Number of bytes transferred.",
+		}, []string{"op"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "io_duration_seconds",
+			Help:      "Duration of I/O operations.",
+			Buckets:   prometheus.ExponentialBuckets(1e-6, 10, 8),
+		}, []string{"op"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "io_errors_total",
+			Help:      "Number of failed I/O operations.",
+		}, []string{"op"}),
+		ops: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "io_operations_total",
+			Help:      "Number of I/O operations.",
+		}, []string{"op"}),
+	}
+
+	// 2. register them and undo the registration on failure
+	metrics := []prometheus.Collector{c.bytes, c.duration, c.errors, c.ops}
+	for idx, m := range metrics {
+		if err := reg.Register(m); err != nil {
+			for _, registered := range metrics[:idx] {
+				reg.Unregister(registered)
+			}
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Observe implements [iox.Collector].
+func (c *Collector) Observe(op string, count int, err error, elapsed time.Duration) {
+	c.bytes.WithLabelValues(op).Add(float64(count))
+	c.ops.WithLabelValues(op).Inc()
+	if err != nil && err != io.EOF {
+		c.errors.WithLabelValues(op).Inc()
+	}
+	c.duration.WithLabelValues(op).Observe(elapsed.Seconds())
+}
@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package prommetrics
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bassosimone/iox"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollector(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	c, err := NewCollector(reg, "test")
+	require.NoError(t, err)
+
+	rc := iox.NewMeteredReadCloser(iox.NopReadCloser(strings.NewReader("hello")), c)
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+	c.Observe("write", 3, errors.New("mocked error"), time.Millisecond)
+
+	assert.Equal(t, float64(5), testutil.ToFloat64(c.bytes.WithLabelValues("read")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(c.ops.WithLabelValues("read")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(c.errors.WithLabelValues("read")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(c.errors.WithLabelValues("write")))
+
+	t.Run("duplicate registration fails", func(t *testing.T) {
+		_, err := NewCollector(reg, "test")
+		assert.Error(t, err)
+	})
+}
+
+func TestNewCollectorUndoesRegistrationOnFailure(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	conflict := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "test",
+		Name:      "io_errors_total",
+		Help:      "Number of failed I/O operations.",
+	}, []string{"op"})
+	require.NoError(t, reg.Register(conflict))
+
+	_, err := NewCollector(reg, "test")
+	require.Error(t, err)
+
+	require.True(t, reg.Unregister(conflict))
+	_, err = NewCollector(reg, "test")
+	require.NoError(t, err)
+}
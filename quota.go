@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+)
+
+// ErrQuotaExceeded is returned when I/O would exceed the budget of a [*Quota].
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// Quota is a byte budget shared by several streams, which is useful to enforce
+// a global cap, e.g., on the bytes downloaded by a multi-stream fetcher.
+//
+// Use [QuotaReadCloser], [QuotaWriteCloser], and [WithQuota] to charge the
+// bytes transferred by streams to the quota. Once the budget is exhausted,
+// further I/O fails with [ErrQuotaExceeded].
+//
+// Each operation is limited to the budget remaining when it starts and charges
+// the bytes actually transferred, so operations racing on different streams may
+// exceed the budget by at most the size of the racing operations.
+//
+// All methods are safe for concurrent use.
+//
+// Construct using [NewQuota].
+type Quota struct {
+	max  int64
+	used atomic.Int64
+}
+
+// NewQuota returns a new [*Quota] allowing to transfer maxBytes bytes.
+func NewQuota(maxBytes int64) *Quota {
+	return &Quota{max: maxBytes}
+}
+
+// Used returns the number of bytes charged so far.
+func (q *Quota) Used() int64 {
+	return q.used.Load()
+}
+
+// Remaining returns the number of bytes that may still be transferred.
+func (q *Quota) Remaining() int64 {
+	return max(q.max-q.used.Load(), 0)
+}
+
+// QuotaReadCloser wraps rc such that reads charge the bytes read to q.
+//
+// Reads are limited to the remaining budget and fail with [ErrQuotaExceeded] once
+// the budget is exhausted and rc has more data. Reading exactly the remaining
+// budget and then EOF is not an error. Close forwards to rc.
+func QuotaReadCloser(rc io.ReadCloser, q *Quota) io.ReadCloser {
+	return readCloser{&quotaReader{q, rc}, rc}
+}
+
+// quotaReader is the [io.Reader] used by [QuotaReadCloser].
+type quotaReader struct {
+	q *Quota
+	r io.Reader
+}
+
+// Read implements [io.Reader].
+func (r *quotaReader) Read(buf []byte) (int, error) {
+	if len(buf) <= 0 {
+		return 0, nil
+	}
+	remaining := r.q.Remaining()
+	if remaining <= 0 {
+		more, err := probeMore(r.r)
+		if more {
+			return 0, ErrQuotaExceeded
+		}
+		return 0, err
+	}
+	count, err := r.r.Read(buf[:min(int64(len(buf)), remaining)])
+	r.q.used.Add(int64(count))
+	return count, err
+}
+
+// QuotaWriteCloser wraps w such that writes charge the bytes written to q.
+//
+// When a write exceeds the remaining budget, it writes as many bytes as the budget
+// allows and fails with [ErrQuotaExceeded]. Close forwards to w.
+func QuotaWriteCloser(w io.WriteCloser, q *Quota) io.WriteCloser {
+	return writeCloser{&quotaWriter{q, w}, w}
+}
+
+// quotaWriter is the [io.Writer] used by [QuotaWriteCloser].
+type quotaWriter struct {
+	q *Quota
+	w io.Writer
+}
+
+// Write implements [io.Writer].
+func (w *quotaWriter) Write(data []byte) (int, error) {
+	remaining := w.q.Remaining()
+	if remaining <= 0 && len(data) > 0 {
+		return 0, ErrQuotaExceeded
+	}
+	count, err := w.w.Write(data[:min(int64(len(data)), remaining)])
+	w.q.used.Add(int64(count))
+	if err == nil && count < len(data) {
+		err = ErrQuotaExceeded
+	}
+	return count, err
+}
+
+// WithQuota causes the copy to charge the bytes it reads to q.
+//
+// It wraps the source using [QuotaReadCloser], so the copy fails with [ErrQuotaExceeded]
+// once the budget is exhausted. Use the same [*Quota] with several copies to enforce
+// an aggregate cap. Charging disables the zero-copy fast path of [CopyContext].
+func WithQuota(q *Quota) CopyOption {
+	return func(cfg *copyConfig) {
+		cfg.wrapReader = append(cfg.wrapReader, func(rc io.ReadCloser) io.ReadCloser {
+			return QuotaReadCloser(rc, q)
+		})
+	}
+}
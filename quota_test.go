@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotaReadCloser(t *testing.T) {
+	t.Run("reading within the budget", func(t *testing.T) {
+		q := NewQuota(10)
+		data, err := io.ReadAll(QuotaReadCloser(NopReadCloser(strings.NewReader("0123456789")), q))
+		require.NoError(t, err)
+		assert.Equal(t, "0123456789", string(data))
+		assert.Equal(t, int64(10), q.Used())
+		assert.Equal(t, int64(0), q.Remaining())
+	})
+
+	t.Run("exceeding the budget", func(t *testing.T) {
+		q := NewQuota(4)
+		data, err := io.ReadAll(QuotaReadCloser(NopReadCloser(strings.NewReader("0123456789")), q))
+		require.ErrorIs(t, err, ErrQuotaExceeded)
+		assert.Equal(t, "0123", string(data))
+		assert.Equal(t, int64(4), q.Used())
+	})
+
+	t.Run("sharing the budget", func(t *testing.T) {
+		q := NewQuota(15)
+		first, err := io.ReadAll(QuotaReadCloser(NopReadCloser(strings.NewReader("0123456789")), q))
+		require.NoError(t, err)
+		second, err := io.ReadAll(QuotaReadCloser(NopReadCloser(strings.NewReader("0123456789")), q))
+		require.ErrorIs(t, err, ErrQuotaExceeded)
+		assert.Equal(t, 15, len(first)+len(second))
+	})
+}
+
+func TestQuotaWriteCloser(t *testing.T) {
+	q := NewQuota(6)
+	var buf bytes.Buffer
+	w := QuotaWriteCloser(NopWriteCloser(&buf), q)
+	count, err := w.Write([]byte("0123"))
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+	count, err = w.Write([]byte("4567"))
+	require.ErrorIs(t, err, ErrQuotaExceeded)
+	assert.Equal(t, 2, count)
+	count, err = w.Write([]byte("89"))
+	require.ErrorIs(t, err, ErrQuotaExceeded)
+	assert.Equal(t, 0, count)
+	count, err = w.Write(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, "012345", buf.String())
+	require.NoError(t, w.Close())
+}
+
+func TestWithQuota(t *testing.T) {
+	q := NewQuota(1 << 20)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var total int
+	var failures int
+	for range 4 {
+		wg.Go(func() {
+			var buf bytes.Buffer
+			count, err := CopyContext(context.Background(), NewLockedWriteCloser(NopWriteCloser(&buf)),
+				NopReadCloser(bytes.NewReader(make([]byte, 512<<10))), WithQuota(q))
+			mu.Lock()
+			defer mu.Unlock()
+			total += count
+			if err != nil {
+				assert.ErrorIs(t, err, ErrQuotaExceeded)
+				failures++
+			}
+		})
+	}
+	wg.Wait()
+	assert.Equal(t, int64(total), q.Used())
+	assert.GreaterOrEqual(t, total, 1<<20)
+	assert.GreaterOrEqual(t, failures, 2)
+}
@@ -0,0 +1,149 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// contextError returns the error explaining why ctx is done, or nil.
+//
+// When ctx has been canceled with a cause (see [context.WithCancelCause]), the
+// returned error wraps both the context error and the cause, such that callers
+// can use [errors.Is] with either of them.
+func contextError(ctx context.Context) error {
+	err := ctx.Err()
+	if err == nil {
+		return nil
+	}
+	if cause := context.Cause(ctx); cause != nil && cause != err {
+		return fmt.Errorf("%w: %w", err, cause)
+	}
+	return err
+}
+
+// readContext performs a single context-interruptible Read.
+//
+// It reads from rc in a background goroutine using an internal buffer, so buf is
+// never written after readContext returns. It only closes rc when the context is
+// canceled, to unblock the in-flight Read in the background goroutine.
+//
+// The returned error is either caused by I/O or by the context.
+func readContext(ctx context.Context, rc io.ReadCloser, buf []byte) (int, error) {
+	// 1. bail early if the context is already done
+	if err := contextError(ctx); err != nil {
+		return 0, err
+	}
+
+	// 2. prepare for receiving the background read result
+	type result struct {
+		count int
+		err   error
+	}
+	resch := make(chan result, 1)
+	bp := getBuffer()
+	if len(*bp) < len(buf) {
+		data := make([]byte, len(buf))
+		bp = &data
+	}
+	data := (*bp)[:len(buf)]
+
+	// 3. do in background so we can be interrupted
+	go func() {
+		count, err := rc.Read(data)
+		resch <- result{count, err}
+	}()
+
+	// 4. wait and collect the result
+	select {
+	case <-ctx.Done():
+		rc.Close()
+		return 0, contextError(ctx)
+	case res := <-resch:
+		count := copy(buf, data[:res.count])
+		putBuffer(bp)
+		return count, res.err
+	}
+}
+
+// readDeadliner is implemented by readers supporting read deadlines (e.g., [net.Conn]).
+type readDeadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+// aLongTimeAgo is a deadline in the past used to unblock in-flight I/O.
+var aLongTimeAgo = time.Unix(1, 0)
+
+// ReadContext performs a single context-interruptible Read.
+//
+// When rc supports read deadlines (e.g., [net.Conn]), ReadContext reads directly
+// into buf and, on context cancellation, sets a read deadline in the past to unblock
+// the in-flight Read, then clears the deadline, so rc remains usable. Otherwise,
+// it reads in a background goroutine using an internal buffer, so buf is never
+// written after ReadContext returns, and closes rc on context cancellation to unblock
+// the in-flight Read. In both cases, rc is NOT closed on success and the caller
+// MUST close it (e.g., via defer).
+//
+// The returned count follows the [io.Reader] semantics, while the returned error
+// is either caused by I/O or by the context.
+func ReadContext(ctx context.Context, rc io.ReadCloser, buf []byte) (int, error) {
+	// 1. fallback to closing when rc does not support deadlines
+	rd, ok := rc.(readDeadliner)
+	if !ok {
+		return readContext(ctx, rc, buf)
+	}
+
+	// 2. bail early if the context is already done
+	if err := contextError(ctx); err != nil {
+		return 0, err
+	}
+
+	// 3. arrange for expiring the deadline on context cancellation
+	expired := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		defer close(expired)
+		rd.SetReadDeadline(aLongTimeAgo)
+	})
+
+	// 4. read and, if we have been interrupted, clear the deadline
+	count, err := rc.Read(buf)
+	if !stop() {
+		<-expired
+		rd.SetReadDeadline(time.Time{})
+		if err != nil {
+			err = contextError(ctx)
+		}
+	}
+	return count, err
+}
+
+// contextReader adapts an [io.ReadCloser] to be an [io.Reader] whose
+// reads are context-interruptible (see readContext).
+type contextReader struct {
+	ctx context.Context
+	rc  io.ReadCloser
+}
+
+// Read implements [io.Reader].
+func (r contextReader) Read(buf []byte) (int, error) {
+	return readContext(r.ctx, r.rc, buf)
+}
+
+// ReadFullContext is a context-interruptible variant of [io.ReadFull].
+//
+// It reads exactly len(buf) bytes from rc. Each Read runs in a background
+// goroutine using an internal buffer, so buf is never written after
+// ReadFullContext returns. It only closes rc when the context is canceled,
+// to unblock any in-flight Read in the background goroutine.
+//
+// On success, rc is NOT closed. The caller MUST ensure rc is closed after
+// ReadFullContext returns (e.g., via defer).
+//
+// The returned count and error follow the [io.ReadFull] semantics, except
+// that the error may also be caused by the context.
+func ReadFullContext(ctx context.Context, rc io.ReadCloser, buf []byte) (int, error) {
+	return io.ReadFull(contextReader{ctx, rc}, buf)
+}
@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadFullContextSuccess(t *testing.T) {
+	// Use a reader returning one byte at a time.
+	sr := strings.NewReader("iox-context")
+	rc := &iotest.FuncReadCloser{
+		ReadFunc: func(b []byte) (int, error) {
+			return sr.Read(b[:1])
+		},
+		CloseFunc: func() error { return nil },
+	}
+
+	buf := make([]byte, 3)
+	count, err := ReadFullContext(context.Background(), rc, buf)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, "iox", string(buf))
+}
+
+func TestReadFullContextWithCancelledContext(t *testing.T) {
+	// Create a reader that blocks until Close is called.
+	insideReader := make(chan struct{})
+	unblockReader := make(chan struct{})
+	rc := &iotest.FuncReadCloser{
+		ReadFunc: func(b []byte) (int, error) {
+			close(insideReader)
+			<-unblockReader
+			return copy(b, "late"), nil
+		},
+		CloseFunc: func() error {
+			close(unblockReader)
+			return nil
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-insideReader
+		cancel()
+	}()
+
+	// The buffer must not be written after we return.
+	buf := make([]byte, 4)
+	count, err := ReadFullContext(ctx, rc, buf)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, make([]byte, 4), buf)
+}
+
+func TestReadFullContextWithShortStream(t *testing.T) {
+	count, err := ReadFullContext(context.Background(), NopReadCloser(strings.NewReader("io")), make([]byte, 3))
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Equal(t, 2, count)
+}
+
+func TestReadContext(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		rc := NopReadCloser(strings.NewReader("iox"))
+		buf := make([]byte, 8)
+		count, err := ReadContext(context.Background(), rc, buf)
+		require.NoError(t, err)
+		assert.Equal(t, "iox", string(buf[:count]))
+	})
+
+	t.Run("cancellation closes readers without deadlines", func(t *testing.T) {
+		unblock := make(chan struct{})
+		rc := &iotest.FuncReadCloser{
+			ReadFunc: func(b []byte) (int, error) {
+				<-unblock
+				return 0, io.ErrClosedPipe
+			},
+			CloseFunc: func() error {
+				close(unblock)
+				return nil
+			},
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		count, err := ReadContext(ctx, rc, make([]byte, 8))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, 0, count)
+		<-unblock
+	})
+
+	t.Run("cancellation expires the deadline and keeps the conn usable", func(t *testing.T) {
+		conn1, conn2 := net.Pipe()
+		defer conn1.Close()
+		defer conn2.Close()
+
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cause := errors.New("mocked cause")
+		time.AfterFunc(10*time.Millisecond, func() { cancel(cause) })
+		buf := make([]byte, 8)
+		_, err := ReadContext(ctx, conn1, buf)
+		require.ErrorIs(t, err, context.Canceled)
+		require.ErrorIs(t, err, cause)
+
+		go conn2.Write([]byte("iox"))
+		count, err := ReadContext(context.Background(), conn1, buf)
+		require.NoError(t, err)
+		assert.Equal(t, "iox", string(buf[:count]))
+	})
+
+	t.Run("already canceled context", func(t *testing.T) {
+		conn1, conn2 := net.Pipe()
+		defer conn1.Close()
+		defer conn2.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := ReadContext(ctx, conn1, make([]byte, 8))
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
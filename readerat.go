@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// CopyReaderAtContext copies size bytes from src into dst using parallel workers.
+//
+// It splits the input into chunks, and each worker reads a chunk using ReadAt and
+// writes it at the same offset using WriteAt. Therefore, dst MUST support parallel
+// WriteAt calls for non-overlapping ranges, as documented by [io.WriterAt].
+//
+// This function honors [WithChunkSize] and [WithConcurrency].
+//
+// Because ReadAt and WriteAt calls cannot be interrupted, the context is checked
+// before copying each chunk. CopyReaderAtContext always waits for all the workers
+// to terminate before returning, so dst is not written after it returns.
+//
+// The returned count is the number of bytes written. The returned error is nil,
+// [io.ErrUnexpectedEOF] if src is shorter than size, or the first error caused
+// by I/O or by the context.
+func CopyReaderAtContext(parent context.Context, dst io.WriterAt, src io.ReaderAt,
+	size int64, opts ...CopyOption) (int64, error) {
+	// 1. create a context that we cancel on the first error
+	cfg := newCopyConfig(opts...)
+	ctx, cancel := context.WithCancelCause(parent)
+	defer cancel(nil)
+
+	// 2. start the workers picking chunks in order
+	var (
+		count atomic.Int64
+		next  atomic.Int64
+		wg    sync.WaitGroup
+	)
+	nchunks := size / cfg.chunkSize
+	if size%cfg.chunkSize > 0 {
+		nchunks++
+	}
+	for range min(int64(cfg.concurrency), nchunks) {
+		wg.Go(func() {
+			buf := make([]byte, min(cfg.chunkSize, size))
+			for {
+				index := next.Add(1) - 1
+				if index >= nchunks {
+					return
+				}
+				if ctx.Err() != nil {
+					return
+				}
+				offset := index * cfg.chunkSize
+				n, err := copyChunk(dst, src, buf[:min(cfg.chunkSize, size-offset)], offset)
+				count.Add(int64(n))
+				if err != nil {
+					cancel(err)
+					return
+				}
+			}
+		})
+	}
+
+	// 3. wait for the workers and report the first error, which
+	// is the parent's error when the parent context is done first
+	wg.Wait()
+	err := context.Cause(ctx)
+	if err != nil && err == context.Cause(parent) {
+		err = contextError(parent)
+	}
+	return count.Load(), err
+}
+
+// copyChunk copies a single chunk for [CopyReaderAtContext].
+func copyChunk(dst io.WriterAt, src io.ReaderAt, buf []byte, offset int64) (int, error) {
+	n, err := src.ReadAt(buf, offset)
+	if errors.Is(err, io.EOF) {
+		err = nil
+		if n < len(buf) {
+			err = io.ErrUnexpectedEOF
+		}
+	}
+	if n <= 0 {
+		return 0, err
+	}
+	written, werr := dst.WriteAt(buf[:n], offset)
+	if werr != nil {
+		return written, werr
+	}
+	return written, err
+}
+
+// SectionReadCloser returns an [io.ReadCloser] that reads from ra starting at
+// offset off and stops with EOF after n bytes, while Close forwards to closer.
+//
+// This is the [io.ReaderAt] counterpart of [LimitReadCloser]. Like [*io.SectionReader],
+// the returned value also implements [io.Seeker] and [io.ReaderAt].
+func SectionReadCloser(ra io.ReaderAt, off, n int64, closer io.Closer) io.ReadCloser {
+	return sectionReadCloser{io.NewSectionReader(ra, off, n), closer}
+}
+
+// sectionReadCloser adapts an [*io.SectionReader] plus an [io.Closer] to an [io.ReadCloser].
+type sectionReadCloser struct {
+	*io.SectionReader
+	io.Closer
+}
+
+// ReaderAtCloser is an [io.ReaderAt] that can be closed.
+type ReaderAtCloser interface {
+	io.ReaderAt
+	io.Closer
+}
+
+// ReadAtContext is a context-interruptible variant of ReadAt.
+//
+// It reads from rac in a background goroutine. It only closes rac when the context
+// is canceled, to unblock any in-flight ReadAt in the background goroutine.
+//
+// On success, rac is NOT closed. The caller MUST ensure rac is closed after
+// ReadAtContext returns (e.g., via defer).
+//
+// The background goroutine reads into an internal buffer, so buf is never
+// written after ReadAtContext returns.
+//
+// The returned error is either caused by I/O or by the context.
+func ReadAtContext(ctx context.Context, rac ReaderAtCloser, buf []byte, off int64) (int, error) {
+	// 1. prepare for receiving the background read result
+	type result struct {
+		count int
+		err   error
+	}
+	resch := make(chan result, 1)
+	data := make([]byte, len(buf))
+
+	// 2. do in background so we can be interrupted
+	go func() {
+		count, err := rac.ReadAt(data, off)
+		resch <- result{count, err}
+	}()
+
+	// 3. wait and collect the result
+	select {
+	case <-ctx.Done():
+		rac.Close()
+		return 0, contextError(ctx)
+	case res := <-resch:
+		return copy(buf, data[:res.count]), res.err
+	}
+}
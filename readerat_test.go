@@ -0,0 +1,190 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memWriterAt is an [io.WriterAt] writing into a fixed-size buffer.
+type memWriterAt struct {
+	buf []byte
+	err error
+	mu  sync.Mutex
+}
+
+// WriteAt implements [io.WriterAt].
+func (w *memWriterAt) WriteAt(data []byte, offset int64) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return 0, w.err
+	}
+	return copy(w.buf[offset:], data), nil
+}
+
+func TestCopyReaderAtContextSuccess(t *testing.T) {
+	payload := strings.Repeat("0123456789", 100)
+	dst := &memWriterAt{buf: make([]byte, len(payload))}
+
+	count, err := CopyReaderAtContext(context.Background(), dst, strings.NewReader(payload),
+		int64(len(payload)), WithChunkSize(64), WithConcurrency(3))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(payload)), count)
+	assert.Equal(t, payload, string(dst.buf))
+}
+
+func TestCopyReaderAtContextWithLargeChunkSize(t *testing.T) {
+	// Workers do not allocate more than size bytes, and huge values are clamped.
+	payload := "0123456789"
+	dst := &memWriterAt{buf: make([]byte, len(payload))}
+
+	count, err := CopyReaderAtContext(context.Background(), dst, strings.NewReader(payload),
+		int64(len(payload)), WithChunkSize(1<<62))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(payload)), count)
+	assert.Equal(t, payload, string(dst.buf))
+	assert.Equal(t, int64(MaxChunkSize), newCopyConfig(WithChunkSize(1<<62)).chunkSize)
+}
+
+func TestCopyReaderAtContextWithShortSource(t *testing.T) {
+	payload := strings.Repeat("x", 100)
+	dst := &memWriterAt{buf: make([]byte, 200)}
+
+	count, err := CopyReaderAtContext(context.Background(), dst, strings.NewReader(payload),
+		200, WithChunkSize(16))
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.LessOrEqual(t, count, int64(100))
+}
+
+func TestCopyReaderAtContextWithWriteError(t *testing.T) {
+	expected := errors.New("mocked write error")
+	dst := &memWriterAt{buf: make([]byte, 100), err: expected}
+
+	count, err := CopyReaderAtContext(context.Background(), dst, bytes.NewReader(make([]byte, 100)),
+		100, WithChunkSize(10))
+	require.ErrorIs(t, err, expected)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestCopyReaderAtContextWithCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	dst := &memWriterAt{buf: make([]byte, 100)}
+
+	count, err := CopyReaderAtContext(ctx, dst, bytes.NewReader(make([]byte, 100)), 100)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestCopyReaderAtContextWithCancelCause(t *testing.T) {
+	cause := errors.New("shutting down")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(cause)
+	dst := &memWriterAt{buf: make([]byte, 100)}
+
+	_, err := CopyReaderAtContext(ctx, dst, bytes.NewReader(make([]byte, 100)), 100)
+	require.ErrorIs(t, err, context.Canceled)
+	require.ErrorIs(t, err, cause)
+}
+
+func TestSectionReadCloser(t *testing.T) {
+	closed := false
+	src := strings.NewReader("0123456789")
+	src2 := SectionReadCloser(src, 2, 5, &iotest.FuncReadCloser{
+		CloseFunc: func() error {
+			closed = true
+			return nil
+		},
+	})
+
+	data, err := io.ReadAll(src2)
+	require.NoError(t, err)
+	assert.Equal(t, "23456", string(data))
+
+	// The section reader can still be rewound.
+	seeker, ok := src2.(io.Seeker)
+	require.True(t, ok)
+	_, err = seeker.Seek(3, io.SeekStart)
+	require.NoError(t, err)
+	data, err = io.ReadAll(src2)
+	require.NoError(t, err)
+	assert.Equal(t, "56", string(data))
+
+	require.NoError(t, src2.Close())
+	assert.True(t, closed)
+}
+
+// funcReaderAtCloser is a [ReaderAtCloser] implemented using funcs.
+type funcReaderAtCloser struct {
+	readAt func(b []byte, off int64) (int, error)
+	close  func() error
+}
+
+// ReadAt implements [io.ReaderAt].
+func (r *funcReaderAtCloser) ReadAt(b []byte, off int64) (int, error) {
+	return r.readAt(b, off)
+}
+
+// Close implements [io.Closer].
+func (r *funcReaderAtCloser) Close() error {
+	return r.close()
+}
+
+func TestReadAtContextSuccess(t *testing.T) {
+	closed := false
+	rac := &funcReaderAtCloser{
+		readAt: strings.NewReader("0123456789").ReadAt,
+		close: func() error {
+			closed = true
+			return nil
+		},
+	}
+
+	buf := make([]byte, 4)
+	count, err := ReadAtContext(context.Background(), rac, buf, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+	assert.Equal(t, "3456", string(buf))
+
+	// ReadAtContext MUST NOT close the reader on success.
+	assert.False(t, closed)
+}
+
+func TestReadAtContextWithCancelledContext(t *testing.T) {
+	// Create a reader that blocks until Close is called.
+	insideReader := make(chan struct{})
+	unblockReader := make(chan struct{})
+	rac := &funcReaderAtCloser{
+		readAt: func(b []byte, off int64) (int, error) {
+			close(insideReader)
+			<-unblockReader
+			return 0, io.EOF
+		},
+		close: func() error {
+			close(unblockReader)
+			return nil
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-insideReader
+		cancel()
+	}()
+
+	count, err := ReadAtContext(ctx, rac, make([]byte, 4), 0)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, count)
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+)
+
+// CopyRecordsContext is like [CopyContext] but also invokes fn for each record.
+//
+// Records are delimited by delim, which fn does not receive. At EOF, the last
+// record is passed to fn even when it is not terminated by delim. All bytes,
+// including delimiters, are streamed into lwc unmodified, which allows processing
+// records inline (e.g., counting or inspecting them) without a second pass.
+//
+// Records longer than the maximum record size (see [WithMaxRecordSize]) cause
+// the copy to fail with [bufio.ErrTooLong]. If fn fails, the copy stops without
+// writing the chunk read last, which contains the end of the failing record, and
+// returns fn's error.
+//
+// The fn callback runs in the background goroutine performing the copy and
+// MUST NOT retain the record after returning. CopyRecordsContext waits for any
+// in-flight fn invocation to complete before returning and fn is never invoked
+// after CopyRecordsContext returns.
+//
+// The ownership rules for rc and lwc are the same of [CopyContext]. This function
+// honors the same options of [CopyContext] and [WithMaxRecordSize].
+func CopyRecordsContext(ctx context.Context, lwc *LockedWriteCloser, rc io.ReadCloser,
+	delim byte, fn func(record []byte) error, opts ...CopyOption) (int, error) {
+	// 1. wrap rc to split records, forwarding its ownership, which the
+	// wrapper hides, since it is not transparent (see [As])
+	cfg := newCopyConfig(opts...)
+	rr := &recordReader{
+		delim:   delim,
+		fn:      fn,
+		maxSize: cfg.maxRecordSize,
+		r:       rc,
+	}
+	opts = append(slices.Clip(opts), WithOwnership(cfg.ownershipOf(rc)))
+
+	// 2. copy and make sure fn is not invoked after returning
+	count, err := CopyContext(ctx, lwc, JoinReadCloser(rr, rc), opts...)
+	rr.stop()
+	return count, err
+}
+
+// recordReader is the [io.Reader] splitting records for [CopyRecordsContext].
+type recordReader struct {
+	delim   byte
+	fn      func(record []byte) error
+	maxSize int
+	mu      sync.Mutex
+	pending []byte
+	r       io.Reader
+	stopped bool
+}
+
+// Read implements [io.Reader].
+func (rr *recordReader) Read(buf []byte) (int, error) {
+	count, err := rr.r.Read(buf)
+	if cberr := rr.process(buf[:count], errors.Is(err, io.EOF)); cberr != nil {
+		return 0, cberr
+	}
+	return count, err
+}
+
+// process splits data into records, invoking the callback for each of them.
+func (rr *recordReader) process(data []byte, atEOF bool) error {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	if rr.stopped {
+		return context.Canceled
+	}
+	for len(data) > 0 {
+		idx := bytes.IndexByte(data, rr.delim)
+		if idx < 0 {
+			break
+		}
+		record := data[:idx]
+		if len(rr.pending) > 0 {
+			record = append(rr.pending, record...)
+			rr.pending = rr.pending[:0]
+		}
+		if len(record) > rr.maxSize {
+			return bufio.ErrTooLong
+		}
+		if err := rr.fn(record); err != nil {
+			return err
+		}
+		data = data[idx+1:]
+	}
+	rr.pending = append(rr.pending, data...)
+	if len(rr.pending) > rr.maxSize {
+		return bufio.ErrTooLong
+	}
+	if atEOF && len(rr.pending) > 0 {
+		record := rr.pending
+		rr.pending = nil
+		return rr.fn(record)
+	}
+	return nil
+}
+
+// stop ensures the callback is not invoked anymore.
+func (rr *recordReader) stop() {
+	rr.mu.Lock()
+	rr.stopped = true
+	rr.mu.Unlock()
+}
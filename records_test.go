@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSmallReadsReadCloser returns a reader for payload returning at most n bytes per read.
+func newSmallReadsReadCloser(payload string, n int) *iotest.FuncReadCloser {
+	sr := strings.NewReader(payload)
+	return &iotest.FuncReadCloser{
+		ReadFunc: func(b []byte) (int, error) {
+			return sr.Read(b[:min(len(b), n)])
+		},
+		CloseFunc: func() error { return nil },
+	}
+}
+
+func TestCopyRecordsContextSuccess(t *testing.T) {
+	// Use small reads so that records span multiple reads.
+	const payload = "alpha;beta;;gamma-delta;tail"
+	buff := &bytes.Buffer{}
+	var records []string
+	count, err := CopyRecordsContext(context.Background(), NewLockedWriteCloser(NopWriteCloser(buff)),
+		newSmallReadsReadCloser(payload, 3), ';', func(record []byte) error {
+			records = append(records, string(record))
+			return nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, len(payload), count)
+	assert.Equal(t, payload, buff.String())
+	assert.Equal(t, []string{"alpha", "beta", "", "gamma-delta", "tail"}, records)
+}
+
+func TestCopyRecordsContextErrors(t *testing.T) {
+	t.Run("when the callback fails", func(t *testing.T) {
+		expected := errors.New("mocked callback error")
+		buff := &bytes.Buffer{}
+		_, err := CopyRecordsContext(context.Background(), NewLockedWriteCloser(NopWriteCloser(buff)),
+			newSmallReadsReadCloser("ok\nbad\n", 3), '\n', func(record []byte) error {
+				if string(record) == "bad" {
+					return expected
+				}
+				return nil
+			})
+		require.ErrorIs(t, err, expected)
+
+		// The chunk terminating the failing record is not written.
+		assert.Equal(t, "ok\nbad", buff.String())
+	})
+
+	t.Run("with a record too long", func(t *testing.T) {
+		buff := &bytes.Buffer{}
+		_, err := CopyRecordsContext(context.Background(), NewLockedWriteCloser(NopWriteCloser(buff)),
+			newSmallReadsReadCloser(strings.Repeat("x", 100), 7), '\n', func(record []byte) error {
+				return nil
+			}, WithMaxRecordSize(16))
+		require.ErrorIs(t, err, bufio.ErrTooLong)
+	})
+
+	t.Run("with a cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		unblockReader := make(chan struct{})
+		rc := &iotest.FuncReadCloser{
+			ReadFunc: func(b []byte) (int, error) {
+				<-unblockReader
+				return 0, io.EOF
+			},
+			CloseFunc: func() error {
+				close(unblockReader)
+				return nil
+			},
+		}
+		_, err := CopyRecordsContext(ctx, NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{})), rc, '\n',
+			func(record []byte) error {
+				panic("should not be called")
+			})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("BorrowedReadCloser is not closed on cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		unblockReader := make(chan struct{})
+		defer close(unblockReader)
+		closed := &atomic.Int64{}
+		rc := &iotest.FuncReadCloser{
+			ReadFunc: func(b []byte) (int, error) {
+				<-unblockReader
+				return 0, io.EOF
+			},
+			CloseFunc: func() error {
+				closed.Add(1)
+				return nil
+			},
+		}
+		_, err := CopyRecordsContext(ctx, NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{})),
+			NewBorrowedReadCloser(rc), '\n', func(record []byte) error { return nil })
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, int64(0), closed.Load())
+	})
+}
@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+)
+
+// RelayResult contains the result of [DuplexCopyContext].
+type RelayResult struct {
+	// Conn1ToConn2 is the result of copying from conn1 to conn2,
+	// whose Label is "conn1->conn2".
+	Conn1ToConn2 CopyResult
+
+	// Conn2ToConn1 is the result of copying from conn2 to conn1,
+	// whose Label is "conn2->conn1".
+	Conn2ToConn1 CopyResult
+
+	// FirstDone is the Label of the direction that finished first.
+	FirstDone string
+}
+
+// DuplexCopyContext copies between conn1 and conn2 in both directions concurrently.
+//
+// It returns when both directions have finished. When a direction reaches EOF
+// and its destination supports half-close (e.g., [*net.TCPConn]), it propagates
+// the half-close and lets the other direction continue. Otherwise, as soon as
+// either direction finishes, or the context is canceled, it tears down the other
+// direction. On return, it always closes both conn1 and conn2.
+//
+// The returned [RelayResult] contains the per-direction bytes, timings, and errors,
+// along with which direction finished first, which is useful to produce access logs.
+// A direction torn down because the other one finished reports a nil error, since
+// it did not fail on its own.
+//
+// The options are passed to the [CopyContext] call copying each direction.
+func DuplexCopyContext(ctx context.Context, conn1, conn2 io.ReadWriteCloser, opts ...CopyOption) RelayResult {
+	// 1. create a context canceled as soon as either direction finishes
+	relayctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// 2. copy in both directions in the background
+	var (
+		once   sync.Once
+		result RelayResult
+		wg     sync.WaitGroup
+	)
+	finished := func(label string) {
+		once.Do(func() { result.FirstDone = label })
+	}
+	wg.Go(func() {
+		opts := append(slices.Clip(opts), withSpanName("iox.DuplexCopyContext conn1->conn2"), withLabel("conn1->conn2"))
+		result.Conn1ToConn2 = relayCopy(ctx, relayctx, cancel, conn2, conn1, opts...)
+		finished(result.Conn1ToConn2.Label)
+	})
+	wg.Go(func() {
+		opts := append(slices.Clip(opts), withSpanName("iox.DuplexCopyContext conn2->conn1"), withLabel("conn2->conn1"))
+		result.Conn2ToConn1 = relayCopy(ctx, relayctx, cancel, conn1, conn2, opts...)
+		finished(result.Conn2ToConn1.Label)
+	})
+
+	// 3. wait for both directions to finish
+	wg.Wait()
+
+	// 4. make sure both connections are closed
+	conn1.Close()
+	conn2.Close()
+	return result
+}
+
+// relayCopy copies a single direction on behalf of [DuplexCopyContext].
+//
+// The parent context is the one passed by the user, while ctx is the one
+// canceled when either direction finishes.
+func relayCopy(parent, ctx context.Context, cancel context.CancelFunc,
+	dst io.Writer, src io.ReadCloser, opts ...CopyOption) CopyResult {
+	// 1. copy without fully closing dst, which is also the other direction's reader
+	var result CopyResult
+	opts = append(opts, WithCopyResult(func(res CopyResult) { result = res }))
+	_, err := CopyContext(ctx, NewLockedWriteCloser(HalfCloseWriter(dst)), src, opts...)
+
+	// 2. on EOF with half-close support, let the other direction continue
+	if _, ok := dst.(closeWriter); ok && err == nil {
+		if cr, ok := src.(closeReader); ok {
+			cr.CloseRead()
+		}
+		return result
+	}
+
+	// 3. otherwise, tear down the other direction
+	cancel()
+
+	// 4. being interrupted because the other direction finished is not an error
+	if errors.Is(err, context.Canceled) && parent.Err() == nil {
+		result.Err = nil
+	}
+	return result
+}
+
+// closeWriter is implemented by connections supporting closing the write side.
+type closeWriter interface {
+	CloseWrite() error
+}
+
+// closeReader is implemented by connections supporting closing the read side.
+type closeReader interface {
+	CloseRead() error
+}
+
+// HalfCloseWriter wraps an [io.Writer] and returns an [io.WriteCloser] whose
+// Close only closes the write side of the connection.
+//
+// When w has a CloseWrite method (e.g., [*net.TCPConn]), Close calls it, otherwise
+// Close is a no-op. This is useful to propagate EOF to the peer when [CopyContext]
+// finishes copying, while keeping the connection open for reading.
+func HalfCloseWriter(w io.Writer) io.WriteCloser {
+	return halfCloseWriter{w}
+}
+
+// halfCloseWriter is the [io.WriteCloser] returned by [HalfCloseWriter].
+type halfCloseWriter struct {
+	io.Writer
+}
+
+// Unwrap returns the wrapped [io.Writer].
+func (w halfCloseWriter) Unwrap() io.Writer {
+	return w.Writer
+}
+
+// Close implements [io.Closer].
+func (w halfCloseWriter) Close() error {
+	if cw, ok := w.Writer.(closeWriter); ok {
+		return cw.CloseWrite()
+	}
+	return nil
+}
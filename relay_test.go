@@ -0,0 +1,165 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuplexCopyContextSuccess(t *testing.T) {
+	// Create the two legs of the relay.
+	client, conn1 := net.Pipe()
+	conn2, server := net.Pipe()
+
+	done := make(chan RelayResult, 1)
+	go func() {
+		done <- DuplexCopyContext(context.Background(), conn1, conn2)
+	}()
+
+	// Send from the client to the server.
+	_, err := client.Write([]byte("hello"))
+	require.NoError(t, err)
+	buff := make([]byte, 5)
+	_, err = io.ReadFull(server, buff)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(buff))
+
+	// Send from the server to the client.
+	_, err = server.Write([]byte("world!"))
+	require.NoError(t, err)
+	buff = make([]byte, 6)
+	_, err = io.ReadFull(client, buff)
+	require.NoError(t, err)
+	assert.Equal(t, "world!", string(buff))
+
+	// Closing the client terminates the relay.
+	require.NoError(t, client.Close())
+	res := <-done
+	assertCopyResult(t, CopyResult{Label: "conn1->conn2", Count: 5}, res.Conn1ToConn2)
+	assertCopyResult(t, CopyResult{Label: "conn2->conn1", Count: 6}, res.Conn2ToConn1)
+	assert.Equal(t, "conn1->conn2", res.FirstDone)
+
+	// The server should see the connection being closed.
+	_, err = server.Read(buff)
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestDuplexCopyContextWithCancelledContext(t *testing.T) {
+	// Create the two legs of the relay.
+	_, conn1 := net.Pipe()
+	conn2, _ := net.Pipe()
+
+	// Run the relay with an already canceled context.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res := DuplexCopyContext(ctx, conn1, conn2)
+
+	require.ErrorIs(t, res.Conn1ToConn2.Err, context.Canceled)
+	require.ErrorIs(t, res.Conn2ToConn1.Err, context.Canceled)
+	assert.Equal(t, 0, res.Conn1ToConn2.Count)
+	assert.Equal(t, 0, res.Conn2ToConn1.Count)
+	assert.Zero(t, res.Conn1ToConn2.FirstByte)
+	assert.NotEmpty(t, res.FirstDone)
+}
+
+func TestDuplexCopyContextHalfClose(t *testing.T) {
+	// Create two TCP connection pairs to relay between.
+	client, conn1 := newTCPConnPair(t)
+	conn2, server := newTCPConnPair(t)
+
+	done := make(chan RelayResult, 1)
+	go func() {
+		done <- DuplexCopyContext(context.Background(), conn1, conn2)
+	}()
+
+	// The client sends its request and half-closes.
+	_, err := client.Write([]byte("request"))
+	require.NoError(t, err)
+	require.NoError(t, client.CloseWrite())
+
+	// The server reads until EOF and then sends its response.
+	data, err := io.ReadAll(server)
+	require.NoError(t, err)
+	assert.Equal(t, "request", string(data))
+	_, err = server.Write([]byte("response"))
+	require.NoError(t, err)
+	require.NoError(t, server.CloseWrite())
+
+	// The client must still be able to receive the response.
+	data, err = io.ReadAll(client)
+	require.NoError(t, err)
+	assert.Equal(t, "response", string(data))
+
+	res := <-done
+	assertCopyResult(t, CopyResult{Label: "conn1->conn2", Count: 7}, res.Conn1ToConn2)
+	assertCopyResult(t, CopyResult{Label: "conn2->conn1", Count: 8}, res.Conn2ToConn1)
+	assert.Equal(t, "conn1->conn2", res.FirstDone)
+}
+
+func TestHalfCloseWriter(t *testing.T) {
+	t.Run("with a writer not supporting half-close", func(t *testing.T) {
+		client, _ := net.Pipe()
+		hcw := HalfCloseWriter(client)
+		require.NoError(t, hcw.Close())
+
+		// Close must not have closed the connection.
+		require.NoError(t, client.SetDeadline(time.Time{}))
+	})
+
+	t.Run("with a writer supporting half-close", func(t *testing.T) {
+		client, server := newTCPConnPair(t)
+		hcw := HalfCloseWriter(client)
+		require.NoError(t, hcw.Close())
+
+		// The peer sees EOF but the connection is still readable.
+		data, err := io.ReadAll(server)
+		require.NoError(t, err)
+		assert.Empty(t, data)
+		_, err = server.Write([]byte("ok"))
+		require.NoError(t, err)
+		buff := make([]byte, 2)
+		_, err = io.ReadFull(client, buff)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", string(buff))
+	})
+}
+
+// newTCPConnPair returns a pair of connected loopback TCP connections.
+func newTCPConnPair(t *testing.T) (*net.TCPConn, *net.TCPConn) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, _ := listener.Accept()
+		accepted <- conn
+	}()
+
+	client, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	server := <-accepted
+	require.NotNil(t, server)
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return client.(*net.TCPConn), server.(*net.TCPConn)
+}
+
+// assertCopyResult asserts that got matches expected, ignoring the timings,
+// which must nonetheless be consistent.
+func assertCopyResult(t *testing.T, expected, got CopyResult) {
+	t.Helper()
+	assert.False(t, got.Start.IsZero())
+	assert.GreaterOrEqual(t, got.Duration, got.FirstByte)
+	got.Start, got.FirstByte, got.Duration = time.Time{}, 0, 0
+	assert.Equal(t, expected, got)
+}
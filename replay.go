@@ -0,0 +1,171 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// RecordingReadCloser is an [io.ReadCloser] capturing everything it reads.
+//
+// For each Read returning data, it writes into the capture a record containing
+// the time elapsed since the previous Read, or since construction for the first
+// Read, and the data. Both are prefixed by their uvarint-encoded value or length,
+// respectively. Use [*ReplayReadCloser] to play back the capture.
+//
+// Not safe for concurrent use, like most readers.
+//
+// Construct using [NewRecordingReadCloser].
+type RecordingReadCloser struct {
+	capture io.Writer
+	last    time.Time
+	rc      io.ReadCloser
+}
+
+// NewRecordingReadCloser wraps rc and returns a [*RecordingReadCloser] writing
+// the capture into capture, which may be, e.g., a [*bytes.Buffer] or a file.
+func NewRecordingReadCloser(rc io.ReadCloser, capture io.Writer) *RecordingReadCloser {
+	return &RecordingReadCloser{capture: capture, last: time.Now(), rc: rc}
+}
+
+// Read implements [io.Reader].
+//
+// When writing into the capture fails, Read returns the bytes read along
+// with the error occurred when writing into the capture.
+func (r *RecordingReadCloser) Read(buf []byte) (int, error) {
+	count, err := r.rc.Read(buf)
+	now := time.Now()
+	if count > 0 {
+		record := make([]byte, 0, 2*binary.MaxVarintLen64+count)
+		record = binary.AppendUvarint(record, uint64(now.Sub(r.last)))
+		record = binary.AppendUvarint(record, uint64(count))
+		record = append(record, buf[:count]...)
+		if _, werr := r.capture.Write(record); werr != nil {
+			return count, werr
+		}
+	}
+	r.last = now
+	return count, err
+}
+
+// Close closes the underlying [io.ReadCloser] but not the capture.
+func (r *RecordingReadCloser) Close() error {
+	return r.rc.Close()
+}
+
+// ReplayReadCloser is an [io.ReadCloser] playing back a [*RecordingReadCloser] capture.
+//
+// It returns the recorded data using the same chunking, as long as the caller's
+// buffers are large enough, and then [io.EOF]. When Timing is true, it also
+// reproduces the recorded delays between reads, honoring the context.
+//
+// Not safe for concurrent use, except that Close may be called concurrently
+// with Read to interrupt it.
+//
+// Construct using [NewReplayReadCloser].
+type ReplayReadCloser struct {
+	// Timing enables reproducing the recorded delays between reads.
+	//
+	// [NewReplayReadCloser] sets it to false. You MUST NOT modify it
+	// after you started reading.
+	Timing bool
+
+	capture io.Closer
+	closed  closeSignal
+	cs      closeState
+	ctx     context.Context
+	mu      sync.Mutex
+	pending []byte
+	r       *bufio.Reader
+}
+
+// NewReplayReadCloser returns a new [*ReplayReadCloser] reading the capture,
+// which it closes when closed, using ctx to interrupt the delays.
+func NewReplayReadCloser(ctx context.Context, capture io.ReadCloser) *ReplayReadCloser {
+	return &ReplayReadCloser{
+		capture: capture,
+		closed:  newCloseSignal(),
+		ctx:     ctx,
+		r:       bufio.NewReader(capture),
+	}
+}
+
+// Read implements [io.Reader].
+//
+// The returned error is nil, [io.EOF] at the end of the capture, [io.ErrUnexpectedEOF]
+// if the capture is truncated, [ErrClosed] when closed, or the error caused by the
+// context or by reading the capture.
+func (r *ReplayReadCloser) Read(buf []byte) (int, error) {
+	// 1. read the next record unless we still have pending data
+	if len(r.pending) <= 0 {
+		delay, data, err := r.next()
+		if err != nil {
+			return 0, err
+		}
+		if r.Timing {
+			if err := r.closed.sleep(r.ctx, time.Duration(delay)); err != nil {
+				return 0, err
+			}
+		}
+		r.pending = data
+	}
+
+	// 2. return the pending data
+	count := copy(buf, r.pending)
+	r.pending = r.pending[count:]
+	return count, nil
+}
+
+// next reads the next record from the capture.
+func (r *ReplayReadCloser) next() (uint64, []byte, error) {
+	// 1. make sure we have not been closed
+	r.mu.Lock()
+	err := r.cs.check()
+	r.mu.Unlock()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// 2. read the delay, where EOF means the end of the capture
+	delay, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// 3. read the data
+	size, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return 0, nil, noEOF(err)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		return 0, nil, noEOF(err)
+	}
+	return delay, data, nil
+}
+
+// noEOF converts [io.EOF] into [io.ErrUnexpectedEOF].
+func noEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// Close closes the capture and interrupts any in-flight Read.
+//
+// Returns nil, [ErrClosed], or the error occurred when closing the capture.
+func (r *ReplayReadCloser) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cs.close(func() error {
+		r.closed.close()
+		return r.capture.Close()
+	})
+}
@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readChunks reads rc until EOF returning the chunks returned by each Read.
+func readChunks(t *testing.T, rc io.Reader) []string {
+	var chunks []string
+	buf := make([]byte, 1024)
+	for {
+		count, err := rc.Read(buf)
+		if count > 0 {
+			chunks = append(chunks, string(buf[:count]))
+		}
+		if errors.Is(err, io.EOF) {
+			return chunks
+		}
+		require.NoError(t, err)
+	}
+}
+
+func TestRecordingAndReplayReadCloser(t *testing.T) {
+	const payload = "GET / HTTP/1.1\r\nHost: example.com\r\n\r\n"
+	capture := &bytes.Buffer{}
+	rec := NewRecordingReadCloser(newSmallReadsReadCloser(payload, 7), capture)
+	original := readChunks(t, rec)
+	require.NoError(t, rec.Close())
+
+	t.Run("replay preserves the data and the chunking", func(t *testing.T) {
+		replay := NewReplayReadCloser(context.Background(), NopReadCloser(bytes.NewReader(capture.Bytes())))
+		assert.Equal(t, original, readChunks(t, replay))
+		require.NoError(t, replay.Close())
+		assert.ErrorIs(t, replay.Close(), ErrClosed)
+		_, err := replay.Read(make([]byte, 1))
+		assert.ErrorIs(t, err, ErrClosed)
+	})
+
+	t.Run("replay works with small buffers", func(t *testing.T) {
+		replay := NewReplayReadCloser(context.Background(), NopReadCloser(bytes.NewReader(capture.Bytes())))
+		data, err := io.ReadAll(io.LimitReader(replay, 1<<20))
+		require.NoError(t, err)
+		assert.Equal(t, payload, string(data))
+	})
+
+	t.Run("truncated captures", func(t *testing.T) {
+		truncated := capture.Bytes()[:capture.Len()-1]
+		replay := NewReplayReadCloser(context.Background(), NopReadCloser(bytes.NewReader(truncated)))
+		_, err := io.ReadAll(replay)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+}
+
+func TestReplayReadCloserTiming(t *testing.T) {
+	// record two reads separated by a known delay
+	capture := &bytes.Buffer{}
+	reads := 0
+	rec := NewRecordingReadCloser(&iotest.FuncReadCloser{
+		ReadFunc: func(buf []byte) (int, error) {
+			reads++
+			switch reads {
+			case 1:
+				return copy(buf, "a"), nil
+			case 2:
+				time.Sleep(50 * time.Millisecond)
+				return copy(buf, "b"), nil
+			default:
+				return 0, io.EOF
+			}
+		},
+		CloseFunc: func() error {
+			return nil
+		},
+	}, capture)
+	readChunks(t, rec)
+
+	t.Run("reproduces the delays", func(t *testing.T) {
+		replay := NewReplayReadCloser(context.Background(), NopReadCloser(bytes.NewReader(capture.Bytes())))
+		replay.Timing = true
+		t0 := time.Now()
+		assert.Equal(t, []string{"a", "b"}, readChunks(t, replay))
+		assert.GreaterOrEqual(t, time.Since(t0), 50*time.Millisecond)
+	})
+
+	t.Run("honors the context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		replay := NewReplayReadCloser(ctx, NopReadCloser(bytes.NewReader(capture.Bytes())))
+		replay.Timing = true
+		_, err := replay.Read(make([]byte, 1))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestRecordingReadCloserCaptureError(t *testing.T) {
+	expected := errors.New("mocked error")
+	capture := &iotest.FuncWriteCloser{
+		WriteFunc: func(data []byte) (int, error) {
+			return 0, expected
+		},
+	}
+	rec := NewRecordingReadCloser(NopReadCloser(bytes.NewReader([]byte("abc"))), capture)
+	count, err := rec.Read(make([]byte, 8))
+	assert.ErrorIs(t, err, expected)
+	assert.Equal(t, 3, count)
+}
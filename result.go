@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// CopyResult contains the result of copying in a single direction.
+type CopyResult struct {
+	// Label identifies the direction of the copy, e.g., "conn1->conn2" for
+	// [DuplexCopyContext], and is empty for [CopyContext].
+	Label string
+
+	// Count is the number of bytes copied.
+	Count int
+
+	// Err is the error that terminated the copy, if any.
+	Err error
+
+	// Start is when the copy started.
+	Start time.Time
+
+	// FirstByte is the time elapsed between Start and reading the first bytes. It
+	// is zero when the copy did not read any byte or used a zero-copy fast path,
+	// which does not observe reads (see [CopyContext]).
+	FirstByte time.Duration
+
+	// Duration is the time elapsed between Start and the end of the copy.
+	Duration time.Duration
+}
+
+// WithCopyResult causes the copy to call fn with its [CopyResult] once done,
+// which is useful, e.g., to produce access logs.
+//
+// Observing when the copy reads the first bytes hides the [io.WriterTo]
+// implemented by the reader, but not the zero-copy fast path of [CopyContext].
+// It composes with other invocations of this option.
+func WithCopyResult(fn func(CopyResult)) CopyOption {
+	return func(cfg *copyConfig) {
+		cfg.results = append(cfg.results, fn)
+	}
+}
+
+// withLabel sets the label of the [CopyResult] passed to [WithCopyResult].
+func withLabel(label string) CopyOption {
+	return func(cfg *copyConfig) {
+		cfg.label = label
+	}
+}
+
+// resultRecorder records the [CopyResult] for [WithCopyResult].
+//
+// All methods tolerate a nil receiver, meaning there is nothing to record.
+type resultRecorder struct {
+	fns    []func(CopyResult)
+	mu     sync.Mutex
+	result CopyResult
+}
+
+// startResult returns a [*resultRecorder] or nil when there are no callbacks.
+func (cfg *copyConfig) startResult() *resultRecorder {
+	if len(cfg.results) <= 0 {
+		return nil
+	}
+	return &resultRecorder{fns: cfg.results, result: CopyResult{Label: cfg.label, Start: time.Now()}}
+}
+
+// wrap wraps rc such that reading records the time of the first byte.
+func (rr *resultRecorder) wrap(rc io.ReadCloser) io.ReadCloser {
+	if rr == nil {
+		return rc
+	}
+	return readCloser{firstByteReader{rc, rr}, rc}
+}
+
+// read records the time of the first byte, if count is positive.
+func (rr *resultRecorder) read(count int) {
+	if count <= 0 {
+		return
+	}
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	if rr.result.FirstByte <= 0 {
+		rr.result.FirstByte = max(time.Since(rr.result.Start), 1)
+	}
+}
+
+// end completes the [CopyResult] and invokes the callbacks.
+func (rr *resultRecorder) end(count int, err error) {
+	if rr == nil {
+		return
+	}
+	rr.mu.Lock()
+	result := rr.result
+	rr.mu.Unlock()
+	result.Count, result.Err, result.Duration = count, err, time.Since(result.Start)
+	for _, fn := range rr.fns {
+		fn(result)
+	}
+}
+
+// firstByteReader is the [io.Reader] returned by [*resultRecorder.wrap].
+type firstByteReader struct {
+	r  io.Reader
+	rr *resultRecorder
+}
+
+// Read implements [io.Reader].
+func (r firstByteReader) Read(buf []byte) (int, error) {
+	count, err := r.r.Read(buf)
+	r.rr.read(count)
+	return count, err
+}
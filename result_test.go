@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithCopyResult(t *testing.T) {
+	t.Run("successful copy", func(t *testing.T) {
+		var results []CopyResult
+		before := time.Now()
+		rc := &iotest.FuncReadCloser{
+			ReadFunc: func() func([]byte) (int, error) {
+				reader := strings.NewReader("hello")
+				return func(b []byte) (int, error) {
+					time.Sleep(time.Millisecond)
+					return reader.Read(b)
+				}
+			}(),
+		}
+		count, err := CopyContext(context.Background(), NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{})),
+			rc, WithCopyResult(func(res CopyResult) { results = append(results, res) }),
+			WithCopyResult(func(res CopyResult) { results = append(results, res) }))
+		require.NoError(t, err)
+		assert.Equal(t, 5, count)
+
+		require.Len(t, results, 2)
+		assert.Equal(t, results[0], results[1])
+		res := results[0]
+		assert.Empty(t, res.Label)
+		assert.Equal(t, 5, res.Count)
+		assert.NoError(t, res.Err)
+		assert.False(t, res.Start.Before(before))
+		assert.GreaterOrEqual(t, res.FirstByte, time.Millisecond)
+		assert.Greater(t, res.Duration, res.FirstByte)
+	})
+
+	t.Run("failed copy without bytes", func(t *testing.T) {
+		expected := errors.New("mocked read error")
+		var result CopyResult
+		_, err := CopyContext(context.Background(), NewLockedWriteCloser(NopWriteCloser(io.Discard)),
+			&iotest.FuncReadCloser{
+				ReadFunc: func(b []byte) (int, error) { return 0, expected },
+			}, WithCopyResult(func(res CopyResult) { result = res }))
+		require.ErrorIs(t, err, expected)
+		assert.Same(t, err, result.Err)
+		assert.Equal(t, 0, result.Count)
+		assert.Zero(t, result.FirstByte)
+	})
+}
@@ -0,0 +1,181 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// ReopenFunc reopens a stream such that it resumes at the given offset.
+//
+// For example, an implementation could issue an HTTP request using a Range header.
+type ReopenFunc func(ctx context.Context, offset int64) (io.ReadCloser, error)
+
+// ResumableReadCloser is an [io.ReadCloser] resuming reading after failures.
+//
+// When the underlying reader fails mid-stream, it closes it and uses a [ReopenFunc]
+// to continue reading from the offset where the failure occurred. Consecutive
+// failures are retried up to MaxRetries times, waiting Backoff before the first
+// retry and doubling the wait at each subsequent retry. Any successful read
+// resets the retry budget. Waiting honors the context passed to the constructor.
+//
+// Read is not safe for concurrent use, but Close may be called concurrently
+// with Read to interrupt it, as [CopyContext] does on cancellation.
+//
+// Construct using [NewResumableReadCloser].
+type ResumableReadCloser struct {
+	// MaxRetries is the maximum number of consecutive reopen attempts.
+	//
+	// [NewResumableReadCloser] sets it to 3. You MUST NOT modify it after
+	// you started reading.
+	MaxRetries int
+
+	// Backoff is the delay before the first reopen attempt.
+	//
+	// [NewResumableReadCloser] sets it to 1 second. You MUST NOT modify it
+	// after you started reading.
+	Backoff time.Duration
+
+	closed   closeSignal
+	ctx      context.Context
+	failures int
+	lasterr  error
+	mu       sync.Mutex
+	offset   int64
+	rc       io.ReadCloser
+	reopen   ReopenFunc
+}
+
+// NewResumableReadCloser wraps rc and returns a [*ResumableReadCloser] that
+// uses reopen to resume reading after failures.
+//
+// The context bounds the reopen attempts and the waits between them.
+func NewResumableReadCloser(ctx context.Context, rc io.ReadCloser, reopen ReopenFunc) *ResumableReadCloser {
+	return &ResumableReadCloser{
+		MaxRetries: 3,
+		Backoff:    time.Second,
+		closed:     newCloseSignal(),
+		ctx:        ctx,
+		rc:         rc,
+		reopen:     reopen,
+	}
+}
+
+// Offset returns the number of bytes read so far.
+func (r *ResumableReadCloser) Offset() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.offset
+}
+
+// Read implements [io.Reader].
+//
+// The returned error is nil, [io.EOF], [ErrClosed] when closed, the context error,
+// or the last error occurred when reading or reopening once retries are exhausted.
+func (r *ResumableReadCloser) Read(buf []byte) (int, error) {
+	for {
+		// 1. make sure we have a reader, reopening if needed
+		rc, err := r.current()
+		if err != nil {
+			return 0, err
+		}
+
+		// 2. read and account for the bytes read
+		count, err := rc.Read(buf)
+		r.mu.Lock()
+		r.offset += int64(count)
+		if count > 0 {
+			r.failures = 0
+		}
+		r.mu.Unlock()
+		if err == nil || errors.Is(err, io.EOF) {
+			return count, err
+		}
+
+		// 3. on failure, get rid of the reader so the next iteration reopens
+		if closeErr := r.discard(rc, err); closeErr != nil {
+			return count, closeErr
+		}
+		if count > 0 {
+			return count, nil
+		}
+	}
+}
+
+// current returns the current reader, reopening it when needed.
+func (r *ResumableReadCloser) current() (io.ReadCloser, error) {
+	for {
+		// 1. check whether we are closed or can use the current reader
+		r.mu.Lock()
+		if r.closed.isClosed() {
+			r.mu.Unlock()
+			return nil, ErrClosed
+		}
+		if rc := r.rc; rc != nil {
+			r.mu.Unlock()
+			return rc, nil
+		}
+		if r.failures >= r.MaxRetries {
+			err := r.lasterr
+			r.mu.Unlock()
+			return nil, err
+		}
+		delay := backoffDelay(r.Backoff, 0, r.failures)
+		r.failures++
+		offset := r.offset
+		r.mu.Unlock()
+
+		// 2. wait before reopening
+		if err := r.closed.sleep(r.ctx, delay); err != nil {
+			return nil, err
+		}
+
+		// 3. attempt to reopen
+		rc, err := r.reopen(r.ctx, offset)
+		r.mu.Lock()
+		switch {
+		case err != nil:
+			r.lasterr = err
+		case r.closed.isClosed():
+			rc.Close()
+		default:
+			r.rc = rc
+		}
+		r.mu.Unlock()
+	}
+}
+
+// discard closes a failed reader such that the next read reopens.
+//
+// Returns [ErrClosed] if we have been closed in the meanwhile.
+func (r *ResumableReadCloser) discard(rc io.ReadCloser, err error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed.isClosed() {
+		return ErrClosed
+	}
+	rc.Close()
+	r.rc = nil
+	r.lasterr = err
+	return nil
+}
+
+// Close implements [io.Closer].
+//
+// Returns nil, [ErrClosed], or the error occurred when closing the current reader.
+func (r *ResumableReadCloser) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.closed.close(); err != nil {
+		return err
+	}
+	if rc := r.rc; rc != nil {
+		r.rc = nil
+		return rc.Close()
+	}
+	return nil
+}
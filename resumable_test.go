@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFlakyReadCloser returns a reader for payload[offset:] failing after n bytes.
+func newFlakyReadCloser(payload string, offset int64, n int, err error) io.ReadCloser {
+	sr := strings.NewReader(payload[offset:])
+	remaining := n
+	return &iotest.FuncReadCloser{
+		ReadFunc: func(b []byte) (int, error) {
+			if remaining <= 0 {
+				return 0, err
+			}
+			count, rerr := sr.Read(b[:min(len(b), remaining)])
+			remaining -= count
+			return count, rerr
+		},
+		CloseFunc: func() error { return nil },
+	}
+}
+
+func TestResumableReadCloserSuccess(t *testing.T) {
+	// Each reader breaks after four bytes and we resume from where we left off.
+	const payload = "resumable download"
+	expected := errors.New("mocked connection reset")
+	var offsets []int64
+	reopen := func(ctx context.Context, offset int64) (io.ReadCloser, error) {
+		offsets = append(offsets, offset)
+		return newFlakyReadCloser(payload, offset, 4, expected), nil
+	}
+	rrc := NewResumableReadCloser(context.Background(), newFlakyReadCloser(payload, 0, 4, expected), reopen)
+	rrc.Backoff = time.Millisecond
+
+	data, err := io.ReadAll(rrc)
+	require.NoError(t, err)
+	assert.Equal(t, payload, string(data))
+	assert.Equal(t, int64(len(payload)), rrc.Offset())
+	assert.Equal(t, []int64{4, 8, 12, 16}, offsets)
+
+	require.NoError(t, rrc.Close())
+	require.ErrorIs(t, rrc.Close(), ErrClosed)
+	_, err = rrc.Read(make([]byte, 1))
+	require.ErrorIs(t, err, ErrClosed)
+}
+
+func TestResumableReadCloserRetriesExhausted(t *testing.T) {
+	// Reopening always fails, so we give up after MaxRetries attempts.
+	expected := errors.New("mocked dial error")
+	attempts := 0
+	reopen := func(ctx context.Context, offset int64) (io.ReadCloser, error) {
+		attempts++
+		return nil, expected
+	}
+	rc := newFlakyReadCloser("iox", 0, 0, errors.New("mocked read error"))
+	rrc := NewResumableReadCloser(context.Background(), rc, reopen)
+	rrc.Backoff = time.Millisecond
+	rrc.MaxRetries = 2
+
+	_, err := io.ReadAll(rrc)
+	require.ErrorIs(t, err, expected)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestResumableReadCloserWithCancelledContext(t *testing.T) {
+	// The wait between retries must honor the context.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	reopen := func(ctx context.Context, offset int64) (io.ReadCloser, error) {
+		panic("should not be called")
+	}
+	rc := newFlakyReadCloser("iox", 0, 0, errors.New("mocked read error"))
+	rrc := NewResumableReadCloser(ctx, rc, reopen)
+	rrc.Backoff = time.Hour
+
+	_, err := io.ReadAll(rrc)
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// ReconnectFunc returns a fresh writer replacing one that failed.
+//
+// For example, an implementation could dial a new connection to a collector.
+type ReconnectFunc func(ctx context.Context) (io.WriteCloser, error)
+
+// RetryWriteCloser is an [io.WriteCloser] retrying transient write failures.
+//
+// When writing fails with an error classified as retryable by IsRetryable, it
+// waits and writes again, up to MaxRetries times per Write, waiting Backoff before
+// the first retry and doubling the wait at each subsequent retry, up to MaxBackoff.
+// Waiting honors the context passed to the constructor.
+//
+// Without Reconnect, it retries writing the unwritten remainder to the same writer.
+// With Reconnect, it closes the failed writer, obtains a fresh one, and writes the
+// whole buffer again, since we cannot know what the failed writer delivered. This
+// gives at-least-once delivery of each buffer passed to Write, so, when writing
+// records, pass each record to a single Write call.
+//
+// Write is not safe for concurrent use (wrap it using [NewLockedWriteCloser] for
+// that), but Close may be called concurrently with Write to interrupt it.
+//
+// Construct using [NewRetryWriteCloser].
+type RetryWriteCloser struct {
+	// MaxRetries is the maximum number of retries for each Write.
+	//
+	// [NewRetryWriteCloser] sets it to 3. You MUST NOT modify it after
+	// you started writing.
+	MaxRetries int
+
+	// Backoff is the delay before the first retry.
+	//
+	// [NewRetryWriteCloser] sets it to 1 second. You MUST NOT modify it
+	// after you started writing.
+	Backoff time.Duration
+
+	// MaxBackoff is the maximum delay between retries.
+	//
+	// [NewRetryWriteCloser] sets it to 30 seconds. You MUST NOT modify it
+	// after you started writing.
+	MaxBackoff time.Duration
+
+	// IsRetryable returns whether a write or reconnect error is transient.
+	//
+	// [NewRetryWriteCloser] sets it to [IsTimeout]. You MUST NOT modify it
+	// after you started writing.
+	IsRetryable func(err error) bool
+
+	// Reconnect, if not nil, obtains a fresh writer after a failure.
+	//
+	// [NewRetryWriteCloser] sets it to nil. You MUST NOT modify it after
+	// you started writing.
+	Reconnect ReconnectFunc
+
+	closed closeSignal
+	ctx    context.Context
+	mu     sync.Mutex
+	w      io.WriteCloser
+}
+
+// NewRetryWriteCloser wraps w and returns a [*RetryWriteCloser] retrying
+// transient write failures.
+//
+// The context bounds the reconnect attempts and the waits between retries.
+func NewRetryWriteCloser(ctx context.Context, w io.WriteCloser) *RetryWriteCloser {
+	return &RetryWriteCloser{
+		MaxRetries:  3,
+		Backoff:     time.Second,
+		MaxBackoff:  30 * time.Second,
+		IsRetryable: IsTimeout,
+		Reconnect:   nil,
+		closed:      newCloseSignal(),
+		ctx:         ctx,
+		w:           w,
+	}
+}
+
+// Write implements [io.Writer].
+//
+// The returned error is nil, [ErrClosed] when closed, the context error, or the
+// last error occurred when writing or reconnecting once it is not retryable or
+// retries are exhausted.
+func (w *RetryWriteCloser) Write(data []byte) (int, error) {
+	pending := data
+	for attempt := 0; ; attempt++ {
+		// 1. wait before retrying
+		if attempt > 0 {
+			if err := w.closed.sleep(w.ctx, w.delay(attempt-1)); err != nil {
+				return len(data) - len(pending), err
+			}
+		}
+
+		// 2. make sure we have a writer, reconnecting if needed
+		wc, err := w.current()
+		if errors.Is(err, ErrClosed) {
+			return len(data) - len(pending), err
+		}
+
+		// 3. write what is still pending
+		if err == nil {
+			var count int
+			count, err = wc.Write(pending)
+			pending = pending[count:]
+			if err == nil {
+				return len(data), nil
+			}
+
+			// 4. when we can reconnect, get rid of the writer and start over
+			if w.Reconnect != nil {
+				w.discard(wc)
+				pending = data
+			}
+		}
+
+		// 5. give up on permanent errors or when retries are exhausted
+		if attempt >= w.MaxRetries || !w.IsRetryable(err) {
+			return len(data) - len(pending), err
+		}
+	}
+}
+
+// delay returns the delay before the given retry, capped to MaxBackoff.
+func (w *RetryWriteCloser) delay(retry int) time.Duration {
+	return backoffDelay(w.Backoff, w.MaxBackoff, retry)
+}
+
+// current returns the current writer, reconnecting when needed.
+func (w *RetryWriteCloser) current() (io.WriteCloser, error) {
+	// 1. check whether we are closed or can use the current writer
+	w.mu.Lock()
+	if w.closed.isClosed() {
+		w.mu.Unlock()
+		return nil, ErrClosed
+	}
+	if wc := w.w; wc != nil {
+		w.mu.Unlock()
+		return wc, nil
+	}
+	w.mu.Unlock()
+
+	// 2. attempt to reconnect
+	wc, err := w.Reconnect(w.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. install the new writer unless we have been closed in the meanwhile
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed.isClosed() {
+		wc.Close()
+		return nil, ErrClosed
+	}
+	w.w = wc
+	return wc, nil
+}
+
+// discard closes a failed writer such that the next attempt reconnects.
+func (w *RetryWriteCloser) discard(wc io.WriteCloser) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.w == wc {
+		w.w = nil
+		wc.Close()
+	}
+}
+
+// Close implements [io.Closer].
+//
+// Returns nil, [ErrClosed], or the error occurred when closing the current writer.
+func (w *RetryWriteCloser) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.closed.close(); err != nil {
+		return err
+	}
+	if wc := w.w; wc != nil {
+		w.w = nil
+		return wc.Close()
+	}
+	return nil
+}
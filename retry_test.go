@@ -0,0 +1,191 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// errRetryable is a mocked transient error.
+var errRetryable = errors.New("mocked transient error")
+
+// isMockedRetryable classifies [errRetryable] as retryable.
+func isMockedRetryable(err error) bool {
+	return errors.Is(err, errRetryable)
+}
+
+// newFlakyWriteCloser returns a writer into buf failing with err after n bytes.
+func newFlakyWriteCloser(buf *bytes.Buffer, n int, err error) *iotest.FuncWriteCloser {
+	remaining := n
+	return &iotest.FuncWriteCloser{
+		WriteFunc: func(b []byte) (int, error) {
+			count := min(len(b), remaining)
+			buf.Write(b[:count])
+			remaining -= count
+			if count < len(b) {
+				return count, err
+			}
+			return count, nil
+		},
+		CloseFunc: func() error { return nil },
+	}
+}
+
+func TestRetryWriteCloserRetriesRemainder(t *testing.T) {
+	// Without Reconnect, we retry writing the remainder to the same writer.
+	var (
+		buf      bytes.Buffer
+		attempts int
+	)
+	wc := &iotest.FuncWriteCloser{
+		WriteFunc: func(b []byte) (int, error) {
+			attempts++
+			if attempts < 3 {
+				buf.Write(b[:1])
+				return 1, errRetryable
+			}
+			return buf.Write(b)
+		},
+		CloseFunc: func() error { return nil },
+	}
+	rwc := NewRetryWriteCloser(context.Background(), wc)
+	rwc.Backoff = time.Millisecond
+	rwc.IsRetryable = isMockedRetryable
+
+	count, err := rwc.Write([]byte("telemetry"))
+	require.NoError(t, err)
+	assert.Equal(t, 9, count)
+	assert.Equal(t, "telemetry", buf.String())
+	assert.Equal(t, 3, attempts)
+
+	require.NoError(t, rwc.Close())
+	require.ErrorIs(t, rwc.Close(), ErrClosed)
+	_, err = rwc.Write([]byte("x"))
+	require.ErrorIs(t, err, ErrClosed)
+}
+
+func TestRetryWriteCloserReconnect(t *testing.T) {
+	// With Reconnect, we close the failed writer and resend the whole buffer.
+	var (
+		first, second bytes.Buffer
+		closed        bool
+	)
+	wc := newFlakyWriteCloser(&first, 4, errRetryable)
+	wc.CloseFunc = func() error {
+		closed = true
+		return nil
+	}
+	rwc := NewRetryWriteCloser(context.Background(), wc)
+	rwc.Backoff = time.Millisecond
+	rwc.IsRetryable = isMockedRetryable
+	rwc.Reconnect = func(ctx context.Context) (io.WriteCloser, error) {
+		return newFlakyWriteCloser(&second, 1024, nil), nil
+	}
+
+	count, err := rwc.Write([]byte("record\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 7, count)
+	assert.Equal(t, "reco", first.String())
+	assert.Equal(t, "record\n", second.String())
+	assert.True(t, closed)
+	require.NoError(t, rwc.Close())
+}
+
+func TestRetryWriteCloserReconnectFailures(t *testing.T) {
+	// Retryable reconnect failures consume the retry budget.
+	var buf bytes.Buffer
+	attempts := 0
+	rwc := NewRetryWriteCloser(context.Background(), newFlakyWriteCloser(&buf, 0, errRetryable))
+	rwc.Backoff = time.Millisecond
+	rwc.IsRetryable = isMockedRetryable
+	rwc.MaxRetries = 2
+	rwc.Reconnect = func(ctx context.Context) (io.WriteCloser, error) {
+		attempts++
+		return nil, errRetryable
+	}
+
+	count, err := rwc.Write([]byte("iox"))
+	require.ErrorIs(t, err, errRetryable)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, 2, attempts)
+	require.NoError(t, rwc.Close())
+}
+
+func TestRetryWriteCloserPermanentError(t *testing.T) {
+	// Errors not classified as retryable are returned immediately.
+	expected := errors.New("mocked permanent error")
+	var buf bytes.Buffer
+	rwc := NewRetryWriteCloser(context.Background(), newFlakyWriteCloser(&buf, 2, expected))
+	rwc.Backoff = time.Hour
+	rwc.IsRetryable = isMockedRetryable
+
+	count, err := rwc.Write([]byte("iox"))
+	require.ErrorIs(t, err, expected)
+	assert.Equal(t, 2, count)
+}
+
+func TestRetryWriteCloserRetriesExhausted(t *testing.T) {
+	// We give up after MaxRetries retries and report the bytes written.
+	var buf bytes.Buffer
+	rwc := NewRetryWriteCloser(context.Background(), newFlakyWriteCloser(&buf, 1, errRetryable))
+	rwc.Backoff = time.Millisecond
+	rwc.IsRetryable = isMockedRetryable
+	rwc.MaxRetries = 2
+
+	count, err := rwc.Write([]byte("iox"))
+	require.ErrorIs(t, err, errRetryable)
+	assert.Equal(t, 1, count)
+}
+
+func TestRetryWriteCloserWithCancelledContext(t *testing.T) {
+	// The wait between retries must honor the context.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var buf bytes.Buffer
+	rwc := NewRetryWriteCloser(ctx, newFlakyWriteCloser(&buf, 0, errRetryable))
+	rwc.Backoff = time.Hour
+	rwc.IsRetryable = isMockedRetryable
+
+	_, err := rwc.Write([]byte("iox"))
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRetryWriteCloserCloseInterruptsWait(t *testing.T) {
+	// Close must interrupt a Write waiting before the next retry.
+	var buf bytes.Buffer
+	rwc := NewRetryWriteCloser(context.Background(), newFlakyWriteCloser(&buf, 0, errRetryable))
+	rwc.Backoff = time.Hour
+	rwc.IsRetryable = isMockedRetryable
+	time.AfterFunc(10*time.Millisecond, func() { rwc.Close() })
+
+	_, err := rwc.Write([]byte("iox"))
+	require.ErrorIs(t, err, ErrClosed)
+}
+
+func TestRetryWriteCloserDelay(t *testing.T) {
+	// The delay doubles at each retry and is capped to MaxBackoff.
+	rwc := NewRetryWriteCloser(context.Background(), nil)
+	rwc.Backoff = time.Second
+	rwc.MaxBackoff = 5 * time.Second
+	assert.Equal(t, time.Second, rwc.delay(0))
+	assert.Equal(t, 2*time.Second, rwc.delay(1))
+	assert.Equal(t, 4*time.Second, rwc.delay(2))
+	assert.Equal(t, 5*time.Second, rwc.delay(3))
+	assert.Equal(t, 5*time.Second, rwc.delay(100))
+}
+
+func TestRetryWriteCloserDefaultsRetryTimeouts(t *testing.T) {
+	// By default, only timeouts are retryable.
+	rwc := NewRetryWriteCloser(context.Background(), nil)
+	assert.True(t, rwc.IsRetryable(context.DeadlineExceeded))
+	assert.False(t, rwc.IsRetryable(errRetryable))
+}
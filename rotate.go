@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// OpenNextFunc opens the index-th destination of a [*RotatingWriteCloser].
+//
+// The first destination has index zero.
+type OpenNextFunc func(index int) (io.WriteCloser, error)
+
+// RotateFilePattern returns an [OpenNextFunc] creating files whose name is
+// obtained by formatting pattern (e.g., "capture-%04d.pcap") with the index.
+//
+// Existing files are truncated.
+func RotateFilePattern(pattern string) OpenNextFunc {
+	return func(index int) (io.WriteCloser, error) {
+		return os.Create(fmt.Sprintf(pattern, index))
+	}
+}
+
+// RotatingWriteCloser is an [io.WriteCloser] rotating destinations by size.
+//
+// It writes into the current destination until it contains maxSize bytes, then
+// it closes it and opens the next one using an [OpenNextFunc]. A write crossing
+// the threshold is split, so each destination contains at most maxSize bytes.
+// The first destination is opened lazily by the first write.
+//
+// All methods are safe for concurrent use. To drive it using [CopyContext],
+// wrap it with [NewLockedWriteCloser].
+//
+// Construct using [NewRotatingWriteCloser].
+type RotatingWriteCloser struct {
+	cs       closeState
+	cur      io.WriteCloser
+	index    int
+	maxSize  int64
+	mu       sync.Mutex
+	openNext OpenNextFunc
+	size     int64
+}
+
+// NewRotatingWriteCloser returns a new [*RotatingWriteCloser].
+//
+// Nonpositive values of maxSize disable size-based rotation.
+func NewRotatingWriteCloser(maxSize int64, openNext OpenNextFunc) *RotatingWriteCloser {
+	return &RotatingWriteCloser{maxSize: maxSize, openNext: openNext}
+}
+
+// Write implements [io.Writer].
+//
+// The returned error is nil, [ErrClosed] when closed, or the error occurred
+// when opening, writing into, or closing a destination.
+func (w *RotatingWriteCloser) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// 1. make sure we have not been closed
+	if err := w.cs.check(); err != nil {
+		return 0, err
+	}
+
+	// 2. write into destinations until we have written everything
+	var total int
+	for len(data) > 0 {
+		// 2.1. rotate when the current destination is full
+		if w.cur != nil && w.maxSize > 0 && w.size >= w.maxSize {
+			if err := w.closeCurrent(); err != nil {
+				return total, err
+			}
+		}
+
+		// 2.2. open the next destination if needed
+		if w.cur == nil {
+			cur, err := w.openNext(w.index)
+			if err != nil {
+				return total, err
+			}
+			w.cur, w.size = cur, 0
+			w.index++
+		}
+
+		// 2.3. write at most the space left into the current destination
+		chunk := data
+		if w.maxSize > 0 {
+			chunk = chunk[:min(int64(len(chunk)), w.maxSize-w.size)]
+		}
+		count, err := w.cur.Write(chunk)
+		total += count
+		w.size += int64(count)
+		data = data[count:]
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// Rotate closes the current destination, if any, such that the next write
+// opens a new destination regardless of the current size.
+//
+// The returned error is nil, [ErrClosed] when closed, or the error occurred
+// when closing the current destination.
+func (w *RotatingWriteCloser) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.cs.check(); err != nil {
+		return err
+	}
+	return w.closeCurrent()
+}
+
+// Index returns the number of destinations opened so far.
+func (w *RotatingWriteCloser) Index() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.index
+}
+
+// closeCurrent closes the current destination, if any. The caller MUST hold the mutex.
+func (w *RotatingWriteCloser) closeCurrent() error {
+	cur := w.cur
+	w.cur, w.size = nil, 0
+	if cur == nil {
+		return nil
+	}
+	return cur.Close()
+}
+
+// Close closes the current destination and ensures that subsequent writes
+// would fail with [ErrClosed].
+//
+// Returns nil, [ErrClosed], or the error occurred when closing the destination.
+func (w *RotatingWriteCloser) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.cs.close(w.closeCurrent)
+}
@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rotationRecorder records the destinations opened by a [*RotatingWriteCloser].
+type rotationRecorder struct {
+	buffers []*bytes.Buffer
+	closed  int
+}
+
+func (r *rotationRecorder) openNext(index int) (io.WriteCloser, error) {
+	buff := &bytes.Buffer{}
+	r.buffers = append(r.buffers, buff)
+	return &iotest.FuncWriteCloser{
+		WriteFunc: buff.Write,
+		CloseFunc: func() error {
+			r.closed++
+			return nil
+		},
+	}, nil
+}
+
+func (r *rotationRecorder) contents() (out []string) {
+	for _, buff := range r.buffers {
+		out = append(out, buff.String())
+	}
+	return
+}
+
+func TestRotatingWriteCloser(t *testing.T) {
+	t.Run("writes are split across destinations", func(t *testing.T) {
+		rec := &rotationRecorder{}
+		w := NewRotatingWriteCloser(4, rec.openNext)
+		assert.Equal(t, 0, w.Index())
+
+		count, err := w.Write([]byte("abcdefghij"))
+		require.NoError(t, err)
+		assert.Equal(t, 10, count)
+		_, err = w.Write([]byte("kl"))
+		require.NoError(t, err)
+		assert.Equal(t, 3, w.Index())
+		assert.Equal(t, 2, rec.closed)
+
+		require.NoError(t, w.Close())
+		assert.Equal(t, 3, rec.closed)
+		assert.Equal(t, []string{"abcd", "efgh", "ijkl"}, rec.contents())
+	})
+
+	t.Run("Rotate forces a new destination", func(t *testing.T) {
+		rec := &rotationRecorder{}
+		w := NewRotatingWriteCloser(0, rec.openNext)
+		_, err := w.Write([]byte("abc"))
+		require.NoError(t, err)
+		require.NoError(t, w.Rotate())
+		require.NoError(t, w.Rotate())
+		_, err = w.Write([]byte("def"))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		assert.Equal(t, []string{"abc", "def"}, rec.contents())
+	})
+
+	t.Run("open errors are returned", func(t *testing.T) {
+		expected := errors.New("mocked error")
+		w := NewRotatingWriteCloser(4, func(index int) (io.WriteCloser, error) {
+			return nil, expected
+		})
+		count, err := w.Write([]byte("abc"))
+		assert.ErrorIs(t, err, expected)
+		assert.Equal(t, 0, count)
+		require.NoError(t, w.Close())
+	})
+
+	t.Run("closed writer returns ErrClosed", func(t *testing.T) {
+		rec := &rotationRecorder{}
+		w := NewRotatingWriteCloser(4, rec.openNext)
+		require.NoError(t, w.Close())
+		assert.ErrorIs(t, w.Close(), ErrClosed)
+		assert.ErrorIs(t, w.Rotate(), ErrClosed)
+		_, err := w.Write([]byte("abc"))
+		assert.ErrorIs(t, err, ErrClosed)
+		assert.Empty(t, rec.buffers)
+	})
+}
+
+func TestRotateFilePattern(t *testing.T) {
+	dir := t.TempDir()
+	w := NewRotatingWriteCloser(5, RotateFilePattern(filepath.Join(dir, "capture-%02d.txt")))
+	lwc := NewLockedWriteCloser(w)
+
+	payload := "hello, world"
+	_, err := CopyContext(context.Background(), lwc, NopReadCloser(strings.NewReader(payload)))
+	require.NoError(t, err)
+
+	expected := map[string]string{
+		"capture-00.txt": "hello",
+		"capture-01.txt": ", wor",
+		"capture-02.txt": "ld",
+	}
+	for name, content := range expected {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.Equal(t, content, string(data))
+	}
+}
@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bufio"
+	"context"
+	"io"
+)
+
+// ScannerContext is a context-aware variant of [*bufio.Scanner].
+//
+// It mirrors the [*bufio.Scanner] API, including pluggable split functions
+// such as [bufio.ScanLines] (the default), [bufio.ScanWords], or custom ones.
+//
+// Reads are context-interruptible: on context cancellation, the underlying
+// [io.ReadCloser] is closed to unblock any in-flight Read and Scan fails
+// with the context error. Otherwise, the [io.ReadCloser] is NOT closed and
+// the caller MUST close it (e.g., via defer).
+//
+// Construct using [NewScannerContext].
+type ScannerContext struct {
+	s *bufio.Scanner
+}
+
+// NewScannerContext returns a new [*ScannerContext] reading from rc.
+func NewScannerContext(ctx context.Context, rc io.ReadCloser) *ScannerContext {
+	return &ScannerContext{bufio.NewScanner(contextReader{ctx, rc})}
+}
+
+// Buffer is like [*bufio.Scanner.Buffer].
+func (s *ScannerContext) Buffer(buf []byte, max int) {
+	s.s.Buffer(buf, max)
+}
+
+// Split is like [*bufio.Scanner.Split].
+func (s *ScannerContext) Split(split bufio.SplitFunc) {
+	s.s.Split(split)
+}
+
+// Scan is like [*bufio.Scanner.Scan].
+func (s *ScannerContext) Scan() bool {
+	return s.s.Scan()
+}
+
+// Bytes is like [*bufio.Scanner.Bytes].
+func (s *ScannerContext) Bytes() []byte {
+	return s.s.Bytes()
+}
+
+// Text is like [*bufio.Scanner.Text].
+func (s *ScannerContext) Text() string {
+	return s.s.Text()
+}
+
+// Err is like [*bufio.Scanner.Err] but may also return the context error.
+func (s *ScannerContext) Err() error {
+	return s.s.Err()
+}
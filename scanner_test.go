@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScannerContextWithWords(t *testing.T) {
+	sc := NewScannerContext(context.Background(), NopReadCloser(strings.NewReader("  context aware\nscanner ")))
+	sc.Split(bufio.ScanWords)
+	var words []string
+	for sc.Scan() {
+		words = append(words, sc.Text())
+	}
+	require.NoError(t, sc.Err())
+	assert.Equal(t, []string{"context", "aware", "scanner"}, words)
+}
+
+func TestScannerContextWithCustomSplit(t *testing.T) {
+	// Split records on commas using a small buffer.
+	splitCommas := func(data []byte, atEOF bool) (int, []byte, error) {
+		if idx := bytes.IndexByte(data, ','); idx >= 0 {
+			return idx + 1, data[:idx], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+	sc := NewScannerContext(context.Background(), NopReadCloser(strings.NewReader("a,bb,ccc")))
+	sc.Split(splitCommas)
+	sc.Buffer(make([]byte, 4), 8)
+	var records []string
+	for sc.Scan() {
+		records = append(records, string(sc.Bytes()))
+	}
+	require.NoError(t, sc.Err())
+	assert.Equal(t, []string{"a", "bb", "ccc"}, records)
+}
+
+func TestScannerContextWithCancelledContext(t *testing.T) {
+	// Create a reader that blocks until Close is called.
+	insideReader := make(chan struct{})
+	unblockReader := make(chan struct{})
+	rc := &iotest.FuncReadCloser{
+		ReadFunc: func(b []byte) (int, error) {
+			close(insideReader)
+			<-unblockReader
+			return 0, io.EOF
+		},
+		CloseFunc: func() error {
+			close(unblockReader)
+			return nil
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-insideReader
+		cancel()
+	}()
+
+	sc := NewScannerContext(ctx, rc)
+	assert.False(t, sc.Scan())
+	require.ErrorIs(t, sc.Err(), context.Canceled)
+}
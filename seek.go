@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"errors"
+	"io"
+)
+
+// SeekReadCloser is an [io.ReadCloser] that can seek.
+//
+// Reader wrappers such as [LimitReadCloser], [JoinReadCloser], and [NopReadCloser]
+// return a SeekReadCloser when the wrapped reader implements [io.Seeker], so
+// that wrapped files can still be rewound (e.g., to retry an upload). Use
+// a type assertion to access the Seek method.
+type SeekReadCloser interface {
+	io.ReadCloser
+	io.Seeker
+}
+
+// errNegativeOffset is returned when seeking before the start.
+var errNegativeOffset = errors.New("iox: seek to a negative offset")
+
+// readSeekCloser is the [SeekReadCloser] returned by [JoinReadCloser].
+type readSeekCloser struct {
+	readCloser
+}
+
+// Seek implements [io.Seeker].
+func (r readSeekCloser) Seek(offset int64, whence int) (int64, error) {
+	return r.Reader.(io.Seeker).Seek(offset, whence)
+}
+
+// nopReadSeekCloser is the [SeekReadCloser] returned by [NopReadCloser].
+type nopReadSeekCloser struct {
+	io.ReadSeeker
+}
+
+// Close implements [io.Closer].
+func (nopReadSeekCloser) Close() error {
+	return nil
+}
+
+// Unwrap returns the wrapped [io.Reader].
+func (r nopReadSeekCloser) Unwrap() io.Reader {
+	return r.ReadSeeker
+}
+
+// nopReadSeekCloserWriterTo is a [nopReadSeekCloser] forwarding [io.WriterTo].
+type nopReadSeekCloserWriterTo struct {
+	nopReadSeekCloser
+}
+
+// WriteTo implements [io.WriterTo].
+func (r nopReadSeekCloserWriterTo) WriteTo(w io.Writer) (int64, error) {
+	return r.ReadSeeker.(io.WriterTo).WriteTo(w)
+}
+
+// limitedSeekReadCloser is the [SeekReadCloser] returned by [LimitReadCloser].
+//
+// It exposes the window of n bytes starting at the offset of the underlying
+// reader when wrapping it, like an [*io.SectionReader].
+type limitedSeekReadCloser struct {
+	base  int64
+	limit int64
+	pos   int64
+	rc    SeekReadCloser
+}
+
+// newLimitedSeekReadCloser returns a new [*limitedSeekReadCloser] or nil
+// when we cannot determine the current offset of rc.
+func newLimitedSeekReadCloser(rc SeekReadCloser, n int64) *limitedSeekReadCloser {
+	base, err := rc.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil
+	}
+	return &limitedSeekReadCloser{base: base, limit: max(n, 0), rc: rc}
+}
+
+// Read implements [io.Reader].
+func (r *limitedSeekReadCloser) Read(buf []byte) (int, error) {
+	if r.pos >= r.limit {
+		return 0, io.EOF
+	}
+	count, err := r.rc.Read(buf[:min(int64(len(buf)), r.limit-r.pos)])
+	r.pos += int64(count)
+	return count, err
+}
+
+// Seek implements [io.Seeker] with offsets relative to the window.
+func (r *limitedSeekReadCloser) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.pos
+	case io.SeekEnd:
+		offset += r.limit
+	default:
+		return 0, errors.New("iox: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errNegativeOffset
+	}
+	if _, err := r.rc.Seek(r.base+offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	r.pos = offset
+	return offset, nil
+}
+
+// Close implements [io.Closer].
+func (r *limitedSeekReadCloser) Close() error {
+	return r.rc.Close()
+}
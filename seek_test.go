@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitReadCloserPreservesSeek(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.txt")
+	require.NoError(t, os.WriteFile(path, []byte("0123456789"), 0600))
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	_, err = file.Seek(2, io.SeekStart)
+	require.NoError(t, err)
+
+	rc := LimitReadCloser(file, 5)
+	src, ok := rc.(SeekReadCloser)
+	require.True(t, ok)
+
+	data, err := io.ReadAll(src)
+	require.NoError(t, err)
+	assert.Equal(t, "23456", string(data))
+
+	// rewind and read again
+	offset, err := src.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), offset)
+	data, err = io.ReadAll(src)
+	require.NoError(t, err)
+	assert.Equal(t, "23456", string(data))
+
+	// seek relative to the end and to the current position
+	offset, err = src.Seek(-2, io.SeekEnd)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), offset)
+	offset, err = src.Seek(1, io.SeekCurrent)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), offset)
+	data, err = io.ReadAll(src)
+	require.NoError(t, err)
+	assert.Equal(t, "6", string(data))
+
+	_, err = src.Seek(-1, io.SeekStart)
+	assert.Error(t, err)
+	_, err = src.Seek(0, 42)
+	assert.Error(t, err)
+
+	require.NoError(t, src.Close())
+	_, err = file.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
+
+func TestReaderWrappersPreserveSeek(t *testing.T) {
+	t.Run("NopReadCloser", func(t *testing.T) {
+		rc := NopReadCloser(strings.NewReader("abc"))
+		_, ok := rc.(SeekReadCloser)
+		assert.True(t, ok)
+		_, ok = rc.(io.WriterTo)
+		assert.True(t, ok)
+
+		_, ok = NopReadCloser(io.MultiReader()).(SeekReadCloser)
+		assert.False(t, ok)
+	})
+
+	t.Run("JoinReadCloser", func(t *testing.T) {
+		rc := JoinReadCloser(strings.NewReader("abc"), NopReadCloser(nil))
+		src, ok := rc.(SeekReadCloser)
+		require.True(t, ok)
+		_, err := io.ReadAll(src)
+		require.NoError(t, err)
+		_, err = src.Seek(1, io.SeekStart)
+		require.NoError(t, err)
+		data, err := io.ReadAll(src)
+		require.NoError(t, err)
+		assert.Equal(t, "bc", string(data))
+	})
+
+	t.Run("LimitReadCloser without Seek", func(t *testing.T) {
+		rc := LimitReadCloser(NopReadCloser(io.MultiReader(strings.NewReader("abcdef"))), 3)
+		_, ok := rc.(SeekReadCloser)
+		assert.False(t, ok)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		assert.Equal(t, "abc", string(data))
+	})
+}
@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"math/bits"
+	"sync/atomic"
+)
+
+// IOStats contains statistics about the I/O calls performed through a wrapper.
+type IOStats struct {
+	// Bytes is the number of bytes transferred.
+	Bytes int64
+
+	// Calls is the number of I/O calls.
+	Calls int64
+
+	// Sizes is the histogram of the per-call sizes, or nil when the wrapper
+	// is not recording sizes (see, e.g., [LockedWriteCloser.RecordSizes]).
+	Sizes *SizeHistogram
+}
+
+// SizeHistogram counts I/O calls by the number of bytes they transferred.
+//
+// It uses power-of-two buckets, which suffice to tell whether a copy gets
+// 32 KiB reads or a storm of 512-byte ones when tuning buffer sizes.
+type SizeHistogram struct {
+	counts [bits.UintSize + 1]int64
+}
+
+// SizeBucket is a bucket of a [SizeHistogram].
+type SizeBucket struct {
+	// Min is the minimum size of the calls counted by the bucket.
+	Min int
+
+	// Max is the maximum size of the calls counted by the bucket.
+	Max int
+
+	// Count is the number of calls counted by the bucket.
+	Count int64
+}
+
+// Buckets returns the nonempty buckets sorted by size.
+//
+// The first bucket counts zero-size calls, while the following ones
+// count calls sized between consecutive powers of two.
+func (h *SizeHistogram) Buckets() []SizeBucket {
+	var buckets []SizeBucket
+	for idx, count := range h.counts {
+		if count <= 0 {
+			continue
+		}
+		bucket := SizeBucket{Count: count}
+		if idx > 0 {
+			bucket.Min = 1 << (idx - 1)
+			bucket.Max = int(uint(1)<<idx - 1)
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets
+}
+
+// Count returns the number of calls counted by the bucket containing size.
+func (h *SizeHistogram) Count(size int) int64 {
+	return h.counts[sizeBucket(size)]
+}
+
+// sizeBucket returns the index of the [SizeHistogram] bucket containing size.
+func sizeBucket(size int) int {
+	return bits.Len(uint(max(size, 0)))
+}
+
+// sizeCounters is a concurrency-safe [SizeHistogram].
+type sizeCounters struct {
+	counts [bits.UintSize + 1]atomic.Int64
+}
+
+// observe accounts for a call transferring size bytes.
+func (c *sizeCounters) observe(size int) {
+	c.counts[sizeBucket(size)].Add(1)
+}
+
+// snapshot returns a [*SizeHistogram] with the current counts, or nil if c is nil.
+func (c *sizeCounters) snapshot() *SizeHistogram {
+	if c == nil {
+		return nil
+	}
+	h := &SizeHistogram{}
+	for idx := range c.counts {
+		h.counts[idx] = c.counts[idx].Load()
+	}
+	return h
+}
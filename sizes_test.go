@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSizeHistogram(t *testing.T) {
+	c := &sizeCounters{}
+	for _, size := range []int{0, 1, 2, 3, 4, 512, 32768, 65535, -1} {
+		c.observe(size)
+	}
+	h := c.snapshot()
+	assert.Equal(t, []SizeBucket{
+		{Min: 0, Max: 0, Count: 2},
+		{Min: 1, Max: 1, Count: 1},
+		{Min: 2, Max: 3, Count: 2},
+		{Min: 4, Max: 7, Count: 1},
+		{Min: 512, Max: 1023, Count: 1},
+		{Min: 32768, Max: 65535, Count: 2},
+	}, h.Buckets())
+	assert.Equal(t, int64(2), h.Count(40000))
+	assert.Equal(t, int64(0), h.Count(100))
+
+	var nilCounters *sizeCounters
+	assert.Nil(t, nilCounters.snapshot())
+	assert.Nil(t, (&SizeHistogram{}).Buckets())
+}
@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"io"
+	"sync"
+)
+
+// SnapshotWriteCloser is an [io.WriteCloser] retaining the first bytes written.
+//
+// It passes all the bytes through to the underlying [io.WriteCloser] while keeping
+// a copy of the first N successfully written bytes, which is useful to inspect a
+// truncated copy of a payload without buffering the entire stream.
+//
+// All methods are safe for concurrent use.
+//
+// Construct using [NewSnapshotWriteCloser].
+type SnapshotWriteCloser struct {
+	buf   []byte
+	limit int
+	mu    sync.Mutex
+	w     io.WriteCloser
+}
+
+// NewSnapshotWriteCloser wraps w and returns a [*SnapshotWriteCloser] retaining
+// the first n bytes written.
+func NewSnapshotWriteCloser(w io.WriteCloser, n int) *SnapshotWriteCloser {
+	return &SnapshotWriteCloser{limit: max(n, 0), w: w}
+}
+
+// Write implements [io.Writer].
+//
+// It holds the lock while writing, so concurrent writes are serialized and
+// the snapshot matches the order in which the bytes were written.
+func (w *SnapshotWriteCloser) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	count, err := w.w.Write(data)
+	if room := w.limit - len(w.buf); room > 0 {
+		w.buf = append(w.buf, data[:min(room, count)]...)
+	}
+	return count, err
+}
+
+// Close implements [io.Closer].
+func (w *SnapshotWriteCloser) Close() error {
+	return w.w.Close()
+}
+
+// Snapshot returns a copy of the first bytes written so far.
+func (w *SnapshotWriteCloser) Snapshot() []byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]byte{}, w.buf...)
+}
@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotWriteCloser(t *testing.T) {
+	// Copy through the snapshot writer using small reads.
+	const payload = "hello from the snapshot writer"
+	sr := strings.NewReader(payload)
+	rc := &iotest.FuncReadCloser{
+		ReadFunc: func(b []byte) (int, error) {
+			return sr.Read(b[:min(len(b), 3)])
+		},
+		CloseFunc: func() error { return nil },
+	}
+
+	buff := &bytes.Buffer{}
+	swc := NewSnapshotWriteCloser(NopWriteCloser(buff), 10)
+	count, err := CopyContext(context.Background(), NewLockedWriteCloser(swc), rc)
+	require.NoError(t, err)
+	assert.Equal(t, len(payload), count)
+
+	// All bytes pass through but we only retain the first ones.
+	assert.Equal(t, payload, buff.String())
+	snapshot := swc.Snapshot()
+	assert.Equal(t, payload[:10], string(snapshot))
+
+	// Modifying the returned slice does not modify the snapshot.
+	snapshot[0] = 'X'
+	assert.Equal(t, payload[:10], string(swc.Snapshot()))
+}
+
+func TestSnapshotWriteCloserConcurrentWrites(t *testing.T) {
+	// The snapshot must match the bytes actually written, in the same order,
+	// and the underlying writer, which is not safe for concurrent use, must
+	// only see serialized writes (which the race detector checks).
+	buff := &bytes.Buffer{}
+	swc := NewSnapshotWriteCloser(NopWriteCloser(buff), 1<<20)
+	wg := &sync.WaitGroup{}
+	for idx := range 8 {
+		wg.Go(func() {
+			for range 100 {
+				_, err := swc.Write([]byte(strings.Repeat(string(rune('a'+idx)), 8)))
+				require.NoError(t, err)
+			}
+		})
+	}
+	wg.Wait()
+	assert.Equal(t, buff.String(), string(swc.Snapshot()))
+}
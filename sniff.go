@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+)
+
+// SniffContext reads up to n bytes from rc and returns them, along with an
+// [io.ReadCloser] replaying them followed by the rest of rc.
+//
+// This is useful to detect the content type (e.g., using [net/http.DetectContentType])
+// or the protocol of a stream, and then to pass the whole stream to the code handling
+// it. The returned prefix is shorter than n bytes only when rc ends earlier or when
+// an error occurs. The replay reads the same bytes as the prefix, so you MUST NOT
+// modify the prefix before reading the replay.
+//
+// Each Read uses [ReadContext], so rc remains usable after cancellation when it
+// supports read deadlines, and is otherwise closed. The returned reader closes
+// rc when closed, so the caller MUST close either of them.
+//
+// The returned error is nil, including when rc ends before n bytes, or the error
+// caused by I/O or by the context, in which case the prefix contains the bytes read
+// before the error and the replay is still valid.
+func SniffContext(ctx context.Context, rc io.ReadCloser, n int) ([]byte, io.ReadCloser, error) {
+	// 1. read until we have n bytes, EOF, or an error
+	prefix := make([]byte, max(n, 0))
+	var (
+		count int
+		err   error
+	)
+	for count < len(prefix) && err == nil {
+		var nread int
+		nread, err = ReadContext(ctx, rc, prefix[count:])
+		count += nread
+	}
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+	prefix = prefix[:count]
+
+	// 2. stitch the prefix back in front of the rest of the stream
+	replay := JoinReadCloser(io.MultiReader(bytes.NewReader(prefix), rc), rc)
+	return prefix, replay, err
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	stdiotest "testing/iotest"
+	"time"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSniffContext(t *testing.T) {
+	t.Run("detecting the content type", func(t *testing.T) {
+		const payload = "<!DOCTYPE html><html><body>hello</body></html>"
+		var closed atomic.Bool
+		rc := &iotest.FuncReadCloser{
+			ReadFunc: stdiotest.OneByteReader(strings.NewReader(payload)).Read,
+			CloseFunc: func() error {
+				closed.Store(true)
+				return nil
+			},
+		}
+		prefix, replay, err := SniffContext(context.Background(), rc, 15)
+		require.NoError(t, err)
+		assert.Equal(t, "<!DOCTYPE html>", string(prefix))
+		assert.Equal(t, "text/html; charset=utf-8", http.DetectContentType(prefix))
+
+		data, err := io.ReadAll(replay)
+		require.NoError(t, err)
+		assert.Equal(t, payload, string(data))
+		require.NoError(t, replay.Close())
+		assert.True(t, closed.Load())
+	})
+
+	t.Run("streams shorter than n", func(t *testing.T) {
+		prefix, replay, err := SniffContext(context.Background(), NopReadCloser(strings.NewReader("abc")), 512)
+		require.NoError(t, err)
+		assert.Equal(t, "abc", string(prefix))
+		data, err := io.ReadAll(replay)
+		require.NoError(t, err)
+		assert.Equal(t, "abc", string(data))
+	})
+
+	t.Run("errors preserve the bytes read so far", func(t *testing.T) {
+		expected := errors.New("mocked read error")
+		var calls int
+		rc := &iotest.FuncReadCloser{
+			ReadFunc: func(b []byte) (int, error) {
+				if calls++; calls == 1 {
+					return copy(b, "ab"), nil
+				}
+				return 0, expected
+			},
+			CloseFunc: func() error { return nil },
+		}
+		prefix, replay, err := SniffContext(context.Background(), rc, 4)
+		require.ErrorIs(t, err, expected)
+		assert.Equal(t, "ab", string(prefix))
+		require.NotNil(t, replay)
+	})
+
+	t.Run("cancellation keeps connections usable", func(t *testing.T) {
+		client, server := net.Pipe()
+		defer client.Close()
+		go client.Write([]byte("SS"))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		prefix, replay, err := SniffContext(ctx, server, 4)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, "SS", string(prefix))
+
+		go func() {
+			client.Write([]byte("H-2.0"))
+			client.Close()
+		}()
+		data, err := io.ReadAll(replay)
+		require.NoError(t, err)
+		assert.Equal(t, "SSH-2.0", string(data))
+		require.NoError(t, replay.Close())
+	})
+}
@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+// SpooledBuffer is an [io.WriteCloser] that keeps data in memory up to a
+// threshold and transparently spills it to a temporary file beyond it.
+//
+// Use [*SpooledBuffer.Reader] to retrieve the data written so far. Close removes
+// the temporary file, if any, and makes subsequent operations fail with [ErrClosed].
+//
+// Because [CopyContext] always closes its writer, wrap the buffer using
+// [NopWriteCloser] when copying into it, and Close it once done reading.
+//
+// All methods are safe for concurrent use.
+//
+// Construct using [NewSpooledBuffer].
+type SpooledBuffer struct {
+	cs        closeState
+	dir       string
+	file      *os.File
+	mem       *bytes.Buffer
+	mu        sync.Mutex
+	size      int64
+	threshold int
+}
+
+// NewSpooledBuffer returns a new [*SpooledBuffer] keeping up to threshold bytes
+// in memory and spilling to a temporary file created inside dir beyond that.
+//
+// When dir is empty, we use the default directory for temporary files (see [os.CreateTemp]).
+func NewSpooledBuffer(dir string, threshold int) *SpooledBuffer {
+	return &SpooledBuffer{dir: dir, mem: &bytes.Buffer{}, threshold: threshold}
+}
+
+// Write implements [io.Writer].
+//
+// The returned error is nil, [ErrClosed] when closed, or the error occurred when
+// creating or writing the temporary file.
+func (sb *SpooledBuffer) Write(data []byte) (int, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if err := sb.cs.check(); err != nil {
+		return 0, err
+	}
+	if sb.file == nil && sb.mem.Len()+len(data) > sb.threshold {
+		if err := sb.spill(); err != nil {
+			return 0, err
+		}
+	}
+	var (
+		count int
+		err   error
+	)
+	if sb.file != nil {
+		count, err = sb.file.Write(data)
+	} else {
+		count, err = sb.mem.Write(data)
+	}
+	sb.size += int64(count)
+	return count, err
+}
+
+// spill moves the in-memory data to a new temporary file.
+//
+// The caller MUST hold the mutex.
+func (sb *SpooledBuffer) spill() error {
+	file, err := os.CreateTemp(sb.dir, "iox-spool-*")
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(sb.mem.Bytes()); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	sb.file = file
+	sb.mem = &bytes.Buffer{}
+	return nil
+}
+
+// Size returns the number of bytes written so far.
+func (sb *SpooledBuffer) Size() int64 {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.size
+}
+
+// Spilled returns whether the data has been spilled to a temporary file.
+func (sb *SpooledBuffer) Spilled() bool {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.file != nil
+}
+
+// Reader returns an [io.ReadCloser] reading the data written so far.
+//
+// Each call returns an independent reader. Closing the returned reader is a
+// no-op, while closing the [*SpooledBuffer] invalidates all readers.
+//
+// The returned error is nil or [ErrClosed] when closed.
+func (sb *SpooledBuffer) Reader() (io.ReadCloser, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if err := sb.cs.check(); err != nil {
+		return nil, err
+	}
+	if sb.file != nil {
+		return NopReadCloser(io.NewSectionReader(sb.file, 0, sb.size)), nil
+	}
+	return NopReadCloser(bytes.NewReader(sb.mem.Bytes())), nil
+}
+
+// Close removes the temporary file, if any.
+//
+// Returns nil, [ErrClosed], or the error occurred when removing the temporary file.
+func (sb *SpooledBuffer) Close() error {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.cs.close(func() error {
+		sb.mem = &bytes.Buffer{}
+		if sb.file == nil {
+			return nil
+		}
+		return errors.Join(sb.file.Close(), os.Remove(sb.file.Name()))
+	})
+}
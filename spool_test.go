@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpooledBuffer(t *testing.T) {
+	sb := NewSpooledBuffer(t.TempDir(), 8)
+
+	// Small writes are kept in memory.
+	_, err := sb.Write([]byte("iox-"))
+	require.NoError(t, err)
+	assert.False(t, sb.Spilled())
+
+	rc, err := sb.Reader()
+	require.NoError(t, err)
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "iox-", string(data))
+
+	// Copying past the threshold spills to disk.
+	const payload = "spooled-buffer"
+	src := &iotest.FuncReadCloser{
+		ReadFunc:  strings.NewReader(payload).Read,
+		CloseFunc: func() error { return nil },
+	}
+	count, err := CopyContext(context.Background(), NewLockedWriteCloser(NopWriteCloser(sb)), src)
+	require.NoError(t, err)
+	assert.Equal(t, len(payload), count)
+	assert.True(t, sb.Spilled())
+	assert.Equal(t, int64(len("iox-"+payload)), sb.Size())
+
+	rc, err = sb.Reader()
+	require.NoError(t, err)
+	data, err = io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "iox-"+payload, string(data))
+	require.NoError(t, rc.Close())
+
+	// Close removes the temporary file.
+	filename := sb.file.Name()
+	require.NoError(t, sb.Close())
+	_, err = os.Stat(filename)
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	// Once closed, all operations fail.
+	require.ErrorIs(t, sb.Close(), ErrClosed)
+	_, err = sb.Write([]byte("x"))
+	require.ErrorIs(t, err, ErrClosed)
+	_, err = sb.Reader()
+	require.ErrorIs(t, err, ErrClosed)
+}
+
+func TestSpooledBufferWithInvalidDir(t *testing.T) {
+	sb := NewSpooledBuffer("/nonexistent/directory", 1)
+	defer sb.Close()
+
+	count, err := sb.Write([]byte("iox"))
+	require.Error(t, err)
+	assert.Equal(t, 0, count)
+	assert.False(t, sb.Spilled())
+}
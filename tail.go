@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import "sync"
+
+// TailWriteCloser is an [io.WriteCloser] retaining only the last bytes written into it.
+//
+// It uses a fixed-size ring buffer, so memory usage is bounded regardless of how
+// much data flows through it. This makes it a suitable [WithTee] target to include
+// the tail of a stream in crash reports and diagnostics.
+//
+// All methods are safe for concurrent use.
+//
+// Construct using [NewTailWriteCloser].
+type TailWriteCloser struct {
+	buf   []byte
+	count int64
+	cs    closeState
+	mu    sync.Mutex
+	start int
+}
+
+// NewTailWriteCloser returns a new [*TailWriteCloser] retaining the last n bytes.
+//
+// Nonpositive values of n cause the writer to retain nothing while still counting bytes.
+func NewTailWriteCloser(n int) *TailWriteCloser {
+	return &TailWriteCloser{buf: make([]byte, 0, max(n, 0))}
+}
+
+// Write implements [io.Writer].
+//
+// It never fails unless the writer has been closed, in which case it returns [ErrClosed].
+func (w *TailWriteCloser) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.cs.check(); err != nil {
+		return 0, err
+	}
+	w.count += int64(len(data))
+
+	// 1. only the last cap(w.buf) bytes of data may survive
+	tail := data[len(data)-min(len(data), cap(w.buf)):]
+
+	// 2. grow the buffer until it is full
+	if room := cap(w.buf) - len(w.buf); room > 0 {
+		count := min(room, len(tail))
+		w.buf = append(w.buf, tail[:count]...)
+		tail = tail[count:]
+	}
+
+	// 3. overwrite the oldest bytes, possibly wrapping around
+	for len(tail) > 0 {
+		count := copy(w.buf[w.start:], tail)
+		w.start = (w.start + count) % len(w.buf)
+		tail = tail[count:]
+	}
+	return len(data), nil
+}
+
+// Bytes returns a copy of the retained bytes, oldest first.
+//
+// It also works after Close, so the tail remains available for reporting.
+func (w *TailWriteCloser) Bytes() []byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	out := make([]byte, 0, len(w.buf))
+	out = append(out, w.buf[w.start:]...)
+	return append(out, w.buf[:w.start]...)
+}
+
+// Count returns the total number of bytes written, including the discarded ones.
+func (w *TailWriteCloser) Count() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.count
+}
+
+// Close implements [io.Closer].
+//
+// Subsequent writes fail with [ErrClosed], while Bytes and Count keep working.
+func (w *TailWriteCloser) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.cs.close(func() error { return nil })
+}
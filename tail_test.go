@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTailWriteCloser(t *testing.T) {
+	t.Run("short writes are retained entirely", func(t *testing.T) {
+		w := NewTailWriteCloser(8)
+		io.WriteString(w, "abc")
+		io.WriteString(w, "de")
+		assert.Equal(t, "abcde", string(w.Bytes()))
+		assert.Equal(t, int64(5), w.Count())
+	})
+
+	t.Run("older bytes are overwritten", func(t *testing.T) {
+		w := NewTailWriteCloser(4)
+		for _, chunk := range []string{"ab", "cde", "f", "ghijk", "l"} {
+			count, err := io.WriteString(w, chunk)
+			require.NoError(t, err)
+			assert.Equal(t, len(chunk), count)
+		}
+		assert.Equal(t, "ijkl", string(w.Bytes()))
+		assert.Equal(t, int64(12), w.Count())
+	})
+
+	t.Run("a large write keeps its tail", func(t *testing.T) {
+		w := NewTailWriteCloser(3)
+		io.WriteString(w, "a")
+		io.WriteString(w, "0123456789")
+		assert.Equal(t, "789", string(w.Bytes()))
+	})
+
+	t.Run("nonpositive sizes retain nothing", func(t *testing.T) {
+		w := NewTailWriteCloser(-1)
+		count, err := io.WriteString(w, "abc")
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.Empty(t, w.Bytes())
+		assert.Equal(t, int64(3), w.Count())
+	})
+
+	t.Run("Bytes works after Close", func(t *testing.T) {
+		w := NewTailWriteCloser(4)
+		io.WriteString(w, "hello")
+		require.NoError(t, w.Close())
+		assert.ErrorIs(t, w.Close(), ErrClosed)
+		_, err := io.WriteString(w, "x")
+		assert.ErrorIs(t, err, ErrClosed)
+		assert.Equal(t, "ello", string(w.Bytes()))
+	})
+
+	t.Run("works as a tee target", func(t *testing.T) {
+		payload := strings.Repeat("0123456789", 100) + "THE END"
+		tail := NewTailWriteCloser(7)
+		lwc := NewLockedWriteCloser(NopWriteCloser(io.Discard))
+		count, err := CopyContext(context.Background(), lwc,
+			NopReadCloser(strings.NewReader(payload)), WithTee(tail))
+		require.NoError(t, err)
+		assert.Equal(t, len(payload), count)
+		assert.Equal(t, "THE END", string(tail.Bytes()))
+	})
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// WithTee causes the copy to mirror the bytes written into the destination to w.
+//
+// Mirroring is best effort: errors writing into w do not affect the copy. Because
+// the copy may continue in the background after context cancellation, w should be
+// safe to use after the copy returns. Teeing disables the zero-copy fast path
+// of [CopyContext].
+func WithTee(w io.Writer) CopyOption {
+	return func(cfg *copyConfig) {
+		cfg.wrapWriter = append(cfg.wrapWriter, func(dst io.Writer) io.Writer {
+			return teeWriter{dst, w}
+		})
+	}
+}
+
+// WithTeeFile causes the copy to mirror the bytes written into the destination
+// to the file at path, which is useful to capture a stream when debugging.
+//
+// The file is created, or truncated, when the copy writes for the first time,
+// and is closed when the copy returns, including on context cancellation. Like
+// for [WithTee], mirroring is best effort: errors creating or writing the file
+// do not affect the copy. Teeing disables the zero-copy fast path of
+// [CopyContext].
+func WithTeeFile(path string) CopyOption {
+	return func(cfg *copyConfig) {
+		cfg.wrapWriter = append(cfg.wrapWriter, func(dst io.Writer) io.Writer {
+			tf := &teeFile{path: path}
+			cfg.closers = append(cfg.closers, tf)
+			return teeWriter{dst, tf}
+		})
+	}
+}
+
+// teeWriter is the [io.Writer] used by [WithTee] and [WithTeeFile].
+type teeWriter struct {
+	w   io.Writer
+	tee io.Writer
+}
+
+// Write implements [io.Writer].
+func (w teeWriter) Write(data []byte) (int, error) {
+	count, err := w.w.Write(data)
+	if count > 0 {
+		w.tee.Write(data[:count])
+	}
+	return count, err
+}
+
+// teeFile is the lazily-created file used by [WithTeeFile].
+type teeFile struct {
+	closed bool
+	file   *os.File
+	mu     sync.Mutex
+	path   string
+}
+
+// Write implements [io.Writer].
+func (f *teeFile) Write(data []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return 0, ErrClosed
+	}
+	if f.file == nil {
+		file, err := os.Create(f.path)
+		if err != nil {
+			return 0, err
+		}
+		f.file = file
+	}
+	return f.file.Write(data)
+}
+
+// Close implements [io.Closer].
+func (f *teeFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Close()
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTee(t *testing.T) {
+	t.Run("mirrors the copied bytes", func(t *testing.T) {
+		var dst, tee bytes.Buffer
+		count, err := CopyContext(context.Background(), NewLockedWriteCloser(NopWriteCloser(&dst)),
+			NopReadCloser(strings.NewReader("iox-tee")), WithTee(&tee))
+		require.NoError(t, err)
+		assert.Equal(t, 7, count)
+		assert.Equal(t, "iox-tee", dst.String())
+		assert.Equal(t, "iox-tee", tee.String())
+	})
+
+	t.Run("tee errors do not affect the copy", func(t *testing.T) {
+		var dst bytes.Buffer
+		tee := &iotest.FuncWriteCloser{
+			WriteFunc: func(b []byte) (int, error) { return 0, errors.New("mocked error") },
+			CloseFunc: func() error { return nil },
+		}
+		count, err := CopyContext(context.Background(), NewLockedWriteCloser(NopWriteCloser(&dst)),
+			NopReadCloser(strings.NewReader("iox-tee")), WithTee(tee))
+		require.NoError(t, err)
+		assert.Equal(t, 7, count)
+		assert.Equal(t, "iox-tee", dst.String())
+	})
+}
+
+func TestWithTeeFile(t *testing.T) {
+	t.Run("mirrors the copied bytes", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "capture")
+		var dst bytes.Buffer
+		_, err := CopyContext(context.Background(), NewLockedWriteCloser(NopWriteCloser(&dst)),
+			NopReadCloser(strings.NewReader("iox-tee")), WithTeeFile(path))
+		require.NoError(t, err)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "iox-tee", string(data))
+	})
+
+	t.Run("the file is created lazily", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "capture")
+		_, err := CopyContext(context.Background(), NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{})),
+			NopReadCloser(strings.NewReader("")), WithTeeFile(path))
+		require.NoError(t, err)
+		_, err = os.Stat(path)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("the file is closed on cancellation", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "capture")
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		unblock := make(chan struct{})
+		first := true
+		rc := &iotest.FuncReadCloser{
+			ReadFunc: func(b []byte) (int, error) {
+				if first {
+					first = false
+					return copy(b, "iox"), nil
+				}
+				<-unblock
+				return 0, io.ErrClosedPipe
+			},
+			CloseFunc: func() error {
+				close(unblock)
+				return nil
+			},
+		}
+		var dst bytes.Buffer
+		var tf *teeFile
+		opts := []CopyOption{WithTeeFile(path), func(cfg *copyConfig) {
+			cfg.wrapWriter = append(cfg.wrapWriter, func(w io.Writer) io.Writer {
+				tf = cfg.closers[0].(*teeFile)
+				return w
+			})
+		}}
+		_, err := CopyContext(ctx, NewLockedWriteCloser(NopWriteCloser(&dst)), rc, opts...)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.NotNil(t, tf)
+		tf.mu.Lock()
+		assert.True(t, tf.closed)
+		tf.mu.Unlock()
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "iox", string(data))
+	})
+
+	t.Run("errors creating the file do not affect the copy", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "missing", "capture")
+		var dst bytes.Buffer
+		count, err := CopyContext(context.Background(), NewLockedWriteCloser(NopWriteCloser(&dst)),
+			NopReadCloser(strings.NewReader("iox-tee")), WithTeeFile(path))
+		require.NoError(t, err)
+		assert.Equal(t, 7, count)
+	})
+}
@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrReadTimeout is returned by the reader returned by [TimeoutReadCloser] when a
+// Read takes too long. It implements Timeout, hence [IsTimeout] recognizes it.
+var ErrReadTimeout error = readTimeoutError{}
+
+// readTimeoutError is the type of [ErrReadTimeout].
+type readTimeoutError struct{}
+
+// Error implements error.
+func (readTimeoutError) Error() string {
+	return "read timeout"
+}
+
+// Timeout returns true, like the errors returned by [net.Conn] on deadlines.
+func (readTimeoutError) Timeout() bool {
+	return true
+}
+
+// TimeoutReadCloser wraps rc such that each Read taking longer than d closes rc,
+// to unblock the Read, and fails with [ErrReadTimeout].
+//
+// This provides per-operation timeouts for readers that do not support
+// SetReadDeadline. Once a Read has timed out, rc is closed, subsequent reads fail
+// with [ErrReadTimeout], and Close returns nil. Nonpositive values of d disable
+// the timeout and TimeoutReadCloser returns rc.
+//
+// Close may be called concurrently with Read, while concurrent reads are not
+// allowed, like for most readers.
+func TimeoutReadCloser(rc io.ReadCloser, d time.Duration) io.ReadCloser {
+	if d <= 0 {
+		return rc
+	}
+	return &timeoutReadCloser{d: d, rc: rc}
+}
+
+// timeoutReadCloser is the [io.ReadCloser] returned by [TimeoutReadCloser].
+type timeoutReadCloser struct {
+	cs       closeState
+	d        time.Duration
+	mu       sync.Mutex
+	rc       io.ReadCloser
+	timedOut bool
+}
+
+// Read implements [io.Reader].
+func (r *timeoutReadCloser) Read(buf []byte) (int, error) {
+	// 1. refuse reading after a timeout or after close
+	if err := r.check(); err != nil {
+		return 0, err
+	}
+
+	// 2. arm the timer closing the reader on timeout
+	expired := make(chan struct{})
+	timer := time.AfterFunc(r.d, func() {
+		defer close(expired)
+		r.expire()
+	})
+
+	// 3. read and, if the timer fired, wait for it to complete
+	count, err := r.rc.Read(buf)
+	if !timer.Stop() {
+		<-expired
+	}
+
+	// 4. map the error caused by closing on timeout
+	if terr := r.check(); terr == ErrReadTimeout {
+		err = terr
+	}
+	return count, err
+}
+
+// check returns [ErrReadTimeout] after a timeout, [ErrClosed] when closed, or nil.
+func (r *timeoutReadCloser) check() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.timedOut {
+		return ErrReadTimeout
+	}
+	return r.cs.check()
+}
+
+// expire closes the reader on timeout unless already closed.
+func (r *timeoutReadCloser) expire() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cs.err == nil && !r.timedOut {
+		r.timedOut = true
+		r.rc.Close()
+	}
+}
+
+// Close implements [io.Closer].
+func (r *timeoutReadCloser) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.timedOut {
+		return nil
+	}
+	return r.cs.close(r.rc.Close)
+}
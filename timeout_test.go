@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeoutReadCloser(t *testing.T) {
+	t.Run("fast reads succeed", func(t *testing.T) {
+		rc := TimeoutReadCloser(NopReadCloser(strings.NewReader("hello")), time.Second)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(data))
+		require.NoError(t, rc.Close())
+		assert.ErrorIs(t, rc.Close(), ErrClosed)
+		_, err = rc.Read(make([]byte, 1))
+		assert.ErrorIs(t, err, ErrClosed)
+	})
+
+	t.Run("slow reads time out", func(t *testing.T) {
+		unblock := make(chan struct{})
+		closed := &atomic.Int64{}
+		rc := TimeoutReadCloser(&iotest.FuncReadCloser{
+			ReadFunc: func(buf []byte) (int, error) {
+				<-unblock
+				return 0, io.ErrClosedPipe
+			},
+			CloseFunc: func() error {
+				if closed.Add(1) == 1 {
+					close(unblock)
+				}
+				return nil
+			},
+		}, 10*time.Millisecond)
+
+		_, err := rc.Read(make([]byte, 1))
+		require.ErrorIs(t, err, ErrReadTimeout)
+		assert.True(t, IsTimeout(err))
+		assert.Equal(t, int64(1), closed.Load())
+
+		_, err = rc.Read(make([]byte, 1))
+		require.ErrorIs(t, err, ErrReadTimeout)
+		require.NoError(t, rc.Close())
+		assert.Equal(t, int64(1), closed.Load())
+	})
+
+	t.Run("nonpositive durations disable the timeout", func(t *testing.T) {
+		rc := NopReadCloser(strings.NewReader("hello"))
+		assert.Equal(t, rc, TimeoutReadCloser(rc, 0))
+	})
+}
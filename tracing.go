@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import "context"
+
+// Tracer starts spans describing copies.
+//
+// The oteliox subpackage adapts an OpenTelemetry tracer to this interface.
+//
+// Implementations MUST be safe for concurrent use.
+type Tracer interface {
+	// StartSpan starts a new [Span] with the given name.
+	StartSpan(ctx context.Context, name string) Span
+}
+
+// Span describes an ongoing copy.
+type Span interface {
+	// RecordFirstByte is called once when the copy reads the first bytes.
+	RecordFirstByte()
+
+	// End is called once with the number of bytes copied and the final error.
+	End(count int, err error)
+}
+
+// WithTracer causes the copy to record a [Span] using the given [Tracer].
+//
+// The span records the time to the first byte, the number of bytes copied,
+// and the final error. [DuplexCopyContext] records a span per direction.
+func WithTracer(tracer Tracer) CopyOption {
+	return func(cfg *copyConfig) {
+		cfg.tracer = tracer
+	}
+}
+
+// withSpanName sets the name of the span recorded by [WithTracer].
+func withSpanName(name string) CopyOption {
+	return func(cfg *copyConfig) {
+		cfg.spanName = name
+	}
+}
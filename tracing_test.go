@@ -0,0 +1,128 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// spanRecord records the calls to a [Span].
+type spanRecord struct {
+	count     int
+	ended     bool
+	err       error
+	firstByte int
+	name      string
+}
+
+// tracerRecorder is a [Tracer] recording the started spans.
+type tracerRecorder struct {
+	mu    sync.Mutex
+	spans []*spanRecord
+}
+
+func (t *tracerRecorder) StartSpan(ctx context.Context, name string) Span {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rec := &spanRecord{name: name}
+	t.spans = append(t.spans, rec)
+	return &recordingSpan{rec}
+}
+
+// recordingSpan is the [Span] returned by [*tracerRecorder.StartSpan].
+type recordingSpan struct {
+	rec *spanRecord
+}
+
+func (s *recordingSpan) RecordFirstByte() {
+	s.rec.firstByte++
+}
+
+func (s *recordingSpan) End(count int, err error) {
+	s.rec.ended, s.rec.count, s.rec.err = true, count, err
+}
+
+func TestCopyContextWithTracer(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		const payload = "hello, world"
+		tracer := &tracerRecorder{}
+		buff := &bytes.Buffer{}
+		lwc := NewLockedWriteCloser(NopWriteCloser(buff))
+
+		count, err := CopyContext(context.Background(), lwc,
+			newSmallReadsReadCloser(payload, 4), WithTracer(tracer))
+		require.NoError(t, err)
+		assert.Equal(t, payload, buff.String())
+
+		require.Len(t, tracer.spans, 1)
+		span := tracer.spans[0]
+		assert.Equal(t, "iox.CopyContext", span.name)
+		assert.Equal(t, 1, span.firstByte)
+		assert.True(t, span.ended)
+		assert.Equal(t, count, span.count)
+		assert.NoError(t, span.err)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		tracer := &tracerRecorder{}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		unblock := make(chan struct{})
+		rc := &iotest.FuncReadCloser{
+			ReadFunc: func(buf []byte) (int, error) {
+				<-unblock
+				return copy(buf, "late"), nil
+			},
+			CloseFunc: func() error {
+				close(unblock)
+				return nil
+			},
+		}
+		lwc := NewLockedWriteCloser(NopWriteCloser(&bytes.Buffer{}))
+
+		_, err := CopyContext(ctx, lwc, rc, WithTracer(tracer))
+		assert.ErrorIs(t, err, context.Canceled)
+
+		require.Len(t, tracer.spans, 1)
+		assert.True(t, tracer.spans[0].ended)
+		assert.ErrorIs(t, tracer.spans[0].err, context.Canceled)
+	})
+}
+
+func TestDuplexCopyContextWithTracer(t *testing.T) {
+	tracer := &tracerRecorder{}
+	conn1, peer1 := newTCPConnPair(t)
+	conn2, peer2 := newTCPConnPair(t)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		DuplexCopyContext(context.Background(), conn1, conn2, WithTracer(tracer))
+	}()
+
+	_, err := peer1.Write([]byte("ping"))
+	require.NoError(t, err)
+	buf := make([]byte, 4)
+	_, err = peer2.Read(buf)
+	require.NoError(t, err)
+	peer1.Close()
+	peer2.Close()
+	<-done
+
+	names := []string{}
+	for _, span := range tracer.spans {
+		assert.True(t, span.ended)
+		names = append(names, span.name)
+	}
+	assert.ElementsMatch(t, []string{
+		"iox.DuplexCopyContext conn1->conn2",
+		"iox.DuplexCopyContext conn2->conn1",
+	}, names)
+}
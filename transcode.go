@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+)
+
+// textCodec encodes and decodes binary data as text.
+type textCodec struct {
+	newEncoder func(w io.Writer) io.WriteCloser
+	newDecoder func(r io.Reader) io.Reader
+}
+
+// textCodecs maps lowercase encoding names to codecs.
+var textCodecs = map[string]textCodec{
+	"base64": {
+		newEncoder: func(w io.Writer) io.WriteCloser { return base64.NewEncoder(base64.StdEncoding, w) },
+		newDecoder: func(r io.Reader) io.Reader { return base64.NewDecoder(base64.StdEncoding, r) },
+	},
+	"base64url": {
+		newEncoder: func(w io.Writer) io.WriteCloser { return base64.NewEncoder(base64.URLEncoding, w) },
+		newDecoder: func(r io.Reader) io.Reader { return base64.NewDecoder(base64.URLEncoding, r) },
+	},
+	"hex": {
+		newEncoder: func(w io.Writer) io.WriteCloser { return NopWriteCloser(hex.NewEncoder(w)) },
+		newDecoder: hex.NewDecoder,
+	},
+}
+
+// lookupTextCodec returns the codec for the given encoding or [ErrUnsupportedEncoding].
+func lookupTextCodec(encoding string) (textCodec, error) {
+	codec, found := textCodecs[normalizeEncoding(encoding)]
+	if !found {
+		return textCodec{}, ErrUnsupportedEncoding
+	}
+	return codec, nil
+}
+
+// EncodeReadCloser returns an [io.ReadCloser] encoding the data read from rc as text
+// according to the given encoding, which is useful to ship binary payloads over text
+// channels without buffering them entirely.
+//
+// We support "base64" (i.e., standard base64 with padding), "base64url" (i.e., URL-safe
+// base64 with padding), and "hex". Each Read returns as soon as reading from rc
+// produces encoded data, rather than trying to fill the buffer, so reads are as
+// responsive as the ones of rc, and wrappers making reads context-interruptible,
+// such as [ReadContext], work as expected.
+//
+// Close closes rc.
+//
+// The returned error is nil or [ErrUnsupportedEncoding].
+func EncodeReadCloser(rc io.ReadCloser, encoding string) (io.ReadCloser, error) {
+	codec, err := lookupTextCodec(encoding)
+	if err != nil {
+		return nil, err
+	}
+	r := &encodeReadCloser{rc: rc}
+	r.enc = codec.newEncoder(&r.out)
+	return r, nil
+}
+
+// encodeReadCloser is the [io.ReadCloser] returned by [EncodeReadCloser].
+type encodeReadCloser struct {
+	enc io.WriteCloser
+	err error
+	out bytes.Buffer
+	raw []byte
+	rc  io.ReadCloser
+}
+
+// Read implements [io.Reader].
+func (r *encodeReadCloser) Read(buf []byte) (int, error) {
+	for r.out.Len() <= 0 {
+		// 1. return the sticky error once drained
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		// 2. read raw data and encode it, flushing the encoder at EOF
+		if r.raw == nil {
+			r.raw = make([]byte, 4096)
+		}
+		count, err := r.rc.Read(r.raw)
+		r.enc.Write(r.raw[:count])
+		if err == io.EOF {
+			r.enc.Close()
+		}
+		r.err = err
+	}
+	return r.out.Read(buf)
+}
+
+// Close implements [io.Closer].
+func (r *encodeReadCloser) Close() error {
+	return r.rc.Close()
+}
+
+// DecodeReadCloser returns an [io.ReadCloser] decoding the text read from rc according
+// to the given encoding, i.e., the inverse of [EncodeReadCloser].
+//
+// See [EncodeReadCloser] for the supported encodings. The base64 decoders ignore
+// newlines, so they also decode line-wrapped base64. Reads fail when rc contains
+// data that is not valid according to the encoding.
+//
+// Close closes rc.
+//
+// The returned error is nil or [ErrUnsupportedEncoding].
+func DecodeReadCloser(rc io.ReadCloser, encoding string) (io.ReadCloser, error) {
+	codec, err := lookupTextCodec(encoding)
+	if err != nil {
+		return nil, err
+	}
+	return readCloser{codec.newDecoder(rc), rc}, nil
+}
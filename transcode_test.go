@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	stdiotest "testing/iotest"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeReadCloser(t *testing.T) {
+	payload := make([]byte, 10000)
+	rand.Read(payload)
+
+	for _, tc := range []struct {
+		encoding string
+		expected string
+	}{
+		{"base64", base64.StdEncoding.EncodeToString(payload)},
+		{"BASE64URL", base64.URLEncoding.EncodeToString(payload)},
+		{" hex ", hex.EncodeToString(payload)},
+	} {
+		t.Run(tc.encoding, func(t *testing.T) {
+			// Read one byte at a time to exercise the encoder's buffering.
+			rc, err := EncodeReadCloser(NopReadCloser(stdiotest.OneByteReader(
+				strings.NewReader(string(payload)))), tc.encoding)
+			require.NoError(t, err)
+			data, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(data))
+
+			rc, err = DecodeReadCloser(NopReadCloser(strings.NewReader(tc.expected)), tc.encoding)
+			require.NoError(t, err)
+			data, err = io.ReadAll(rc)
+			require.NoError(t, err)
+			assert.Equal(t, payload, data)
+		})
+	}
+
+	t.Run("reads return as soon as there is encoded data", func(t *testing.T) {
+		chunks := []string{"abc", "def"}
+		rc, err := EncodeReadCloser(&iotest.FuncReadCloser{
+			ReadFunc: func(b []byte) (int, error) {
+				if len(chunks) <= 0 {
+					return 0, io.EOF
+				}
+				count := copy(b, chunks[0])
+				chunks = chunks[1:]
+				return count, nil
+			},
+			CloseFunc: func() error { return nil },
+		}, "base64")
+		require.NoError(t, err)
+		buf := make([]byte, 1024)
+		count, err := rc.Read(buf)
+		require.NoError(t, err)
+		assert.Equal(t, "YWJj", string(buf[:count]))
+		assert.Len(t, chunks, 1)
+	})
+
+	t.Run("errors are returned after the encoded data", func(t *testing.T) {
+		expected := errors.New("mocked error")
+		rc, err := EncodeReadCloser(NopReadCloser(io.MultiReader(
+			strings.NewReader("abc"), stdiotest.ErrReader(expected))), "hex")
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.ErrorIs(t, err, expected)
+		assert.Equal(t, "616263", string(data))
+	})
+
+	t.Run("close forwarding", func(t *testing.T) {
+		closed := &atomic.Int64{}
+		newReader := func() io.ReadCloser {
+			return &iotest.FuncReadCloser{
+				ReadFunc: strings.NewReader("").Read,
+				CloseFunc: func() error {
+					closed.Add(1)
+					return nil
+				},
+			}
+		}
+		enc, err := EncodeReadCloser(newReader(), "hex")
+		require.NoError(t, err)
+		require.NoError(t, enc.Close())
+		dec, err := DecodeReadCloser(newReader(), "hex")
+		require.NoError(t, err)
+		require.NoError(t, dec.Close())
+		assert.Equal(t, int64(2), closed.Load())
+	})
+
+	t.Run("unsupported encodings", func(t *testing.T) {
+		_, err := EncodeReadCloser(NopReadCloser(strings.NewReader("")), "rot13")
+		require.ErrorIs(t, err, ErrUnsupportedEncoding)
+		_, err = DecodeReadCloser(NopReadCloser(strings.NewReader("")), "rot13")
+		require.ErrorIs(t, err, ErrUnsupportedEncoding)
+	})
+
+	t.Run("invalid encoded data", func(t *testing.T) {
+		rc, err := DecodeReadCloser(NopReadCloser(strings.NewReader("zz")), "hex")
+		require.NoError(t, err)
+		_, err = io.ReadAll(rc)
+		require.Error(t, err)
+	})
+
+	t.Run("line-wrapped base64", func(t *testing.T) {
+		rc, err := DecodeReadCloser(NopReadCloser(strings.NewReader("aW94\r\nLXRl\neHQ=\n")), "base64")
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		assert.Equal(t, "iox-text", string(data))
+	})
+}
@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"sync"
+	"time"
+)
+
+// TranscriptEntry is an operation recorded by a [*Transcript].
+type TranscriptEntry struct {
+	// Op is the operation: "read", "write", or "close".
+	Op string
+
+	// Data contains the bytes read or written.
+	Data []byte
+
+	// Err is the error returned by the operation.
+	Err error
+
+	// Time is when the operation returned.
+	Time time.Time
+}
+
+// Transcript is an [io.ReadWriteCloser] recording the operations it forwards.
+//
+// It is meant to be used in tests to assert on the behavior of protocol code,
+// by wrapping, e.g., one end of a [net.Pipe], and then inspecting the recorded
+// entries using Entries, Reads, and Writes.
+//
+// All methods are safe for concurrent use.
+//
+// Construct using [NewTranscript].
+type Transcript struct {
+	entries []TranscriptEntry
+	mu      sync.Mutex
+	rwc     io.ReadWriteCloser
+}
+
+// NewTranscript wraps rwc and returns a [*Transcript].
+func NewTranscript(rwc io.ReadWriteCloser) *Transcript {
+	return &Transcript{rwc: rwc}
+}
+
+// Read implements [io.Reader].
+func (t *Transcript) Read(buf []byte) (int, error) {
+	count, err := t.rwc.Read(buf)
+	t.record("read", buf[:count], err)
+	return count, err
+}
+
+// Write implements [io.Writer].
+func (t *Transcript) Write(data []byte) (int, error) {
+	count, err := t.rwc.Write(data)
+	t.record("write", data[:count], err)
+	return count, err
+}
+
+// Close implements [io.Closer].
+func (t *Transcript) Close() error {
+	err := t.rwc.Close()
+	t.record("close", nil, err)
+	return err
+}
+
+// record appends a copy of the given operation to the entries.
+func (t *Transcript) record(op string, data []byte, err error) {
+	entry := TranscriptEntry{Op: op, Data: slices.Clone(data), Err: err, Time: time.Now()}
+	t.mu.Lock()
+	t.entries = append(t.entries, entry)
+	t.mu.Unlock()
+}
+
+// Entries returns the recorded operations in the order in which they returned.
+func (t *Transcript) Entries() []TranscriptEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.entries)
+}
+
+// Reads returns the recorded read operations.
+func (t *Transcript) Reads() []TranscriptEntry {
+	return t.filter("read")
+}
+
+// Writes returns the recorded write operations.
+func (t *Transcript) Writes() []TranscriptEntry {
+	return t.filter("write")
+}
+
+// ReadData returns the concatenation of the bytes read.
+func (t *Transcript) ReadData() []byte {
+	return concatData(t.Reads())
+}
+
+// WrittenData returns the concatenation of the bytes written.
+func (t *Transcript) WrittenData() []byte {
+	return concatData(t.Writes())
+}
+
+// filter returns the recorded operations with the given op.
+func (t *Transcript) filter(op string) (out []TranscriptEntry) {
+	for _, entry := range t.Entries() {
+		if entry.Op == op {
+			out = append(out, entry)
+		}
+	}
+	return
+}
+
+// concatData concatenates the data of the given entries.
+func concatData(entries []TranscriptEntry) []byte {
+	buf := &bytes.Buffer{}
+	for _, entry := range entries {
+		buf.Write(entry.Data)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscript(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	transcript := NewTranscript(client)
+
+	// run an echo server on the other end
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			count, err := server.Read(buf)
+			if err != nil {
+				return
+			}
+			server.Write(buf[:count])
+		}
+	}()
+
+	for _, msg := range []string{"ping", "hello"} {
+		_, err := transcript.Write([]byte(msg))
+		require.NoError(t, err)
+		buf := make([]byte, len(msg))
+		_, err = io.ReadFull(transcript, buf)
+		require.NoError(t, err)
+	}
+	require.NoError(t, transcript.Close())
+	_, err := transcript.Write([]byte("late"))
+	require.Error(t, err)
+
+	entries := transcript.Entries()
+	require.Len(t, entries, 6)
+	ops := []string{}
+	for idx, entry := range entries {
+		ops = append(ops, entry.Op)
+		if idx > 0 {
+			assert.False(t, entry.Time.Before(entries[idx-1].Time))
+		}
+	}
+	assert.Equal(t, []string{"write", "read", "write", "read", "close", "write"}, ops)
+
+	writes := transcript.Writes()
+	require.Len(t, writes, 3)
+	assert.Equal(t, "ping", string(writes[0].Data))
+	assert.ErrorIs(t, writes[2].Err, io.ErrClosedPipe)
+	assert.Len(t, transcript.Reads(), 2)
+	assert.Equal(t, "pinghello", string(transcript.WrittenData()))
+	assert.Equal(t, "pinghello", string(transcript.ReadData()))
+}
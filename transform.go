@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"errors"
+	"io"
+)
+
+// Transformer transforms a stream chunk by chunk.
+//
+// Since chunk boundaries are arbitrary, a Transformer may retain a suffix of
+// a chunk that could be the beginning of a match (e.g., a partial secret or a
+// trailing "\r") and prepend it to the next chunk.
+type Transformer interface {
+	// Transform returns the transformed data ready to be written. The returned
+	// slice may alias internal buffers and is only valid until the next call.
+	Transform(data []byte) []byte
+
+	// Finish returns the data retained at the end of the stream.
+	Finish() []byte
+}
+
+// TransformFunc adapts a stateless function to be a [Transformer] that
+// transforms each chunk independently and never retains data.
+type TransformFunc func(data []byte) []byte
+
+var _ Transformer = TransformFunc(nil)
+
+// Transform implements [Transformer].
+func (fn TransformFunc) Transform(data []byte) []byte {
+	return fn(data)
+}
+
+// Finish implements [Transformer].
+func (fn TransformFunc) Finish() []byte {
+	return nil
+}
+
+// WithTransform causes the copy to pass the bytes read through a [Transformer]
+// before writing them into the destination, which is useful to redact secrets or
+// normalize line endings while streaming.
+//
+// Since a [Transformer] is stateful, the copy calls newTransformer to obtain a
+// fresh one. When the copy reaches EOF, it writes the data retained by the
+// [Transformer] (see [Transformer.Finish]). The byte count of the copy is the
+// number of transformed bytes written. When passing several transforms, the last
+// one sees the data first. Transforming disables the zero-copy fast path
+// of [CopyContext].
+func WithTransform(newTransformer func() Transformer) CopyOption {
+	return func(cfg *copyConfig) {
+		cfg.wrapWriter = append(cfg.wrapWriter, func(dst io.Writer) io.Writer {
+			tw := &transformWriter{t: newTransformer(), w: dst}
+			cfg.finishers = append(cfg.finishers, tw.finish)
+			return tw
+		})
+	}
+}
+
+// transformWriter is the [io.Writer] used by [WithTransform] and [TransformWriteCloser].
+type transformWriter struct {
+	t Transformer
+	w io.Writer
+}
+
+// Write implements [io.Writer].
+//
+// On success, it returns len(data) regardless of the size of the transformed data.
+func (w *transformWriter) Write(data []byte) (int, error) {
+	if _, err := w.w.Write(w.t.Transform(data)); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// finish writes the data retained by the [Transformer].
+func (w *transformWriter) finish() error {
+	if data := w.t.Finish(); len(data) > 0 {
+		_, err := w.w.Write(data)
+		return err
+	}
+	return nil
+}
+
+// TransformWriteCloser is an [io.WriteCloser] passing the data written into
+// it through a [Transformer] before writing it into the underlying writer.
+//
+// Close writes the data retained by the [Transformer] and then closes the
+// underlying writer exactly once, which is the ordering [CopyContext] needs
+// when it closes the destination.
+//
+// Construct using [NewTransformWriteCloser].
+type TransformWriteCloser struct {
+	cs closeState
+	tw transformWriter
+	wc io.WriteCloser
+}
+
+// NewTransformWriteCloser returns a new [*TransformWriteCloser] transforming
+// the data using t and writing it into wc.
+func NewTransformWriteCloser(wc io.WriteCloser, t Transformer) *TransformWriteCloser {
+	return &TransformWriteCloser{tw: transformWriter{t: t, w: wc}, wc: wc}
+}
+
+// Write implements [io.Writer].
+//
+// On success, it returns len(data) regardless of the size of the transformed data.
+func (w *TransformWriteCloser) Write(data []byte) (int, error) {
+	if err := w.cs.check(); err != nil {
+		return 0, err
+	}
+	return w.tw.Write(data)
+}
+
+// Close implements [io.Closer].
+func (w *TransformWriteCloser) Close() error {
+	return w.cs.close(func() error {
+		return errors.Join(w.tw.finish(), w.wc.Close())
+	})
+}
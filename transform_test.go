@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	stdiotest "testing/iotest"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// redactor is a [Transformer] replacing a secret with asterisks across chunk boundaries.
+type redactor struct {
+	out     []byte
+	pending []byte
+	secret  []byte
+}
+
+// newRedactor returns a new [*redactor] for the given secret.
+func newRedactor(secret string) *redactor {
+	return &redactor{secret: []byte(secret)}
+}
+
+// Transform implements [Transformer].
+func (r *redactor) Transform(data []byte) []byte {
+	data = bytes.ReplaceAll(append(r.pending, data...), r.secret, bytes.Repeat([]byte("*"), len(r.secret)))
+	keep := 0
+	for n := min(len(r.secret)-1, len(data)); n > 0; n-- {
+		if bytes.HasSuffix(data, r.secret[:n]) {
+			keep = n
+			break
+		}
+	}
+	r.out = append(r.out[:0], data[:len(data)-keep]...)
+	r.pending = append([]byte(nil), data[len(data)-keep:]...)
+	return r.out
+}
+
+// Finish implements [Transformer].
+func (r *redactor) Finish() []byte {
+	return r.pending
+}
+
+func TestTransformWriteCloser(t *testing.T) {
+	// The secret is split across writes and the tail is retained until Close.
+	var (
+		buf    bytes.Buffer
+		closed bool
+	)
+	wc := &iotest.FuncWriteCloser{
+		WriteFunc: buf.Write,
+		CloseFunc: func() error {
+			closed = true
+			return nil
+		},
+	}
+	twc := NewTransformWriteCloser(wc, newRedactor("hunter2"))
+	for _, chunk := range []string{"password=hun", "ter2 user=hun"} {
+		count, err := twc.Write([]byte(chunk))
+		require.NoError(t, err)
+		assert.Equal(t, len(chunk), count)
+	}
+	assert.Equal(t, "password=******* user=", buf.String())
+
+	require.NoError(t, twc.Close())
+	assert.Equal(t, "password=******* user=hun", buf.String())
+	assert.True(t, closed)
+
+	require.ErrorIs(t, twc.Close(), ErrClosed)
+	_, err := twc.Write([]byte("x"))
+	require.ErrorIs(t, err, ErrClosed)
+}
+
+func TestTransformWriteCloserErrors(t *testing.T) {
+	// Write errors are returned, and Close joins finishing and closing errors.
+	expected := errors.New("mocked write error")
+	wc := &iotest.FuncWriteCloser{
+		WriteFunc: func(b []byte) (int, error) { return 0, expected },
+		CloseFunc: func() error { return nil },
+	}
+	twc := NewTransformWriteCloser(wc, newRedactor("hunter2"))
+	count, err := twc.Write([]byte("user=alice"))
+	require.ErrorIs(t, err, expected)
+	assert.Equal(t, 0, count)
+
+	_, err = twc.Write([]byte("pass=hunt"))
+	require.ErrorIs(t, err, expected)
+	require.ErrorIs(t, twc.Close(), expected)
+}
+
+func TestTransformFunc(t *testing.T) {
+	// A stateless transform never retains data.
+	crlf := TransformFunc(func(data []byte) []byte {
+		return bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	})
+	assert.Equal(t, "a\nb\n", string(crlf.Transform([]byte("a\r\nb\r\n"))))
+	assert.Nil(t, crlf.Finish())
+}
+
+func TestCopyContextWithTransform(t *testing.T) {
+	// Reading one byte at a time, we still redact and write the retained tail.
+	const payload = "token=hunter2 again hunter2 and hunte"
+	var buf bytes.Buffer
+	rc := io.NopCloser(stdiotest.OneByteReader(strings.NewReader(payload)))
+	count, err := CopyContext(context.Background(), NewLockedWriteCloser(NopWriteCloser(&buf)), rc,
+		WithTransform(func() Transformer { return newRedactor("hunter2") }))
+	require.NoError(t, err)
+	assert.Equal(t, "token=******* again ******* and hunte", buf.String())
+	assert.Equal(t, len(payload), count)
+}
+
+func TestCopyContextWithSeveralTransforms(t *testing.T) {
+	// The last transform sees the data first.
+	upper := func() Transformer { return TransformFunc(bytes.ToUpper) }
+	var buf bytes.Buffer
+	_, err := CopyContext(context.Background(), NewLockedWriteCloser(NopWriteCloser(&buf)),
+		io.NopCloser(strings.NewReader("secret=hunter2")),
+		WithTransform(func() Transformer { return newRedactor("HUNTER2") }), WithTransform(upper))
+	require.NoError(t, err)
+	assert.Equal(t, "SECRET=*******", buf.String())
+}
+
+func TestCopyContextWithTransformFinishError(t *testing.T) {
+	// Failing to write the retained data fails the copy.
+	expected := errors.New("mocked write error")
+	writes := 0
+	wc := &iotest.FuncWriteCloser{
+		WriteFunc: func(b []byte) (int, error) {
+			if writes++; writes > 1 {
+				return 0, expected
+			}
+			return len(b), nil
+		},
+		CloseFunc: func() error { return nil },
+	}
+	_, err := CopyContext(context.Background(), NewLockedWriteCloser(wc),
+		io.NopCloser(strings.NewReader("user=hunt")),
+		WithTransform(func() Transformer { return newRedactor("hunter2") }))
+	require.ErrorIs(t, err, expected)
+	var ioxErr *Error
+	require.ErrorAs(t, err, &ioxErr)
+	assert.Equal(t, "write", ioxErr.Op)
+}
@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import "io"
+
+// As returns v, or the first value wrapped by v, implementing T.
+//
+// Wrappers hide the optional interfaces of the values they wrap (e.g., [io.WriterTo],
+// [io.Seeker], [io.ReaderFrom], or [net/http.Flusher]). As rediscovers them by looking
+// through the chain of wrappers with an Unwrap method returning an [io.Reader] or an
+// [io.Writer], such as the ones returned by [NopReadCloser], [NopWriteCloser],
+// [JoinReadCloser], [HalfCloseWriter], [NewOwnedReadCloser], [NewBorrowedReadCloser],
+// and [NewCancelableReadCloser], and [*LockedWriteCloser]. The package uses As for
+// its own fast paths, e.g., to flush or to copy using splice or sendfile.
+//
+// Wrappers transforming, limiting, or observing the data MUST NOT implement Unwrap,
+// since using the wrapped value directly would bypass them. Conversely, wrappers
+// implementing Unwrap MUST be transparent, such that bypassing them is harmless.
+func As[T any](v any) (T, bool) {
+	for v != nil {
+		if tv, ok := v.(T); ok {
+			return tv, true
+		}
+		switch uv := v.(type) {
+		case interface{ Unwrap() io.Reader }:
+			v = uv.Unwrap()
+		case interface{ Unwrap() io.Writer }:
+			v = uv.Unwrap()
+		default:
+			v = nil
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// unwrapReader returns the innermost reader of the chain of wrappers
+// implementing Unwrap starting at r (see [As]).
+func unwrapReader(r io.Reader) io.Reader {
+	for {
+		ur, ok := r.(interface{ Unwrap() io.Reader })
+		if !ok {
+			return r
+		}
+		r = ur.Unwrap()
+	}
+}
@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAs(t *testing.T) {
+	t.Run("finds writer capabilities through wrappers", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		lwc := NewLockedWriteCloser(HalfCloseWriter(NopWriteCloser(rec)))
+		found, ok := As[interface{ Flush() }](lwc)
+		require.True(t, ok)
+		assert.Same(t, rec, found)
+	})
+
+	t.Run("finds reader capabilities through wrappers", func(t *testing.T) {
+		reader := strings.NewReader("hello")
+		rc := NewBorrowedReadCloser(JoinReadCloser(onlyReader{reader}, io.NopCloser(nil)))
+		_, ok := As[io.WriterTo](rc)
+		assert.False(t, ok)
+
+		wt, ok := As[io.WriterTo](NewOwnedReadCloser(JoinReadCloser(reader, io.NopCloser(nil))))
+		require.True(t, ok)
+		assert.Same(t, reader, wt)
+	})
+
+	t.Run("finds Flusher implementations", func(t *testing.T) {
+		bw := bufio.NewWriter(&bytes.Buffer{})
+		found, ok := As[Flusher](NopWriteCloser(bw))
+		require.True(t, ok)
+		assert.Same(t, bw, found)
+	})
+
+	t.Run("stops at opaque wrappers", func(t *testing.T) {
+		_, ok := As[*strings.Reader](io.NopCloser(strings.NewReader("")))
+		assert.False(t, ok)
+		_, ok = As[io.Reader](nil)
+		assert.False(t, ok)
+	})
+}
+
+func TestUnwrapReader(t *testing.T) {
+	reader := strings.NewReader("hello")
+	rc := NewCancelableReadCloser(NopReadCloser(reader))
+	assert.Same(t, reader, unwrapReader(rc))
+}
+
+// onlyReader hides the optional interfaces of an [io.Reader].
+type onlyReader struct {
+	io.Reader
+}
@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// writeDeadliner is implemented by writers supporting write deadlines (e.g., [net.Conn]).
+type writeDeadliner interface {
+	SetWriteDeadline(t time.Time) error
+}
+
+// WriteContext performs a single context-interruptible Write.
+//
+// This is the counterpart of [ReadContext] and handles a writer blocked forever
+// on a stuck peer. When wc supports write deadlines (e.g., [net.Conn]), WriteContext
+// writes data directly and, on context cancellation, sets a write deadline in the
+// past to unblock the in-flight Write, then clears the deadline, so wc remains
+// usable. Otherwise, it writes in a background goroutine using a copy of data, so
+// data may be reused after WriteContext returns, and closes wc on context cancellation
+// to unblock the in-flight Write. In both cases, wc is NOT closed on success and
+// the caller MUST close it (e.g., via defer).
+//
+// The returned count follows the [io.Writer] semantics, while the returned error
+// is either caused by I/O or by the context. When the context is canceled while
+// writing in the background, the returned count is zero, since we cannot know
+// how many bytes have been written.
+func WriteContext(ctx context.Context, wc io.WriteCloser, data []byte) (int, error) {
+	// 1. bail early if the context is already done
+	if err := contextError(ctx); err != nil {
+		return 0, err
+	}
+
+	// 2. prefer deadlines, which keep wc usable
+	if wd, ok := wc.(writeDeadliner); ok {
+		return writeContextDeadline(ctx, wc, wd, data)
+	}
+
+	// 3. prepare for receiving the background write result
+	type result struct {
+		count int
+		err   error
+	}
+	resch := make(chan result, 1)
+	bp := getBuffer()
+	if len(*bp) < len(data) {
+		buf := make([]byte, len(data))
+		bp = &buf
+	}
+	buf := (*bp)[:copy(*bp, data)]
+
+	// 4. do in background so we can be interrupted
+	go func() {
+		count, err := wc.Write(buf)
+		resch <- result{count, err}
+	}()
+
+	// 5. wait and collect the result
+	select {
+	case <-ctx.Done():
+		wc.Close()
+		return 0, contextError(ctx)
+	case res := <-resch:
+		putBuffer(bp)
+		return res.count, res.err
+	}
+}
+
+// writeContextDeadline implements [WriteContext] for writers supporting deadlines.
+func writeContextDeadline(ctx context.Context, w io.Writer, wd writeDeadliner, data []byte) (int, error) {
+	// 1. arrange for expiring the deadline on context cancellation
+	expired := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		defer close(expired)
+		wd.SetWriteDeadline(aLongTimeAgo)
+	})
+
+	// 2. write and, if we have been interrupted, clear the deadline
+	count, err := w.Write(data)
+	if !stop() {
+		<-expired
+		wd.SetWriteDeadline(time.Time{})
+		if err != nil {
+			err = contextError(ctx)
+		}
+	}
+	return count, err
+}
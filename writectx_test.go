@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bassosimone/iotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteContext(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		var sb strings.Builder
+		wc := NopWriteCloser(&sb)
+		count, err := WriteContext(context.Background(), wc, []byte("iox"))
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.Equal(t, "iox", sb.String())
+	})
+
+	t.Run("write errors are returned", func(t *testing.T) {
+		expected := errors.New("mocked error")
+		wc := &iotest.FuncWriteCloser{
+			WriteFunc: func(b []byte) (int, error) { return 1, expected },
+			CloseFunc: func() error { return nil },
+		}
+		count, err := WriteContext(context.Background(), wc, []byte("iox"))
+		require.ErrorIs(t, err, expected)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("cancellation closes writers without deadlines", func(t *testing.T) {
+		unblock := make(chan struct{})
+		wc := &iotest.FuncWriteCloser{
+			WriteFunc: func(b []byte) (int, error) {
+				<-unblock
+				return 0, io.ErrClosedPipe
+			},
+			CloseFunc: func() error {
+				close(unblock)
+				return nil
+			},
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		count, err := WriteContext(ctx, wc, []byte("iox"))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, 0, count)
+		<-unblock
+	})
+
+	t.Run("cancellation expires the deadline and keeps the conn usable", func(t *testing.T) {
+		conn1, conn2 := net.Pipe()
+		defer conn1.Close()
+		defer conn2.Close()
+
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cause := errors.New("mocked cause")
+		time.AfterFunc(10*time.Millisecond, func() { cancel(cause) })
+		_, err := WriteContext(ctx, conn1, []byte("iox"))
+		require.ErrorIs(t, err, context.Canceled)
+		require.ErrorIs(t, err, cause)
+
+		done := make(chan []byte)
+		go func() {
+			buf := make([]byte, 8)
+			count, _ := conn2.Read(buf)
+			done <- buf[:count]
+		}()
+		count, err := WriteContext(context.Background(), conn1, []byte("iox"))
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.Equal(t, "iox", string(<-done))
+	})
+
+	t.Run("already canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := WriteContext(ctx, NopWriteCloser(io.Discard), []byte("iox"))
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
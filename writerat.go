@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"io"
+	"sync"
+)
+
+// WriterAtCloser is an [io.WriterAt] that can be closed.
+type WriterAtCloser interface {
+	io.WriterAt
+	io.Closer
+}
+
+// LockedWriterAt is a concurrency safe [WriterAtCloser] wrapper.
+//
+// It serializes positional writes, makes Close idempotent, and keeps track of the
+// number of bytes successfully written. It is the positional counterpart of
+// [*LockedWriteCloser] and a safe sink for [CopyReaderAtContext].
+//
+// All methods are safe for concurrent use.
+//
+// Close is serialized with WriteAt, so it may block until an in-flight WriteAt returns.
+//
+// Construct using [NewLockedWriterAt].
+type LockedWriterAt struct {
+	cs  closeState
+	mu  sync.RWMutex
+	num int64
+	w   WriterAtCloser
+}
+
+// NewLockedWriterAt wraps a [WriterAtCloser] and returns a concurrency-safe wrapper.
+func NewLockedWriterAt(w WriterAtCloser) *LockedWriterAt {
+	return &LockedWriterAt{cs: closeState{idempotent: true}, w: w}
+}
+
+// WriteAt implements [io.WriterAt] by writing the given bytes at the given offset
+// of the underlying [WriterAtCloser] while holding the lock.
+//
+// The returned error is nil, [ErrClosed] when closed, or the error occurred
+// when attempting to write into the underlying [WriterAtCloser].
+func (w *LockedWriterAt) WriteAt(data []byte, offset int64) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.cs.check(); err != nil {
+		return 0, err
+	}
+	count, err := w.w.WriteAt(data, offset)
+	w.num += int64(count)
+	return count, err
+}
+
+// Count returns the number of bytes successfully written so far.
+func (w *LockedWriterAt) Count() int64 {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.num
+}
+
+// Close ensures that subsequent writes would fail with [ErrClosed].
+//
+// Returns nil, [ErrClosed], or the error occurred when closing the [WriterAtCloser].
+func (w *LockedWriterAt) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.cs.close(w.w.Close)
+}
@@ -0,0 +1,40 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockedWriterAt(t *testing.T) {
+	// Copy into a file using the parallel chunked copy.
+	payload := strings.Repeat("locked-writer-at ", 64)
+	filename := filepath.Join(t.TempDir(), "output.txt")
+	file, err := os.Create(filename)
+	require.NoError(t, err)
+	lwa := NewLockedWriterAt(file)
+
+	count, err := CopyReaderAtContext(context.Background(), lwa, strings.NewReader(payload),
+		int64(len(payload)), WithChunkSize(100), WithConcurrency(8))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(payload)), count)
+	assert.Equal(t, int64(len(payload)), lwa.Count())
+
+	// Make sure subsequent writes and closes fail with the expected error.
+	require.NoError(t, lwa.Close())
+	wcount, err := lwa.WriteAt([]byte("0xabad1dea"), 0)
+	require.ErrorIs(t, err, ErrClosed)
+	assert.Equal(t, 0, wcount)
+	require.ErrorIs(t, lwa.Close(), ErrClosed)
+
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, payload, string(data))
+}
@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import "io"
+
+// zeroCopySource returns the reader to pass to lockedZeroCopy when the copy performed
+// by [CopyContext] may use the zero-copy fast path, or nil otherwise.
+//
+// The fast path requires copying between the raw reader and writer, so any option
+// wrapping them, or observing each chunk, disables it, and so do [NeverClose] and
+// [AbandonOnCancel], since nothing would interrupt a copy holding the lock of the
+// [*LockedWriteCloser], which prevents closing it on cancellation.
+// It looks for the raw reader through transparent wrappers (see [As]), such as the
+// ones returned by [NewOwnedReadCloser] and [NewBorrowedReadCloser].
+func (cfg *copyConfig) zeroCopySource(rc io.ReadCloser, tracer *copyTracer) io.Reader {
+	if cfg.flushEachWrite || len(cfg.wrapReader) > 0 || len(cfg.wrapWriter) > 0 || tracer != nil {
+		return nil
+	}
+	switch cfg.ownershipOf(rc) {
+	case NeverClose, AbandonOnCancel:
+		return nil // the copy holds the lock until EOF, since nothing unblocks it
+	}
+	return unwrapReader(rc)
+}
+
+// lockedZeroCopy copies from src into the underlying [io.WriteCloser] using
+// splice or sendfile, when the platform and the types of src and of the
+// underlying writer allow, while holding the lock for the whole copy.
+//
+// The returned bool is false when the zero-copy fast path is not available,
+// in which case the caller should fallback to a regular copy. This is also
+// the case when using StickyErrors, since the errors returned by the fast
+// path mix read and write errors, and we only want to latch the latter.
+func (w *LockedWriteCloser) lockedZeroCopy(src io.Reader) (int64, bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.StickyErrors {
+		return 0, false, nil
+	}
+	rf, ok := zeroCopyDestination(w.w, src)
+	if !ok {
+		return 0, false, nil
+	}
+	if err := w.check(); err != nil {
+		return 0, true, err
+	}
+	count, err := rf.ReadFrom(src)
+	w.account(int(count))
+	return count, true, err
+}
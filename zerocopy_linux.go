@@ -0,0 +1,32 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build linux
+
+package iox
+
+import (
+	"io"
+	"net"
+	"os"
+)
+
+// zeroCopyDestination returns the [io.ReaderFrom] performing the zero-copy
+// copy from src into dst, and whether such a copy is possible.
+//
+// On Linux, [*net.TCPConn] uses splice when reading from sockets and sendfile when
+// reading from files, while [*os.File] uses copy_file_range or splice. It looks for
+// dst through transparent wrappers (see [As]), such as [HalfCloseWriter].
+func zeroCopyDestination(dst io.Writer, src io.Reader) (io.ReaderFrom, bool) {
+	switch src.(type) {
+	case *net.TCPConn, *net.UnixConn, *os.File:
+	default:
+		return nil, false
+	}
+	if conn, ok := As[*net.TCPConn](dst); ok {
+		return conn, true
+	}
+	if file, ok := As[*os.File](dst); ok {
+		return file, true
+	}
+	return nil, false
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package iox
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZeroCopyDestination(t *testing.T) {
+	conn, _ := newTCPConnPair(t)
+	file, err := os.CreateTemp(t.TempDir(), "")
+	require.NoError(t, err)
+	defer file.Close()
+
+	for _, tc := range []struct {
+		name     string
+		dst      io.Writer
+		src      io.Reader
+		expected bool
+	}{
+		{"socket to socket", conn, conn, true},
+		{"file to socket", HalfCloseWriter(conn), file, true},
+		{"socket to file", NopWriteCloser(file), conn, true},
+		{"buffer to socket", conn, strings.NewReader(""), false},
+		{"socket to buffer", &bytes.Buffer{}, conn, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, ok := zeroCopyDestination(tc.dst, tc.src)
+			assert.Equal(t, tc.expected, ok)
+		})
+	}
+}
+
+func TestCopyContextZeroCopy(t *testing.T) {
+	t.Run("file to socket", func(t *testing.T) {
+		payload := make([]byte, 1<<20)
+		rand.Read(payload)
+		path := filepath.Join(t.TempDir(), "payload")
+		require.NoError(t, os.WriteFile(path, payload, 0600))
+		file, err := os.Open(path)
+		require.NoError(t, err)
+		defer file.Close()
+
+		client, server := newTCPConnPair(t)
+		received := make(chan []byte)
+		go func() {
+			data, _ := io.ReadAll(server)
+			received <- data
+		}()
+
+		lwc := NewLockedWriteCloser(HalfCloseWriter(client))
+		count, err := CopyContext(context.Background(), lwc, file)
+		require.NoError(t, err)
+		assert.Equal(t, len(payload), count)
+		assert.Equal(t, payload, <-received)
+		assert.Equal(t, int64(1), lwc.Stats().Calls)
+	})
+
+	t.Run("socket to socket with cancellation", func(t *testing.T) {
+		src, _ := newTCPConnPair(t)
+		dst, _ := newTCPConnPair(t)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		lwc := NewLockedWriteCloser(dst)
+		_, err := CopyContext(ctx, lwc, src)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("sticky errors disable the fast path", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "payload")
+		require.NoError(t, os.WriteFile(path, []byte("iox"), 0600))
+		file, err := os.Open(path)
+		require.NoError(t, err)
+		defer file.Close()
+		client, _ := newTCPConnPair(t)
+		require.NoError(t, client.Close())
+
+		lwc := NewLockedWriteCloser(HalfCloseWriter(client))
+		lwc.StickyErrors = true
+		_, err = CopyContext(context.Background(), lwc, file)
+		require.Error(t, err)
+		assert.ErrorIs(t, lwc.Err(), net.ErrClosed)
+	})
+
+	t.Run("tracing disables the fast path", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "payload")
+		require.NoError(t, os.WriteFile(path, []byte("iox"), 0600))
+		file, err := os.Open(path)
+		require.NoError(t, err)
+		defer file.Close()
+		client, server := newTCPConnPair(t)
+		go io.Copy(io.Discard, server)
+
+		var chunks int
+		lwc := NewLockedWriteCloser(HalfCloseWriter(client))
+		count, err := CopyContext(context.Background(), lwc, file,
+			WithCopyTrace(&CopyTrace{WroteChunk: func(int) { chunks++ }}))
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.Equal(t, 1, chunks)
+	})
+}
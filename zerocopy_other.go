@@ -0,0 +1,15 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+//go:build !linux
+
+package iox
+
+import "io"
+
+// zeroCopyDestination returns the [io.ReaderFrom] performing the zero-copy
+// copy from src into dst, and whether such a copy is possible.
+//
+// This platform does not support the zero-copy fast path.
+func zeroCopyDestination(dst io.Writer, src io.Reader) (io.ReaderFrom, bool) {
+	return nil, false
+}